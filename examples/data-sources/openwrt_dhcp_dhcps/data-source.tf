@@ -0,0 +1,5 @@
+data "openwrt_dhcp_dhcps" "testing" {
+  filter = {
+    interface = "lan"
+  }
+}
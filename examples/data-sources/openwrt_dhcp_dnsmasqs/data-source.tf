@@ -0,0 +1,2 @@
+data "openwrt_dhcp_dnsmasqs" "testing" {
+}
@@ -0,0 +1,5 @@
+data "openwrt_dhcp_domains" "testing" {
+  filter = {
+    ip = "192.168.1.1"
+  }
+}
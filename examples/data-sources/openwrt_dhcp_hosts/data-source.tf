@@ -0,0 +1,5 @@
+data "openwrt_dhcp_hosts" "testing" {
+  filter = {
+    name = "testing"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_firewall_forwardings" "testing" {
+  filter = {
+    src = "lan"
+  }
+}
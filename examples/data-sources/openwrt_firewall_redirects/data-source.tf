@@ -0,0 +1,5 @@
+data "openwrt_firewall_redirects" "testing" {
+  filter = {
+    dest = "lan"
+  }
+}
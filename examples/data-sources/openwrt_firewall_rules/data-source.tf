@@ -0,0 +1,5 @@
+data "openwrt_firewall_rules" "testing" {
+  filter = {
+    src = "wan"
+  }
+}
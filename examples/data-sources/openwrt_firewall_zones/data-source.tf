@@ -0,0 +1,5 @@
+data "openwrt_firewall_zones" "testing" {
+  filter = {
+    name = "wan"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_network_bridge_vlans" "testing" {
+  filter = {
+    device = "br-lan"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_network_devices" "testing" {
+  filter = {
+    type = "bridge"
+  }
+}
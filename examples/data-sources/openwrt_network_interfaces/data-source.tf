@@ -0,0 +1,5 @@
+data "openwrt_network_interfaces" "testing" {
+  filter = {
+    proto = "static"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_network_switch_vlans" "testing" {
+  filter = {
+    device = "switch0"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_network_switches" "testing" {
+  filter = {
+    name = "switch0"
+  }
+}
@@ -0,0 +1,3 @@
+data "openwrt_uci_config" "testing" {
+  config = "uhttpd"
+}
@@ -0,0 +1,4 @@
+data "openwrt_uci_section" "testing" {
+  config = "uhttpd"
+  name   = "main"
+}
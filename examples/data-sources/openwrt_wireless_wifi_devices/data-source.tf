@@ -0,0 +1,5 @@
+data "openwrt_wireless_wifi_devices" "testing" {
+  filter = {
+    band = "5g"
+  }
+}
@@ -0,0 +1,5 @@
+data "openwrt_wireless_wifi_ifaces" "testing" {
+  filter = {
+    network = "lan"
+  }
+}
@@ -0,0 +1,14 @@
+resource "openwrt_dhcp_host" "testing" {
+  id   = "testing"
+  ip   = "192.168.1.50"
+  mac  = "12:34:56:78:90:ab"
+  name = "testing"
+}
+
+resource "openwrt_service_reload" "dnsmasq" {
+  service = "dnsmasq"
+
+  triggers = {
+    "host" = jsonencode(openwrt_dhcp_host.testing)
+  }
+}
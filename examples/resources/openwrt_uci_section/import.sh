@@ -0,0 +1,18 @@
+# The Terraform id is the config and the name of the section, separated by a period.
+# One way to find the name of a section is with `uci show` on the device:
+#
+# uci show dropbear
+#
+# This command will output something like:
+#
+# dropbear.cfg014dd4=dropbear
+# dropbear.cfg014dd4.PasswordAuth='on'
+# dropbear.cfg014dd4.Port='22'
+#
+# We'd then use the information to import the appropriate resource:
+
+terraform import openwrt_uci_section.this dropbear.cfg014dd4
+
+# Sections on one of the provider's `devices` are imported with the name of the device in front, separated by a slash:
+
+terraform import openwrt_uci_section.this office/dropbear.cfg014dd4
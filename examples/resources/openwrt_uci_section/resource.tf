@@ -0,0 +1,14 @@
+resource "openwrt_uci_section" "testing" {
+  config = "uhttpd"
+  name   = "testing"
+  type   = "uhttpd"
+
+  list_options = {
+    "listen_http" = ["0.0.0.0:8080", "[::]:8080"]
+  }
+
+  options = {
+    "home"           = "/www"
+    "rfc1918_filter" = "1"
+  }
+}
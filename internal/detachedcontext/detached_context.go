@@ -0,0 +1,38 @@
+// Package detachedcontext provides a context that outlives its parent's cancellation.
+// Go 1.21 has [context.WithoutCancel] for this,
+// but we still build with Go 1.20.
+package detachedcontext
+
+import (
+	"context"
+	"time"
+)
+
+// New constructs a new [Context] with the values of `ctx`.
+func New(
+	ctx context.Context,
+) Context {
+	return Context{
+		Context: ctx,
+	}
+}
+
+// Context keeps the values of a context (e.g. for logging),
+// but is never cancelled.
+// Use it for work that has to finish even if the caller gives up,
+// like reverting changes or connecting to a device that later callers share.
+type Context struct {
+	context.Context
+}
+
+func (Context) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (Context) Done() <-chan struct{} {
+	return nil
+}
+
+func (Context) Err() error {
+	return nil
+}
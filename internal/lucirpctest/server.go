@@ -0,0 +1,971 @@
+// Package lucirpctest provides an in-process fake of the LuCI JSON-RPC API.
+// It lets the provider be exercised without running OpenWrt in Docker.
+package lucirpctest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt"
+	"gotest.tools/v3/assert"
+)
+
+const (
+	ciEnvironmentVariable = "CI"
+
+	methodAdd         = "add"
+	methodChanges     = "changes"
+	methodCommit      = "commit"
+	methodDelete      = "delete"
+	methodExec        = "exec"
+	methodGetAll      = "get_all"
+	methodInitReload  = "init.reload"
+	methodInitRestart = "init.restart"
+	methodLogin       = "login"
+	methodReorder     = "reorder"
+	methodRevert      = "revert"
+	methodSection     = "section"
+	methodTSet        = "tset"
+
+	pathAuth = "/cgi-bin/luci/rpc/auth"
+	pathSys  = "/cgi-bin/luci/rpc/sys"
+	pathUCI  = "/cgi-bin/luci/rpc/uci"
+
+	queryKeyAuth = "auth"
+
+	terraformPathEnvironmentVariable = "TF_ACC_TERRAFORM_PATH"
+)
+
+var (
+	extendedSectionPattern = regexp.MustCompile(`^@([^\[]+)\[(-?[0-9]+)\]$`)
+)
+
+// Server is a fake of the JSON-RPC API provided by `luci-mod-rpc`.
+// It implements the `auth` and `uci` methods the provider uses over an in-memory UCI tree,
+// and the `sys` methods that run init scripts.
+//
+// Like UCI, changes are staged until they are committed.
+// Reads see staged changes,
+// but only committed changes show up in [Server.CommittedSection].
+//
+// Any config can be used without creating it first,
+// unless the configs on the device are set with [Server.SetConfigs].
+type Server struct {
+	Hostname string
+	Password string
+	Port     uint16
+	Scheme   string
+	Username string
+
+	mutex          sync.Mutex
+	changes        map[string][][]string
+	committed      map[string][]section
+	configs        []string
+	serviceActions []string
+	sessions       map[string]bool
+	staged         map[string][]section
+}
+
+// NewServer starts a [Server] that is closed when the test finishes.
+func NewServer(
+	t *testing.T,
+) *Server {
+	t.Helper()
+
+	s := &Server{
+		Password:  "",
+		Username:  "root",
+		changes:   map[string][][]string{},
+		committed: map[string][]section{},
+		sessions:  map[string]bool{},
+		staged:    map[string][]section{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(server.Close)
+	address, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+	port, err := strconv.Atoi(address.Port())
+	assert.NilError(t, err)
+	s.Hostname = address.Hostname()
+	s.Port = uint16(port)
+	s.Scheme = address.Scheme
+	return s
+}
+
+// CommittedSection returns the committed options of a section,
+// including the metadata LuCI adds.
+func (s *Server) CommittedSection(
+	config string,
+	sectionName string,
+) (lucirpc.Options, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	index := findSection(s.committed[config], sectionName)
+	if index < 0 {
+		return nil, false
+	}
+
+	raw, err := json.Marshal(s.committed[config][index].toJSON())
+	if err != nil {
+		return nil, false
+	}
+
+	var options lucirpc.Options
+	err = json.Unmarshal(raw, &options)
+	if err != nil {
+		return nil, false
+	}
+
+	return options, true
+}
+
+// DeviceAttribute creates a stringified entry for the `devices` attribute of the OpenWrt provider,
+// so resources can use the [Server] as the device with the given `name`.
+func (s *Server) DeviceAttribute(
+	name string,
+) string {
+	return fmt.Sprintf(`
+	%q = {
+		hostname = %q
+		password = %q
+		port = %d
+		scheme = %q
+		username = %q
+	}
+`,
+		name,
+		s.Hostname,
+		s.Password,
+		s.Port,
+		s.Scheme,
+		s.Username,
+	)
+}
+
+// LuCIRPCClient returns a [*lucirpc.Client] to interact with the [Server].
+func (s *Server) LuCIRPCClient(
+	ctx context.Context,
+	t *testing.T,
+	options ...lucirpc.ClientOption,
+) *lucirpc.Client {
+	t.Helper()
+
+	client, err := lucirpc.NewClient(
+		ctx,
+		s.Scheme,
+		s.Hostname,
+		s.Port,
+		s.Username,
+		s.Password,
+		options...,
+	)
+	assert.NilError(t, err)
+	return client
+}
+
+// ProviderBlock creates a stringified provider block for the OpenWrt provider.
+// Any `attributes` (e.g. `section_id_strategy = "anonymous"`) are added to the block.
+func (s *Server) ProviderBlock(
+	attributes ...string,
+) string {
+	return fmt.Sprintf(`
+provider "openwrt" {
+	hostname = %q
+	password = %q
+	port = %d
+	scheme = %q
+	username = %q
+%s
+}
+`,
+		s.Hostname,
+		s.Password,
+		s.Port,
+		s.Scheme,
+		s.Username,
+		strings.Join(attributes, "\n"),
+	)
+}
+
+// RemoveSection removes a committed section from the [Server],
+// like someone deleting it in LuCI and saving.
+// Use this to change the device outside of Terraform during a test.
+func (s *Server) RemoveSection(
+	config string,
+	sectionName string,
+) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sections := s.committed[config]
+	index := findSection(sections, sectionName)
+	if index < 0 {
+		return false
+	}
+
+	s.committed[config] = append(sections[:index:index], sections[index+1:]...)
+	delete(s.staged, config)
+	delete(s.changes, config)
+	return true
+}
+
+// ServiceActions returns the init script actions that were run, in order.
+// Each is the service followed by the action (e.g. `network reload`).
+func (s *Server) ServiceActions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := []string{}
+	result = append(result, s.serviceActions...)
+	return result
+}
+
+// SetConfigs sets the configs the device reports having when it is probed,
+// like a device that only has some packages installed.
+func (s *Server) SetConfigs(
+	configs ...string,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.configs = append([]string{}, configs...)
+}
+
+// SetSection adds a committed section to the [Server].
+// Use this to set up the state of the device before a test.
+func (s *Server) SetSection(
+	config string,
+	sectionType string,
+	sectionName string,
+	options map[string]any,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := map[string]any{}
+	for option, value := range options {
+		values[option] = normalizeValue(value)
+	}
+
+	sections := s.committed[config]
+	index := findSection(sections, sectionName)
+	if index < 0 {
+		sections = append(sections, section{
+			name:        sectionName,
+			options:     map[string]any{},
+			sectionType: sectionType,
+		})
+		index = len(sections) - 1
+	}
+
+	sections[index].sectionType = sectionType
+	for option, value := range values {
+		sections[index].options[option] = value
+	}
+
+	s.committed[config] = sections
+	delete(s.staged, config)
+	delete(s.changes, config)
+}
+
+// StageOptions stages changes to the options of an existing section without committing them,
+// like unsaved edits in LuCI.
+func (s *Server) StageOptions(
+	config string,
+	sectionName string,
+	options map[string]any,
+) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.updateSection(config, sectionName, options)
+}
+
+// addSection adds an anonymous section,
+// named the way UCI names them (e.g. `cfg0392bd`).
+func (s *Server) addSection(
+	config string,
+	sectionType string,
+) string {
+	sections := s.stage(config)
+	added := section{
+		anonymous:   true,
+		options:     map[string]any{},
+		sectionType: sectionType,
+	}
+	added.name = added.anonymousName(len(sections))
+	s.staged[config] = append(sections, added)
+	s.changes[config] = append(s.changes[config], []string{"add", added.name, added.sectionType})
+	return added.name
+}
+
+// commit commits the staged changes of a config.
+// Like UCI loading the config again,
+// every anonymous section is named again from its position.
+func (s *Server) commit(
+	config string,
+) bool {
+	staged, ok := s.staged[config]
+	if ok {
+		for index := range staged {
+			if staged[index].anonymous {
+				staged[index].name = staged[index].anonymousName(index)
+			}
+		}
+
+		s.committed[config] = staged
+	}
+
+	delete(s.staged, config)
+	delete(s.changes, config)
+	return true
+}
+
+func (s *Server) createSection(
+	config string,
+	sectionType string,
+	sectionName string,
+	values map[string]any,
+) bool {
+	sections := s.stage(config)
+	index := findSection(sections, sectionName)
+	if index < 0 {
+		sections = append(sections, section{
+			name:        sectionName,
+			options:     map[string]any{},
+			sectionType: sectionType,
+		})
+		index = len(sections) - 1
+	}
+
+	sections[index].sectionType = sectionType
+	s.changes[config] = append(s.changes[config], []string{"add", sectionName, sectionType})
+	for option, value := range values {
+		sections[index].options[option] = normalizeValue(value)
+		s.changes[config] = append(s.changes[config], []string{"set", sectionName, option, fmt.Sprint(sections[index].options[option])})
+	}
+
+	s.staged[config] = sections
+	return true
+}
+
+func (s *Server) delete(
+	config string,
+	sectionName string,
+	option *string,
+) bool {
+	sections := s.current(config)
+	index := findSection(sections, sectionName)
+	if index < 0 {
+		return false
+	}
+
+	name := sections[index].name
+	if option == nil {
+		sections = s.stage(config)
+		s.staged[config] = append(sections[:index], sections[index+1:]...)
+		s.changes[config] = append(s.changes[config], []string{"remove", name})
+		return true
+	}
+
+	if _, ok := sections[index].options[*option]; !ok {
+		return false
+	}
+
+	sections = s.stage(config)
+	delete(sections[index].options, *option)
+	s.changes[config] = append(s.changes[config], []string{"remove", name, *option})
+	return true
+}
+
+func (s *Server) getAll(
+	config string,
+	sectionName string,
+) (map[string]any, bool) {
+	sections := s.current(config)
+	index := findSection(sections, sectionName)
+	if index < 0 {
+		return nil, false
+	}
+
+	return sections[index].toJSON(), true
+}
+
+// getAllSections returns every section of a config,
+// with the `.index` metadata LuCI adds when listing a whole config.
+// Like LuCI, a config without any sections does not exist.
+func (s *Server) getAllSections(
+	config string,
+) any {
+	sections := s.current(config)
+	if len(sections) == 0 {
+		return nil
+	}
+
+	result := map[string]any{}
+	for index, section := range sections {
+		values := section.toJSON()
+		values[".index"] = index
+		result[section.name] = values
+	}
+
+	return result
+}
+
+// current returns the sections of a config as reads see them.
+func (s *Server) current(
+	config string,
+) []section {
+	staged, ok := s.staged[config]
+	if ok {
+		return staged
+	}
+
+	return s.committed[config]
+}
+
+func (s *Server) handleAuth(
+	request rpcRequest,
+) (any, error) {
+	switch request.Method {
+	case methodLogin:
+		var (
+			password string
+			username string
+		)
+		err := unmarshalParams(request.Params, &username, &password)
+		if err != nil {
+			return nil, err
+		}
+
+		if username != s.Username || password != s.Password {
+			return nil, nil
+		}
+
+		token := fmt.Sprintf("session%d", len(s.sessions)+1)
+		s.sessions[token] = true
+		return token, nil
+
+	default:
+		return nil, fmt.Errorf("method not found: %s", request.Method)
+	}
+}
+
+func (s *Server) handleSys(
+	request rpcRequest,
+) (any, error) {
+	switch request.Method {
+	// The only command the provider runs is the one listing the configs on the device.
+	// Without any configs set,
+	// the device cannot run commands at all.
+	case methodExec:
+		if s.configs == nil {
+			return nil, fmt.Errorf("method not found: %s", request.Method)
+		}
+
+		return strings.Join(s.configs, "\n") + "\n", nil
+
+	case methodInitReload, methodInitRestart:
+		var service string
+		err := unmarshalParams(request.Params, &service)
+		if err != nil {
+			return nil, err
+		}
+
+		action := strings.TrimPrefix(request.Method, "init.")
+		s.serviceActions = append(s.serviceActions, fmt.Sprintf("%s %s", service, action))
+		return true, nil
+
+	default:
+		return nil, fmt.Errorf("method not found: %s", request.Method)
+	}
+}
+
+func (s *Server) handleUCI(
+	request rpcRequest,
+) (any, error) {
+	switch request.Method {
+	case methodAdd:
+		var (
+			config      string
+			sectionType string
+		)
+		err := unmarshalParams(request.Params, &config, &sectionType)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.addSection(config, sectionType), nil
+
+	case methodChanges:
+		var config string
+		err := unmarshalParams(request.Params, &config)
+		if err != nil {
+			return nil, err
+		}
+
+		changes := s.changes[config]
+		if changes == nil {
+			return [][]string{}, nil
+		}
+
+		return changes, nil
+
+	case methodCommit:
+		var config string
+		err := unmarshalParams(request.Params, &config)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.commit(config), nil
+
+	case methodDelete:
+		var (
+			config      string
+			option      *string
+			sectionName string
+		)
+		if len(request.Params) > 2 {
+			err := unmarshalParams(request.Params, &config, &sectionName, &option)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err := unmarshalParams(request.Params, &config, &sectionName)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return nullIfFalse(s.delete(config, sectionName, option)), nil
+
+	case methodGetAll:
+		var (
+			config      string
+			sectionName string
+		)
+		if len(request.Params) < 2 {
+			err := unmarshalParams(request.Params, &config)
+			if err != nil {
+				return nil, err
+			}
+
+			return s.getAllSections(config), nil
+		}
+
+		err := unmarshalParams(request.Params, &config, &sectionName)
+		if err != nil {
+			return nil, err
+		}
+
+		result, ok := s.getAll(config, sectionName)
+		if !ok {
+			return nil, nil
+		}
+
+		return result, nil
+
+	case methodReorder:
+		var (
+			config      string
+			index       int
+			sectionName string
+		)
+		err := unmarshalParams(request.Params, &config, &sectionName, &index)
+		if err != nil {
+			return nil, err
+		}
+
+		return nullIfFalse(s.reorder(config, sectionName, index)), nil
+
+	case methodRevert:
+		var config string
+		err := unmarshalParams(request.Params, &config)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.revert(config), nil
+
+	case methodSection:
+		var (
+			config      string
+			sectionName string
+			sectionType string
+			values      map[string]any
+		)
+		err := unmarshalParams(request.Params, &config, &sectionType, &sectionName, &values)
+		if err != nil {
+			return nil, err
+		}
+
+		return nullIfFalse(s.createSection(config, sectionType, sectionName, values)), nil
+
+	case methodTSet:
+		var (
+			config      string
+			sectionName string
+			values      map[string]any
+		)
+		err := unmarshalParams(request.Params, &config, &sectionName, &values)
+		if err != nil {
+			return nil, err
+		}
+
+		return nullIfFalse(s.updateSection(config, sectionName, values)), nil
+
+	default:
+		return nil, fmt.Errorf("method not found: %s", request.Method)
+	}
+}
+
+// reorder moves a section to the `index` among the other sections of the config,
+// the way UCI does.
+func (s *Server) reorder(
+	config string,
+	sectionName string,
+	index int,
+) bool {
+	sections := s.current(config)
+	current := findSection(sections, sectionName)
+	if current < 0 {
+		return false
+	}
+
+	sections = s.stage(config)
+	moved := sections[current]
+	sections = append(sections[:current], sections[current+1:]...)
+	if index < 0 || index > len(sections) {
+		index = len(sections)
+	}
+
+	sections = append(sections[:index], append([]section{moved}, sections[index:]...)...)
+	s.staged[config] = sections
+	s.changes[config] = append(s.changes[config], []string{"order", moved.name, strconv.Itoa(index)})
+	return true
+}
+
+// revert throws away the staged changes of a config.
+func (s *Server) revert(
+	config string,
+) bool {
+	delete(s.staged, config)
+	delete(s.changes, config)
+	return true
+}
+
+func (s *Server) serveHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var request rpcRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var result any
+	switch r.URL.Path {
+	case pathAuth:
+		result, err = s.handleAuth(request)
+
+	case pathSys:
+		if !s.sessions[r.URL.Query().Get(queryKeyAuth)] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		result, err = s.handleSys(request)
+
+	case pathUCI:
+		if !s.sessions[r.URL.Query().Get(queryKeyAuth)] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		result, err = s.handleUCI(request)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	response := rpcResponse{
+		Result: result,
+	}
+	if err != nil {
+		message := err.Error()
+		response.Error = &message
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// stage returns the staged sections of a config,
+// copying the committed sections the first time the config changes.
+func (s *Server) stage(
+	config string,
+) []section {
+	staged, ok := s.staged[config]
+	if ok {
+		return staged
+	}
+
+	staged = []section{}
+	for _, committed := range s.committed[config] {
+		staged = append(staged, committed.clone())
+	}
+
+	s.staged[config] = staged
+	return staged
+}
+
+func (s *Server) updateSection(
+	config string,
+	sectionName string,
+	values map[string]any,
+) bool {
+	index := findSection(s.current(config), sectionName)
+	if index < 0 {
+		return false
+	}
+
+	sections := s.stage(config)
+	for option, value := range values {
+		sections[index].options[option] = normalizeValue(value)
+		s.changes[config] = append(s.changes[config], []string{"set", sections[index].name, option, fmt.Sprint(sections[index].options[option])})
+	}
+
+	return true
+}
+
+// TerraformSteps runs the [resource.TestStep]s against the OpenWrt provider as a unit test.
+//
+// Terraform itself is still required.
+// It is found on the `PATH`, or at `TF_ACC_TERRAFORM_PATH`.
+// If neither is available, the test fails in CI (when `CI` is set),
+// and is skipped everywhere else.
+func TerraformSteps(
+	t *testing.T,
+	testStep resource.TestStep,
+	testSteps ...resource.TestStep,
+) {
+	t.Helper()
+
+	_, ok := os.LookupEnv(terraformPathEnvironmentVariable)
+	if !ok {
+		_, err := exec.LookPath("terraform")
+		if err != nil {
+			message := fmt.Sprintf("Terraform is required, but was not found on the PATH and %s is not set", terraformPathEnvironmentVariable)
+			_, inCI := os.LookupEnv(ciEnvironmentVariable)
+			if inCI {
+				t.Fatal(message)
+			}
+
+			t.Skip(message)
+		}
+	}
+
+	allTestSteps := append([]resource.TestStep{testStep}, testSteps...)
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"openwrt": providerserver.NewProtocol6WithError(openwrt.New("test", os.LookupEnv)),
+		},
+		Steps: allTestSteps,
+	})
+}
+
+type rpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	Error  *string `json:"error"`
+	Result any     `json:"result"`
+}
+
+type section struct {
+	anonymous   bool
+	name        string
+	options     map[string]any
+	sectionType string
+}
+
+// anonymousName names an anonymous section at the `index` the way UCI does:
+// a counter of the sections in the config, followed by a hash of the section's type.
+// UCI names sections as it loads the config,
+// so the name changes when an earlier section is removed or moved.
+func (s section) anonymousName(
+	index int,
+) string {
+	hash := djbHash(s.sectionType)
+	return fmt.Sprintf("cfg%02x%04x", index+1, hash%(1<<16))
+}
+
+func (s section) clone() section {
+	options := map[string]any{}
+	for option, value := range s.options {
+		options[option] = value
+	}
+
+	return section{
+		anonymous:   s.anonymous,
+		name:        s.name,
+		options:     options,
+		sectionType: s.sectionType,
+	}
+}
+
+func (s section) toJSON() map[string]any {
+	result := map[string]any{
+		".anonymous": s.anonymous,
+		".name":      s.name,
+		".type":      s.sectionType,
+	}
+	for option, value := range s.options {
+		result[option] = value
+	}
+
+	return result
+}
+
+// djbHash is the hash UCI uses to name anonymous sections.
+func djbHash(
+	value string,
+) uint32 {
+	hash := uint32(5381)
+	for index := 0; index < len(value); index++ {
+		hash = (hash << 5) + hash + uint32(value[index])
+	}
+
+	return hash & 0x7FFFFFFF
+}
+
+// findSection looks up a section by name,
+// or by UCI's extended syntax (e.g. `@system[0]`).
+// It returns -1 if the section does not exist.
+func findSection(
+	sections []section,
+	sectionName string,
+) int {
+	matches := extendedSectionPattern.FindStringSubmatch(sectionName)
+	if matches == nil {
+		for index, section := range sections {
+			if section.name == sectionName {
+				return index
+			}
+		}
+
+		return -1
+	}
+
+	indices := []int{}
+	for index, section := range sections {
+		if section.sectionType == matches[1] {
+			indices = append(indices, index)
+		}
+	}
+
+	position, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return -1
+	}
+
+	if position < 0 {
+		position += len(indices)
+	}
+
+	if position < 0 || position >= len(indices) {
+		return -1
+	}
+
+	return indices[position]
+}
+
+// normalizeValue converts a JSON value into what UCI would store.
+// UCI only has strings and lists of strings,
+// so LuCI converts booleans and numbers to strings.
+func normalizeValue(
+	value any,
+) any {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+
+	case int:
+		return strconv.Itoa(v)
+
+	case []any:
+		result := []string{}
+		for _, element := range v {
+			result = append(result, fmt.Sprint(normalizeValue(element)))
+		}
+
+		return result
+
+	case []string:
+		return append([]string{}, v...)
+
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// nullIfFalse mirrors LuCI responding with `null` when a UCI operation fails.
+func nullIfFalse(
+	result bool,
+) any {
+	if !result {
+		return nil
+	}
+
+	return true
+}
+
+func unmarshalParams(
+	params []json.RawMessage,
+	targets ...any,
+) error {
+	if len(params) < len(targets) {
+		return fmt.Errorf("expected %d params, got %d", len(targets), len(params))
+	}
+
+	for index, target := range targets {
+		err := json.Unmarshal(params[index], target)
+		if err != nil {
+			return fmt.Errorf("invalid param %d: %w", index, err)
+		}
+	}
+
+	return nil
+}
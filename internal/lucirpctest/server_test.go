@@ -0,0 +1,340 @@
+package lucirpctest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestServer(t *testing.T) {
+	t.Run("stages changes until they are committed", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"testing",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		got, ok := server.CommittedSection("network", "testing")
+		assert.Check(t, ok)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous": lucirpc.Boolean(false),
+			".name":      lucirpc.String("testing"),
+			".type":      lucirpc.String("interface"),
+		})
+		changes, err := client.ShowChanges(ctx, "network")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, changes, [][]string{})
+	})
+
+	t.Run("adds anonymous sections", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		first, err := client.AddSection(ctx, "firewall", "rule", lucirpc.Options{
+			"name": lucirpc.String("first"),
+		})
+		assert.NilError(t, err)
+		second, err := client.AddSection(ctx, "firewall", "rule", lucirpc.Options{})
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, first != second)
+		got, ok := server.CommittedSection("firewall", first)
+		assert.Check(t, ok)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous": lucirpc.Boolean(true),
+			".name":      lucirpc.String(first),
+			".type":      lucirpc.String("rule"),
+			"name":       lucirpc.String("first"),
+		})
+	})
+
+	t.Run("names anonymous sections again when an earlier section is deleted", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+		first, err := client.AddSection(ctx, "firewall", "rule", lucirpc.Options{})
+		assert.NilError(t, err)
+		second, err := client.AddSection(ctx, "firewall", "rule", lucirpc.Options{
+			"name": lucirpc.String("second"),
+		})
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.DeleteSection(ctx, "firewall", first)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, first, "cfg0192bd")
+		assert.Equal(t, second, "cfg0292bd")
+		_, ok := server.CommittedSection("firewall", second)
+		assert.Check(t, !ok)
+		got, ok := server.CommittedSection("firewall", "cfg0192bd")
+		assert.Check(t, ok)
+		assert.DeepEqual(t, got["name"], lucirpc.String("second"))
+	})
+
+	t.Run("stores values the way UCI does", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"testing",
+			lucirpc.Options{
+				"option_1": lucirpc.Boolean(true),
+				"option_2": lucirpc.Integer(31),
+				"option_3": lucirpc.ListString([]string{"foo", "bar", "baz"}),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		got, err := client.GetSection(ctx, "network", "testing")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous": lucirpc.Boolean(false),
+			".name":      lucirpc.String("testing"),
+			".type":      lucirpc.String("interface"),
+			"option_1":   lucirpc.Boolean(true),
+			"option_2":   lucirpc.Integer(31),
+			"option_3":   lucirpc.ListString([]string{"foo", "bar", "baz"}),
+		})
+	})
+
+	t.Run("updates and deletes options", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "testing", map[string]any{
+			"dns":   []string{"1.1.1.1"},
+			"proto": "static",
+		})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.UpdateSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.Options{
+				"proto": lucirpc.String("dhcp"),
+			},
+		)
+		assert.NilError(t, err)
+		_, err = client.DeleteOptions(
+			ctx,
+			"network",
+			"testing",
+			[]string{"dns"},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		got, ok := server.CommittedSection("network", "testing")
+		assert.Check(t, ok)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous": lucirpc.Boolean(false),
+			".name":      lucirpc.String("testing"),
+			".type":      lucirpc.String("interface"),
+			"proto":      lucirpc.String("dhcp"),
+		})
+	})
+
+	t.Run("deletes sections", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "testing", map[string]any{})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.DeleteSection(ctx, "network", "testing")
+
+		// Then
+		assert.NilError(t, err)
+		_, ok := server.CommittedSection("network", "testing")
+		assert.Check(t, !ok)
+		_, err = client.GetSection(ctx, "network", "testing")
+		var notFound lucirpc.NotFoundError
+		assert.Assert(t, errors.As(err, &notFound))
+	})
+
+	t.Run("removes sections outside of the client", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "first", map[string]any{})
+		server.SetSection("network", "interface", "second", map[string]any{})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		removed := server.RemoveSection("network", "first")
+
+		// Then
+		assert.Check(t, removed)
+		_, err := client.GetSection(ctx, "network", "first")
+		var notFound lucirpc.NotFoundError
+		assert.Assert(t, errors.As(err, &notFound))
+		_, ok := server.CommittedSection("network", "second")
+		assert.Check(t, ok)
+		assert.Check(t, !server.RemoveSection("network", "first"))
+	})
+
+	t.Run("finds sections with the extended syntax", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("system", "system", "cfg01e48a", map[string]any{
+			"hostname": "OpenWrt",
+		})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		got, err := client.GetSection(ctx, "system", "@system[0]")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous": lucirpc.Boolean(false),
+			".name":      lucirpc.String("cfg01e48a"),
+			".type":      lucirpc.String("system"),
+			"hostname":   lucirpc.String("OpenWrt"),
+		})
+	})
+
+	t.Run("lists sections in order", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("dhcp", "dnsmasq", "cfg01411c", map[string]any{})
+		server.SetSection("dhcp", "host", "first", map[string]any{})
+		server.SetSection("dhcp", "host", "second", map[string]any{})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		got, err := client.ListSections(ctx, "dhcp", "host")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []lucirpc.Options{
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(1),
+				".name":      lucirpc.String("first"),
+				".type":      lucirpc.String("host"),
+			},
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(2),
+				".name":      lucirpc.String("second"),
+				".type":      lucirpc.String("host"),
+			},
+		})
+	})
+
+	t.Run("reorders sections", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("firewall", "defaults", "cfg01e63d", map[string]any{})
+		server.SetSection("firewall", "rule", "first", map[string]any{})
+		server.SetSection("firewall", "rule", "second", map[string]any{})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.ReorderSection(ctx, "firewall", "second", 1)
+
+		// Then
+		assert.NilError(t, err)
+		got, err := client.ListSections(ctx, "firewall", "rule")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []lucirpc.Options{
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(1),
+				".name":      lucirpc.String("second"),
+				".type":      lucirpc.String("rule"),
+			},
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(2),
+				".name":      lucirpc.String("first"),
+				".type":      lucirpc.String("rule"),
+			},
+		})
+	})
+
+	t.Run("rejects the wrong password", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			server.Scheme,
+			server.Hostname,
+			server.Port,
+			server.Username,
+			"wrong",
+		)
+
+		// Then
+		var authentication lucirpc.AuthenticationError
+		assert.Assert(t, errors.As(err, &authentication))
+	})
+
+	t.Run("reports the configs that were set", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetConfigs("network", "dhcp")
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got.Configs, []string{"dhcp", "network"})
+		assert.Check(t, !got.HasConfig("wireless"))
+	})
+
+	t.Run("has every config unless the configs are set", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got.HasConfig("wireless"))
+	})
+}
@@ -0,0 +1,108 @@
+package lucirpc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+const (
+	humanReadableCapabilities = "probe capabilities"
+	humanReadableListConfigs  = "list configs"
+	humanReadableReadRelease  = "read release"
+
+	// capabilitiesListConfigsCommand lists the UCI configs on the device.
+	// Each config is a file in this directory.
+	capabilitiesListConfigsCommand = "ls /etc/config"
+
+	// capabilitiesReleaseFile describes the OpenWrt release the device runs.
+	capabilitiesReleaseFile = "/etc/openwrt_release"
+
+	// capabilitiesReleaseKey is the human-readable release in the [capabilitiesReleaseFile] (e.g. `OpenWrt 22.03.3 r20028-43d71ad93e`).
+	capabilitiesReleaseKey = "DISTRIB_DESCRIPTION"
+)
+
+// Capabilities describe what a device can do.
+// Anything that could not be found out is left empty.
+type Capabilities struct {
+	// Configs are the UCI configs on the device (e.g. `network`, `wireless`), sorted.
+	// This is nil if they could not be listed.
+	Configs []string
+
+	// Release is the OpenWrt release the device runs (e.g. `OpenWrt 22.03.3 r20028-43d71ad93e`).
+	Release string
+
+	// RPCModules are the RPC modules that answered while probing, sorted.
+	// For LuCI RPC these are LuCI's libraries (e.g. `uci`, `sys`, `fs`),
+	// and for ubus these are ubus objects (e.g. `uci`, `system`).
+	// This is nil for transports that do not use RPC.
+	RPCModules []string
+}
+
+// HasConfig checks if the UCI `config` is on the device.
+// If the configs could not be listed,
+// every config is assumed to be there.
+func (c Capabilities) HasConfig(
+	config string,
+) bool {
+	if c.Configs == nil {
+		return true
+	}
+
+	index := sort.SearchStrings(c.Configs, config)
+	return index < len(c.Configs) && c.Configs[index] == config
+}
+
+// Capabilities probes what the device can do.
+// This also checks that the device can still be reached and logged in to.
+//
+// Only problems reaching or logging in to the device are returned as errors.
+// Anything the device does not allow (or does not have) is left out of the [Capabilities] instead.
+func (c *Client) Capabilities(
+	ctx context.Context,
+) (Capabilities, error) {
+	return c.transport.capabilities(ctx)
+}
+
+// isProbeFailure checks if the `err` from probing means the device could not be reached or logged in to.
+// Any other error only means the device does not have what was probed.
+// That includes a device that answers with something other than a 200 (e.g. a 404 for an RPC module that is not installed),
+// so only the temporary problems that [retry] gave up on count.
+func isProbeFailure(
+	err error,
+) bool {
+	var authenticationError AuthenticationError
+	var temporary temporaryError
+	return errors.As(err, &authenticationError) || errors.As(err, &temporary)
+}
+
+// parseConfigs parses the output of [capabilitiesListConfigsCommand].
+func parseConfigs(
+	output string,
+) []string {
+	configs := strings.Fields(output)
+	sort.Strings(configs)
+	return configs
+}
+
+// parseRelease finds the human-readable release in the contents of the [capabilitiesReleaseFile].
+// The file is a shell script that sets variables,
+// quoted the same way as UCI values.
+func parseRelease(
+	contents string,
+) string {
+	statements, err := parseUCIStatements(contents)
+	if err != nil {
+		return ""
+	}
+
+	for _, statement := range statements {
+		key, value, ok := strings.Cut(strings.Join(statement, " "), "=")
+		if ok && key == capabilitiesReleaseKey {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,223 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+const (
+	openWrtRelease = `DISTRIB_ID='OpenWrt'
+DISTRIB_RELEASE='22.03.3'
+DISTRIB_REVISION='r20028-43d71ad93e'
+DISTRIB_DESCRIPTION='OpenWrt 22.03.3 r20028-43d71ad93e'
+`
+)
+
+func TestCapabilitiesHasConfig(t *testing.T) {
+	t.Run("checks the configs that were listed", func(t *testing.T) {
+		// Given
+		capabilities := lucirpc.Capabilities{
+			Configs: []string{"dhcp", "network"},
+		}
+
+		// When
+		hasNetwork := capabilities.HasConfig("network")
+		hasWireless := capabilities.HasConfig("wireless")
+
+		// Then
+		assert.Check(t, hasNetwork)
+		assert.Check(t, !hasWireless)
+	})
+
+	t.Run("assumes every config is there if they could not be listed", func(t *testing.T) {
+		// Given
+		capabilities := lucirpc.Capabilities{}
+
+		// When
+		got := capabilities.HasConfig("wireless")
+
+		// Then
+		assert.Check(t, got)
+	})
+}
+
+func TestClientCapabilities(t *testing.T) {
+	t.Run("probes with the sys and fs libraries", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/sys":
+				fmt.Fprintf(w, `{
+					"result": "network\ndhcp\nsystem\n"
+				}`)
+
+			case "/cgi-bin/luci/rpc/fs":
+				fmt.Fprintf(w, `{
+					"result": %q
+				}`, base64.StdEncoding.EncodeToString([]byte(openWrtRelease)))
+
+			default:
+				t.Errorf("unexpected request: %s", r.URL.Path)
+			}
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Capabilities{
+			Configs:    []string{"dhcp", "network", "system"},
+			Release:    "OpenWrt 22.03.3 r20028-43d71ad93e",
+			RPCModules: []string{"fs", "sys"},
+		})
+	})
+
+	t.Run("leaves out what the device does not have", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Capabilities{
+			RPCModules: []string{},
+		})
+	})
+
+	t.Run("probes with the uci and system objects over ubus", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			switch call.Object + " " + call.Method {
+			case "uci configs":
+				return `[0, {"configs": ["network", "dhcp"]}]`
+
+			case "system board":
+				return `[0, {"release": {"description": "OpenWrt 22.03.3 r20028-43d71ad93e"}}]`
+
+			default:
+				t.Errorf("unexpected call: %#v", call)
+				return `[3]`
+			}
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Capabilities{
+			Configs:    []string{"dhcp", "network"},
+			Release:    "OpenWrt 22.03.3 r20028-43d71ad93e",
+			RPCModules: []string{"system", "uci"},
+		})
+	})
+
+	t.Run("leaves out ubus objects the session cannot call", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			if call.Object == "system" {
+				return `[6]`
+			}
+
+			return `[0, {"configs": ["network"]}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Capabilities{
+			Configs:    []string{"network"},
+			RPCModules: []string{"uci"},
+		})
+	})
+
+	t.Run("probes with the command line over SSH", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(command sshCommand) sshReply {
+			switch command.Command {
+			case "ls /etc/config":
+				return sshReply{Stdout: "system\nnetwork\n"}
+
+			case "cat /etc/openwrt_release":
+				return sshReply{Stdout: openWrtRelease}
+
+			default:
+				return sshReply{Status: 127, Stderr: "not found"}
+			}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.Capabilities(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Capabilities{
+			Configs: []string{"network", "system"},
+			Release: "OpenWrt 22.03.3 r20028-43d71ad93e",
+		})
+	})
+
+	t.Run("returns an AuthenticationError when the session cannot be renewed", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithSessionToken("expired"),
+		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.Capabilities(ctx)
+
+		// Then
+		var authenticationError lucirpc.AuthenticationError
+		assert.Check(t, errors.As(err, &authenticationError))
+	})
+}
@@ -1,78 +1,171 @@
 package lucirpc
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
+	"sort"
+	"time"
 )
 
 const (
-	humanReadableCommitChanges = "commit changes"
-	humanReadableCreateSection = "create section"
-	humanReadableDeleteSection = "delete section"
-	humanReadableGetSection    = "get section"
-	humanReadableLogin         = "login"
-	humanReadableShowChanges   = "show changes"
-	humanReadableUpdateSection = "update section"
-
-	methodChanges = "changes"
-	methodCommit  = "commit"
-	methodDelete  = "delete"
-	methodGetAll  = "get_all"
-	methodLogin   = "login"
-	methodSection = "section"
-	methodTSet    = "tset"
-
-	pathAuth = "/cgi-bin/luci/rpc/auth"
-	pathUCI  = "/cgi-bin/luci/rpc/uci"
-
-	queryKeyAuth = "auth"
+	humanReadableAddSection     = "add section"
+	humanReadableChangeSection  = "change section"
+	humanReadableApplyChanges   = "apply changes"
+	humanReadableCheckSession   = "check session"
+	humanReadableCommitChanges  = "commit changes"
+	humanReadableConfirmChanges = "confirm changes"
+	humanReadableCreateSection  = "create section"
+	humanReadableDeleteOptions  = "delete options"
+	humanReadableDeleteSection  = "delete section"
+	humanReadableGetSection     = "get section"
+	humanReadableListSections   = "list sections"
+	humanReadableLogin          = "login"
+	humanReadableReorderSection = "reorder section"
+	humanReadableRevertChanges  = "revert changes"
+	humanReadableRollback       = "roll back changes"
+	humanReadableServiceAction  = "run service action"
+	humanReadableShowChanges    = "show changes"
+	humanReadableUpdateSection  = "update section"
+
+	// These name the transports in a [NotSupportedError].
+	humanReadableTransportLuCIRPC = "the LuCI RPC transport"
+	humanReadableTransportUbus    = "the ubus transport"
+)
+
+const (
+	// PendingChangesWarn commits changes that were already staged in a config along with the [Client]'s own.
+	// The changes are reported with [ContextWithPendingChangesReporter].
+	// This is the default.
+	PendingChangesWarn PendingChangesPolicy = iota
+
+	// PendingChangesFail refuses to change a config that already has staged changes,
+	// and returns a [PendingChangesError] instead.
+	PendingChangesFail
+
+	// PendingChangesRevert throws away changes that were already staged in a config before changing it.
+	// The changes that were thrown away are reported with [ContextWithPendingChangesReporter].
+	PendingChangesRevert
+)
+
+const (
+	// ServiceReload reloads the configuration of a service, like `/etc/init.d/<service> reload`.
+	ServiceReload ServiceAction = "reload"
+
+	// ServiceRestart stops and starts a service, like `/etc/init.d/<service> restart`.
+	ServiceRestart ServiceAction = "restart"
 )
 
 type Client struct {
-	jsonRPCClientUCI jsonRPCClient
+	commits        *commitBatcher
+	locks          *configLocks
+	pendingChanges PendingChangesPolicy
+	rollback       *rollback
+	transport      transport
 }
 
-func (c *Client) CommitChanges(
+// ClientOption changes how a [Client] behaves.
+type ClientOption func(*clientOptions)
+
+// PendingChangesPolicy decides what a [Client] does when a config already has staged changes that it did not make.
+// Those could be from someone editing the config in LuCI,
+// or left behind by another tool.
+type PendingChangesPolicy int
+
+// SectionChanges are the changes [Client.ChangeSection] makes to an existing section.
+type SectionChanges struct {
+	// DeleteOptions are removed from the section.
+	DeleteOptions []string
+
+	// Index moves the section to this index among every section of the config, like [Client.ReorderSection].
+	// If nil, the section is not moved.
+	Index *int
+
+	// Options are set on the section.
+	Options Options
+}
+
+// ServiceAction is an action the init script of a service can run.
+type ServiceAction string
+
+// AddSection creates an anonymous section of the `sectionType`.
+// The device generates the name of the section (e.g. `cfg0a1b2c`),
+// and that name is returned.
+func (c *Client) AddSection(
 	ctx context.Context,
 	config string,
-) (bool, error) {
-	marshalledConfig, err := json.Marshal(config)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableCommitChanges, err)
-	}
+	sectionType string,
+	options Options,
+) (string, error) {
+	return c.addSection(ctx, config, sectionType, options, nil)
+}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodCommit,
-		Params: []json.RawMessage{
-			marshalledConfig,
-		},
-	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
+// AddSectionAt creates an anonymous section like [Client.AddSection],
+// and moves it to the `index` among every section of the `config` like [Client.ReorderSection].
+// Both are committed together,
+// so the section is never in the wrong place on the device.
+func (c *Client) AddSectionAt(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	options Options,
+	index int,
+) (string, error) {
+	return c.addSection(ctx, config, sectionType, options, &index)
+}
+
+// ChangeSection sets and removes options of an existing section.
+// Options are only set if there are any to set.
+// Every change is staged before committing,
+// so the device reloads once and never sees only some of the changes.
+func (c *Client) ChangeSection(
+	ctx context.Context,
+	config string,
+	section string,
+	changes SectionChanges,
+) (bool, error) {
+	return c.change(
 		ctx,
-		humanReadableCommitChanges,
-		requestBody,
+		config,
+		humanReadableChangeSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			if len(changes.Options) > 0 {
+				result, err := stager.updateSection(
+					ctx,
+					section,
+					changes.Options,
+				)
+				if err != nil || !result {
+					return result, err
+				}
+			}
+
+			if len(changes.DeleteOptions) > 0 {
+				result, err := stager.deleteOptions(
+					ctx,
+					section,
+					changes.DeleteOptions,
+				)
+				if err != nil || !result {
+					return result, err
+				}
+			}
+
+			return stager.reorderSection(ctx, section, changes.Index)
+		},
 	)
-	if err != nil {
-		return false, fmt.Errorf("unable to %s: %w", humanReadableCommitChanges, err)
-	}
-
-	// The result can be `true` to indicate success,
-	// or `null` to indicate failure.
-	var result bool
-	if responseBody == nil {
-		return false, nil
-	}
-
-	err = json.Unmarshal(*responseBody, &result)
-	if err != nil {
-		return false, fmt.Errorf("unable to parse %s response: %w", humanReadableCommitChanges, err)
-	}
+}
 
-	return result, nil
+func (c *Client) CommitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	return c.transport.commitChanges(
+		ctx,
+		config,
+	)
 }
 
 func (c *Client) CreateSection(
@@ -82,450 +175,826 @@ func (c *Client) CreateSection(
 	section string,
 	options Options,
 ) (bool, error) {
-	marshalledConfig, err := json.Marshal(config)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableCreateSection, err)
-	}
-
-	marshalledSectionType, err := json.Marshal(sectionType)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize sectionType %q for %s: %w", sectionType, humanReadableCreateSection, err)
-	}
-
-	marshalledSection, err := json.Marshal(section)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableCreateSection, err)
-	}
+	return c.createSection(ctx, config, sectionType, section, options, nil)
+}
 
-	marshalledOptions, err := json.Marshal(options)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize options %q for %s: %w", options, humanReadableCreateSection, err)
-	}
+// CreateSectionAt creates a section like [Client.CreateSection],
+// and moves it to the `index` among every section of the `config` like [Client.ReorderSection].
+// Both are committed together,
+// so the section is never in the wrong place on the device.
+func (c *Client) CreateSectionAt(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	section string,
+	options Options,
+	index int,
+) (bool, error) {
+	return c.createSection(ctx, config, sectionType, section, options, &index)
+}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodSection,
-		Params: []json.RawMessage{
-			marshalledConfig,
-			marshalledSectionType,
-			marshalledSection,
-			marshalledOptions,
+// DeleteOptions removes the given `options` from an existing section.
+func (c *Client) DeleteOptions(
+	ctx context.Context,
+	config string,
+	section string,
+	options []string,
+) (bool, error) {
+	return c.change(
+		ctx,
+		config,
+		humanReadableDeleteOptions,
+		func(ctx context.Context, stager stager) (bool, error) {
+			return stager.deleteOptions(
+				ctx,
+				section,
+				options,
+			)
 		},
-	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
+	)
+}
+
+func (c *Client) DeleteSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (bool, error) {
+	return c.change(
 		ctx,
-		humanReadableCreateSection,
-		requestBody,
+		config,
+		humanReadableDeleteSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			return stager.deleteSection(
+				ctx,
+				section,
+			)
+		},
 	)
-	if err != nil {
-		return false, fmt.Errorf("unable to %s: %w", humanReadableCreateSection, err)
-	}
+}
 
-	// The result can be `true` to indicate success,
-	// or `null` to indicate failure.
-	var result bool
-	if responseBody == nil {
-		return false, nil
-	}
+func (c *Client) GetSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (Options, error) {
+	return c.transport.getSection(
+		ctx,
+		config,
+		section,
+	)
+}
 
-	err = json.Unmarshal(*responseBody, &result)
-	if err != nil {
-		return false, fmt.Errorf("unable to parse %s response: %w", humanReadableCreateSection, err)
-	}
+// ListSections returns the sections of a `config` in the order they appear.
+// If `sectionType` is not empty,
+// only sections of that type are returned.
+//
+// Each section includes the `.name`, `.type`, `.anonymous`, and `.index` metadata.
+func (c *Client) ListSections(
+	ctx context.Context,
+	config string,
+	sectionType string,
+) ([]Options, error) {
+	return c.transport.listSections(
+		ctx,
+		config,
+		sectionType,
+	)
+}
 
-	if !result {
-		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableCreateSection)
-	}
+// ReorderSection moves a `section` so it is at the `index` among every section of the `config`.
+// Like UCI,
+// the `index` counts the other sections of the `config`,
+// so the section ends up before the section currently at that `index`.
+func (c *Client) ReorderSection(
+	ctx context.Context,
+	config string,
+	section string,
+	index int,
+) (bool, error) {
+	return c.change(
+		ctx,
+		config,
+		humanReadableReorderSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			return stager.reorderSection(
+				ctx,
+				section,
+				&index,
+			)
+		},
+	)
+}
 
-	result, err = c.CommitChanges(
+// RevertChanges throws away every change staged in the `config`.
+func (c *Client) RevertChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	return c.transport.revertChanges(
 		ctx,
 		config,
 	)
-	if err != nil {
-		return false, fmt.Errorf("was able to %s, but could not %s: %w", humanReadableCreateSection, humanReadableCommitChanges, err)
-	}
+}
 
-	return result, nil
+// RunServiceAction runs the `action` of the init script for the `service` (e.g. `/etc/init.d/network reload`).
+// This is how committed changes are made live for services that do not pick them up on their own.
+// The result is false if the device could not run it (e.g. the service does not exist).
+func (c *Client) RunServiceAction(
+	ctx context.Context,
+	service string,
+	action ServiceAction,
+) (bool, error) {
+	return c.transport.serviceAction(
+		ctx,
+		service,
+		action,
+	)
 }
 
-func (c *Client) DeleteSection(
+func (c *Client) ShowChanges(
+	ctx context.Context,
+	config string,
+) ([][]string, error) {
+	return c.transport.showChanges(
+		ctx,
+		config,
+	)
+}
+
+func (c *Client) UpdateSection(
 	ctx context.Context,
 	config string,
 	section string,
+	options Options,
 ) (bool, error) {
-	marshalledConfig, err := json.Marshal(config)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableDeleteSection, err)
-	}
+	return c.change(
+		ctx,
+		config,
+		humanReadableUpdateSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			return stager.updateSection(
+				ctx,
+				section,
+				options,
+			)
+		},
+	)
+}
 
-	marshalledSection, err := json.Marshal(section)
+// NewClient constructs a [Client] that talks to the LuCI JSON-RPC API.
+// This requires the `luci-mod-rpc` package on the device.
+func NewClient(
+	ctx context.Context,
+	scheme string,
+	hostname string,
+	port uint16,
+	username string,
+	password string,
+	options ...ClientOption,
+) (*Client, error) {
+	clientOptions := newClientOptions(options)
+	credentials, err := clientOptions.credentials(password)
 	if err != nil {
-		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableDeleteSection, err)
+		return nil, err
 	}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodDelete,
-		Params: []json.RawMessage{
-			marshalledConfig,
-			marshalledSection,
-		},
-	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
+	httpClient := clientOptions.httpClient()
+	transport, err := newLuCIRPCTransport(
 		ctx,
-		humanReadableDeleteSection,
-		requestBody,
+		*httpClient,
+		scheme,
+		joinHostPort(hostname, port),
+		username,
+		credentials,
+		clientOptions.retryPolicy,
 	)
 	if err != nil {
-		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteSection, err)
+		return nil, err
 	}
 
-	// The result can be `true` to indicate success,
-	// or `null` to indicate failure.
-	var result bool
-	if responseBody == nil {
-		return false, nil
-	}
+	return newClient(transport, clientOptions)
+}
 
-	err = json.Unmarshal(*responseBody, &result)
+// NewSSHClient constructs a [Client] that runs the `uci` command line tool over SSH.
+// This only requires an SSH server (e.g. dropbear) on the device.
+//
+// Only the [WithRequestTimeout], [WithRetries], [WithCommitBatchWindow], [WithPendingChanges], [WithPasswordCommand], and [WithPasswordFile] options apply.
+// The password is only read once, when connecting.
+func NewSSHClient(
+	ctx context.Context,
+	hostname string,
+	port uint16,
+	username string,
+	sshOptions SSHOptions,
+	options ...ClientOption,
+) (*Client, error) {
+	clientOptions := newClientOptions(options)
+	credentials, err := clientOptions.credentials(sshOptions.Password)
 	if err != nil {
-		return false, fmt.Errorf("unable to parse %s response: %w", humanReadableDeleteSection, err)
+		return nil, err
 	}
 
-	if !result {
-		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableDeleteSection)
+	if credentials.sessionToken != "" {
+		return nil, NewNotSupportedError("login with a session token", humanReadableTransportLuCIRPC, humanReadableTransportUbus)
 	}
 
-	result, err = c.CommitChanges(
+	sshOptions.Password, err = credentials.currentPassword(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newSSHTransport(
 		ctx,
-		config,
+		joinHostPort(hostname, port),
+		username,
+		sshOptions,
+		clientOptions.requestTimeout,
+		clientOptions.retryPolicy,
 	)
 	if err != nil {
-		return false, fmt.Errorf("was able to %s, but could not %s: %w", humanReadableDeleteSection, humanReadableCommitChanges, err)
+		return nil, err
 	}
 
-	return result, nil
+	return newClient(transport, clientOptions)
 }
 
-func (c *Client) GetSection(
+// NewUbusClient constructs a [Client] that talks to the ubus JSON-RPC API.
+// This only requires the `rpcd` and `uhttpd-mod-ubus` packages on the device.
+func NewUbusClient(
 	ctx context.Context,
-	config string,
-	section string,
-) (Options, error) {
-	marshalledConfig, err := json.Marshal(config)
-	if err != nil {
-		return nil, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableGetSection, err)
-	}
-
-	marshalledSection, err := json.Marshal(section)
+	scheme string,
+	hostname string,
+	port uint16,
+	username string,
+	password string,
+	options ...ClientOption,
+) (*Client, error) {
+	clientOptions := newClientOptions(options)
+	credentials, err := clientOptions.credentials(password)
 	if err != nil {
-		return nil, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableGetSection, err)
+		return nil, err
 	}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodGetAll,
-		Params: []json.RawMessage{
-			marshalledConfig,
-			marshalledSection,
-		},
-	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
+	httpClient := clientOptions.httpClient()
+	transport, err := newUbusTransport(
 		ctx,
-		humanReadableGetSection,
-		requestBody,
+		*httpClient,
+		scheme,
+		joinHostPort(hostname, port),
+		username,
+		credentials,
+		clientOptions.retryPolicy,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("unable to %s: %w", humanReadableGetSection, err)
+		return nil, err
 	}
 
-	if responseBody == nil {
-		return nil, fmt.Errorf("could not find section %s.%s", config, section)
-	}
+	return newClient(transport, clientOptions)
+}
 
-	// Depending on the `config` and `section`,
-	// this method can return a response that is an array instead of an object.
-	// We have to handle that case as well.
-	var unknownResult any
-	err = json.Unmarshal(*responseBody, &unknownResult)
-	if err != nil {
-		return nil, fmt.Errorf("unable to determine type of %s response: %w", humanReadableGetSection, err)
+// WithCommitBatchWindow makes [Client.CreateSection], [Client.DeleteSection], and [Client.UpdateSection] wait for `window` before committing.
+// Any other changes to the same config made while waiting are committed at the same time.
+//
+// A `window` of 0 (the default) commits after every change.
+func WithCommitBatchWindow(
+	window time.Duration,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.commitBatchWindow = window
 	}
+}
 
-	_, ok := unknownResult.([]any)
-	if ok {
-		return nil, fmt.Errorf("incorrect config (%q) and/or section (%q): result from LuCI: %s", config, section, *responseBody)
+// WithPasswordCommand makes the [Client] login with the password printed by running `command` (e.g. a credential helper).
+// The first element is the program, and the rest are its arguments.
+// The command is run every time the [Client] logs in,
+// and a single trailing line ending is removed from what it prints.
+//
+// The password given to the constructor must be empty.
+func WithPasswordCommand(
+	command []string,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.passwordCommand = command
 	}
+}
 
-	var result Options
-	err = json.Unmarshal(*responseBody, &result)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse %s response: %w", humanReadableGetSection, err)
+// WithPasswordFile makes the [Client] login with the password in the file at `path`.
+// The file is read every time the [Client] logs in,
+// and a single trailing line ending is removed from it.
+//
+// The password given to the constructor must be empty.
+func WithPasswordFile(
+	path string,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.passwordFile = path
 	}
-
-	return result, nil
 }
 
-func (c *Client) ShowChanges(
-	ctx context.Context,
-	config string,
-) ([][]string, error) {
-	marshalledConfig, err := json.Marshal(config)
-	if err != nil {
-		return nil, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableShowChanges, err)
+// WithPendingChanges decides what the [Client] does when a config it is about to change already has staged changes.
+// See [PendingChangesPolicy] for the choices.
+//
+// Changes the [Client] staged itself (e.g. while waiting for [WithCommitBatchWindow]) are not checked.
+func WithPendingChanges(
+	policy PendingChangesPolicy,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.pendingChanges = policy
 	}
+}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodChanges,
-		Params: []json.RawMessage{
-			marshalledConfig,
-		},
+// WithRequestTimeout limits how long each request to the device can take.
+// A `timeout` of 0 (the default) means requests never time out.
+func WithRequestTimeout(
+	timeout time.Duration,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.requestTimeout = timeout
 	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
-		ctx,
-		humanReadableShowChanges,
-		requestBody,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to %s: %w", humanReadableShowChanges, err)
+}
+
+// WithRetries retries requests that fail with a temporary problem up to `retries` times.
+// The first retry waits for `initialBackoff`,
+// and each one after that waits twice as long as the last, up to `maxBackoff`.
+//
+// Reads are retried after any temporary problem.
+// Changes (including commits and service actions) are only retried when the device could not be reached at all,
+// so they are never made twice.
+func WithRetries(
+	retries int,
+	initialBackoff time.Duration,
+	maxBackoff time.Duration,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.retryPolicy = retryPolicy{
+			initialBackoff: initialBackoff,
+			maxBackoff:     maxBackoff,
+			retries:        retries,
+		}
 	}
+}
 
-	result := [][]string{}
-	if responseBody == nil {
-		return result, nil
+// WithRollback makes [Client.CreateSection], [Client.DeleteSection], and [Client.UpdateSection] apply changes so the device can roll them back.
+// If the device cannot be reached again within `timeout` of applying the changes,
+// they are not confirmed and the device restores its previous configuration.
+// The device counts the `timeout` in whole seconds,
+// so it must be at least 1s and is rounded up.
+//
+// Applying changes applies every config at once,
+// so changes to different configs are made one at a time instead of in parallel.
+//
+// This is supported by [NewClient] and [NewUbusClient].
+// LuCI RPC cannot choose how long the device waits before rolling back
+// (LuCI waits for its `luci.apply.rollback` setting, and never less than 90 seconds),
+// so with [NewClient] the `timeout` only decides how long to keep trying to confirm the changes.
+func WithRollback(
+	timeout time.Duration,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.rollbackTimeout = timeout
 	}
+}
 
-	err = json.Unmarshal(*responseBody, &result)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse %s response: %w", humanReadableShowChanges, err)
+// WithSessionToken makes the [Client] use an existing rpcd session instead of logging in.
+// The `token` is the session ID (e.g. from `ubus call session login`).
+// There are no credentials to login again with,
+// so once the session expires every request fails with an [AuthenticationError].
+//
+// The password given to the constructor must be empty.
+// This is not supported by [NewSSHClient].
+func WithSessionToken(
+	token string,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.sessionToken = token
 	}
+}
 
-	return result, nil
+// WithTLSConfig makes the [Client] use `config` for HTTPS connections.
+// See [NewTLSConfig] for building one.
+func WithTLSConfig(
+	config *tls.Config,
+) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsConfig = config
+	}
 }
 
-func (c *Client) UpdateSection(
+// addSection creates an anonymous section,
+// and moves it to the `index` if there is one.
+func (c *Client) addSection(
 	ctx context.Context,
 	config string,
-	section string,
+	sectionType string,
 	options Options,
-) (bool, error) {
-	marshalledConfig, err := json.Marshal(config)
+	index *int,
+) (string, error) {
+	var section string
+	result, err := c.change(
+		ctx,
+		config,
+		humanReadableAddSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			var err error
+			section, err = stager.addSection(
+				ctx,
+				sectionType,
+				options,
+			)
+			if err != nil {
+				return false, err
+			}
+
+			return stager.reorderSection(ctx, section, index)
+		},
+	)
 	if err != nil {
-		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableUpdateSection, err)
+		return "", err
 	}
 
-	marshalledSection, err := json.Marshal(section)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableUpdateSection, err)
+	if !result {
+		return "", fmt.Errorf("unable to %s: the device refused to %s", humanReadableAddSection, humanReadableReorderSection)
 	}
 
-	marshalledOptions, err := json.Marshal(options)
-	if err != nil {
-		return false, fmt.Errorf("unable to serialize options %q for %s: %w", options, humanReadableCreateSection, err)
-	}
+	return section, nil
+}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodTSet,
-		Params: []json.RawMessage{
-			marshalledConfig,
-			marshalledSection,
-			marshalledOptions,
-		},
-	}
-	responseBody, err := c.jsonRPCClientUCI.Invoke(
-		ctx,
-		humanReadableUpdateSection,
-		requestBody,
-	)
-	if err != nil {
-		return false, fmt.Errorf("unable to %s: %w", humanReadableUpdateSection, err)
+// change stages a change to the `config` with `stage`, and then commits it.
+//
+// Changes to the same config are made one at a time,
+// so a commit never includes another change that is only partly staged.
+// If staging or committing fails,
+// what the [Client] staged in the `config` is undone,
+// so the next commit does not pick up what is left of the failed change.
+func (c *Client) change(
+	ctx context.Context,
+	config string,
+	humanReadableChange string,
+	stage func(context.Context, stager) (bool, error),
+) (bool, error) {
+	lock := c.locks.lock(c.lockKey(config))
+	result, err := c.stageChange(ctx, config, lock, stage)
+	if err != nil || !result {
+		lock.Unlock()
+		return result, err
 	}
 
-	// The result can be `true` to indicate success,
-	// or `null` to indicate failure.
-	var result bool
-	if responseBody == nil {
-		return false, nil
-	}
+	if c.commits == nil {
+		result, err = c.commitLocked(ctx, config, lock)
+		lock.Unlock()
+		if err != nil {
+			return false, fmt.Errorf("was able to %s, but could not %s: %w", humanReadableChange, humanReadableCommitChanges, err)
+		}
 
-	err = json.Unmarshal(*responseBody, &result)
-	if err != nil {
-		return false, fmt.Errorf("unable to parse %s response: %w", humanReadableUpdateSection, err)
+		return result, nil
 	}
 
-	if !result {
-		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableUpdateSection)
+	// Let other changes join the batch while this one waits for it to be committed.
+	event := lock.wait()
+	lock.Unlock()
+	result, err = c.commits.commitChanges(ctx, config)
+	lock.Lock()
+	reverted := lock.revertedAfter(event)
+	lock.Unlock()
+	if err != nil {
+		return false, fmt.Errorf("was able to %s, but could not %s: %w", humanReadableChange, humanReadableCommitChanges, err)
 	}
 
-	result, err = c.CommitChanges(
-		ctx,
-		config,
-	)
-	if err != nil {
-		return false, fmt.Errorf("was able to %s, but could not %s: %w", humanReadableUpdateSection, humanReadableCommitChanges, err)
+	if reverted {
+		return false, fmt.Errorf("was able to %s, but it was reverted because another change to %q failed before it could be committed", humanReadableChange, config)
 	}
 
 	return result, nil
 }
 
-func NewClient(
+// commitBatch commits a batch of changes to the `config`.
+// If the changes were already committed or reverted, there is nothing to do.
+func (c *Client) commitBatch(
 	ctx context.Context,
-	scheme string,
-	hostname string,
-	port uint16,
-	username string,
-	password string,
-) (*Client, error) {
-	host := hostname
-	if port != 0 {
-		host = fmt.Sprintf("%s:%d", host, port)
+	config string,
+) (bool, error) {
+	lock := c.locks.lock(c.lockKey(config))
+	defer lock.Unlock()
+	if !lock.hasStaged() {
+		return true, nil
 	}
 
-	address := url.URL{
-		Host:   host,
-		Path:   pathAuth,
-		Scheme: scheme,
-	}
-	httpClient := &http.Client{}
-	marshalledUsername, err := json.Marshal(username)
-	if err != nil {
-		return nil, fmt.Errorf("unable to serialize username for %s: %w", humanReadableLogin, err)
-	}
+	return c.commitLocked(ctx, config, lock)
+}
 
-	marshalledPassword, err := json.Marshal(password)
-	if err != nil {
-		return nil, fmt.Errorf("unable to serialize password for %s: %w", humanReadableLogin, err)
+// commitLocked commits the changes staged in the `config`,
+// and reverts them if they could not be committed.
+// The `lock` must be held.
+func (c *Client) commitLocked(
+	ctx context.Context,
+	config string,
+	lock *configLock,
+) (bool, error) {
+	commit := c.transport.commitChanges
+	if c.rollback != nil {
+		commit = c.rollback.commitChanges
 	}
 
-	requestBody := jsonRPCRequestBody{
-		Method: methodLogin,
-		Params: []json.RawMessage{
-			marshalledUsername,
-			marshalledPassword,
-		},
+	result, err := commit(ctx, config)
+	if err != nil || !result {
+		return false, c.revertLocked(ctx, config, lock, err)
 	}
-	jsonRPCClient := jsonRPCNewClient(
-		*httpClient,
-		address,
-	)
-	responseBody, err := jsonRPCClient.InvokeNotNull(
+
+	lock.committed()
+	return true, nil
+}
+
+// createSection creates a section,
+// and moves it to the `index` if there is one.
+func (c *Client) createSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	section string,
+	options Options,
+	index *int,
+) (bool, error) {
+	return c.change(
 		ctx,
-		humanReadableLogin,
-		requestBody,
+		config,
+		humanReadableCreateSection,
+		func(ctx context.Context, stager stager) (bool, error) {
+			result, err := stager.createSection(
+				ctx,
+				sectionType,
+				section,
+				options,
+			)
+			if err != nil || !result {
+				return result, err
+			}
+
+			return stager.reorderSection(ctx, section, index)
+		},
 	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to %s: %w", humanReadableLogin, err)
-	}
+}
 
-	var authToken string
-	err = json.Unmarshal(responseBody, &authToken)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse %s response: %w", humanReadableLogin, err)
+// lockKey is the lock that needs to be held to change the `config`.
+// Applying changes with a rollback applies every config at once,
+// so then every config shares the same lock.
+// Otherwise, another config's half-staged change could be applied along with this one,
+// and could not be reverted if it failed.
+// The cost is that changes to different configs are made one at a time,
+// each waiting for the previous apply to be confirmed.
+func (c *Client) lockKey(
+	config string,
+) string {
+	if c.rollback != nil {
+		return ""
 	}
 
-	query := url.Values{}
-	query.Add(queryKeyAuth, authToken)
-	addressUCI := url.URL{
-		Host:     host,
-		Path:     pathUCI,
-		RawQuery: query.Encode(),
-		Scheme:   scheme,
-	}
-	jsonRPCClientUCI := jsonRPCNewClient(
-		*httpClient,
-		addressUCI,
-	)
-	client := &Client{
-		jsonRPCClientUCI: jsonRPCClientUCI,
+	return config
+}
+
+// revertBatch reverts a batch of changes to the `config` that will not be committed.
+// If the changes were already committed or reverted, there is nothing to do.
+func (c *Client) revertBatch(
+	ctx context.Context,
+	config string,
+) error {
+	lock := c.locks.lock(c.lockKey(config))
+	defer lock.Unlock()
+	if !lock.hasStaged() {
+		return nil
 	}
-	return client, nil
+
+	return c.revertLocked(ctx, config, lock, nil)
 }
 
-type jsonRPCClient struct {
-	address url.URL
-	client  http.Client
+// revertLocked reverts the changes staged in the `config` because of the `cause`.
+// Every other config staged under the same `lock` is reverted too,
+// as the changes waiting on the `lock` are marked as reverted together.
+// Any problem reverting is returned along with the `cause`.
+// The `lock` must be held.
+//
+// Only a config that was checked to be clean before the [Client] staged in it is reverted as a whole.
+// Otherwise, someone else's changes could be staged in it too,
+// so only the operations the [Client] staged are undone.
+func (c *Client) revertLocked(
+	ctx context.Context,
+	config string,
+	lock *configLock,
+	cause error,
+) error {
+	configs := []string{config}
+	for staged := range lock.staged {
+		if staged != config {
+			configs = append(configs, staged)
+		}
+	}
+
+	sort.Strings(configs[1:])
+	errs := []error{cause}
+	for _, config := range configs {
+		var err error
+		if lock.clean[config] {
+			_, err = c.transport.revertChanges(ctx, config)
+		} else {
+			err = lock.undoStaged(ctx, config)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to %s to %q: %w", humanReadableRevertChanges, config, err))
+		}
+	}
+
+	lock.reverted()
+	return errors.Join(errs...)
 }
 
-func (c jsonRPCClient) InvokeNotNull(
+// stageChange stages a change to the `config` with `stage`,
+// after checking for changes that were already staged.
+// The `lock` must be held.
+func (c *Client) stageChange(
 	ctx context.Context,
-	humanReadableMethod string,
-	requestBody jsonRPCRequestBody,
-) (json.RawMessage, error) {
-	result, err := c.Invoke(
-		ctx,
-		humanReadableMethod,
-		requestBody,
-	)
-	if err != nil {
-		return json.RawMessage{}, err
+	config string,
+	lock *configLock,
+	stage func(context.Context, stager) (bool, error),
+) (bool, error) {
+	if !lock.staged[config] {
+		clean, err := c.checkPendingChanges(ctx, config)
+		if err != nil {
+			return false, err
+		}
+
+		lock.clean[config] = clean
 	}
 
-	if result == nil {
-		return nil, fmt.Errorf("invalid %s response: expected either an error or a result, got neither", humanReadableMethod)
+	result, err := stage(ctx, stager{
+		config:    config,
+		lock:      lock,
+		transport: c.transport,
+	})
+	if err != nil || !result {
+		return result, c.revertLocked(ctx, config, lock, err)
 	}
 
-	return *result, nil
+	lock.staged[config] = true
+	return true, nil
 }
 
-func (c jsonRPCClient) Invoke(
-	ctx context.Context,
-	humanReadableMethod string,
-	requestBody jsonRPCRequestBody,
-) (*json.RawMessage, error) {
-	buffer := bytes.Buffer{}
-	encoder := json.NewEncoder(&buffer)
-	err := encoder.Encode(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("problem encoding %s request: %w", humanReadableMethod, err)
-	}
+type clientOptions struct {
+	commitBatchWindow time.Duration
+	passwordCommand   []string
+	passwordFile      string
+	pendingChanges    PendingChangesPolicy
+	requestTimeout    time.Duration
+	retryPolicy       retryPolicy
+	rollbackTimeout   time.Duration
+	sessionToken      string
+	tlsConfig         *tls.Config
+}
 
-	request, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		c.address.String(),
-		&buffer,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("problem creating %s request: %w", humanReadableMethod, err)
+// credentials combines the `password` given to a constructor with the other ways to authenticate.
+// Only one of them can be used.
+func (o clientOptions) credentials(
+	password string,
+) (credentials, error) {
+	result := credentials{
+		password:        password,
+		passwordCommand: o.passwordCommand,
+		passwordFile:    o.passwordFile,
+		sessionToken:    o.sessionToken,
+	}
+	return result, result.validate()
+}
+
+// httpClient constructs the [http.Client] every request is sent with.
+func (o clientOptions) httpClient() *http.Client {
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.tlsConfig != nil {
+		httpTransport.TLSClientConfig = o.tlsConfig
 	}
 
-	response, err := c.client.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("problem sending request to %s: %w", humanReadableMethod, err)
+	return &http.Client{
+		Timeout:   o.requestTimeout,
+		Transport: httpTransport,
 	}
+}
 
-	defer response.Body.Close()
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("expected %s to respond with a 200: got %s", humanReadableMethod, response.Status)
+func newClient(
+	transport transport,
+	clientOptions clientOptions,
+) (*Client, error) {
+	client := &Client{
+		locks:          newConfigLocks(),
+		pendingChanges: clientOptions.pendingChanges,
+		transport:      transport,
 	}
+	if clientOptions.rollbackTimeout > 0 {
+		if clientOptions.rollbackTimeout < time.Second {
+			return nil, fmt.Errorf("invalid %s timeout: the device counts it in whole seconds, so it must be at least 1s: got %s", humanReadableRollback, clientOptions.rollbackTimeout)
+		}
 
-	var responseBody jsonRPCResponseBody
-	decoder := json.NewDecoder(response.Body)
-	err = decoder.Decode(&responseBody)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse %s response: %w", humanReadableMethod, err)
+		rollbackTransport, ok := transport.(rollbackTransport)
+		if !ok {
+			return nil, NewNotSupportedError(humanReadableRollback, humanReadableTransportLuCIRPC, humanReadableTransportUbus)
+		}
+
+		client.rollback = newRollback(
+			rollbackTransport,
+			clientOptions.rollbackTimeout,
+		)
 	}
 
-	if responseBody.Error != nil {
-		return nil, fmt.Errorf("%s error: %s", humanReadableMethod, *responseBody.Error)
+	if clientOptions.commitBatchWindow > 0 {
+		client.commits = newCommitBatcher(
+			client.commitBatch,
+			client.revertBatch,
+			clientOptions.commitBatchWindow,
+		)
 	}
 
-	return responseBody.Result, nil
+	return client, nil
+}
+
+// transport is the protocol used to talk to UCI on the device.
+//
+// Each method should only stage the change on the device.
+// Committing changes is left up to the [Client].
+type transport interface {
+	addSection(ctx context.Context, config string, sectionType string, options Options) (string, error)
+	capabilities(ctx context.Context) (Capabilities, error)
+	commitChanges(ctx context.Context, config string) (bool, error)
+	createSection(ctx context.Context, config string, sectionType string, section string, options Options) (bool, error)
+	deleteOptions(ctx context.Context, config string, section string, options []string) (bool, error)
+	deleteSection(ctx context.Context, config string, section string) (bool, error)
+	getSection(ctx context.Context, config string, section string) (Options, error)
+	listSections(ctx context.Context, config string, sectionType string) ([]Options, error)
+	reorderSection(ctx context.Context, config string, section string, index int) (bool, error)
+	revertChanges(ctx context.Context, config string) (bool, error)
+	serviceAction(ctx context.Context, service string, action ServiceAction) (bool, error)
+	showChanges(ctx context.Context, config string) ([][]string, error)
+	updateSection(ctx context.Context, config string, section string, options Options) (bool, error)
 }
 
-func jsonRPCNewClient(
-	httpClient http.Client,
-	address url.URL,
-) jsonRPCClient {
-	return jsonRPCClient{
-		address: address,
-		client:  httpClient,
+// orderSections puts the `sections` of a config in the order they appear,
+// keeping only the ones of `sectionType` (or all of them, if it is empty).
+func orderSections(
+	sections map[string]Options,
+	sectionType string,
+) ([]Options, error) {
+	type indexedSection struct {
+		index   int
+		options Options
+	}
+	indexedSections := []indexedSection{}
+	for name, options := range sections {
+		if sectionType != "" {
+			actualType, err := options.GetString(".type")
+			if err != nil {
+				return nil, NewProtocolError(fmt.Errorf("unable to find the type of section %q: %w", name, err))
+			}
+
+			if actualType != sectionType {
+				continue
+			}
+		}
+
+		index, err := options.GetInteger(".index")
+		if err != nil {
+			return nil, NewProtocolError(fmt.Errorf("unable to find the index of section %q: %w", name, err))
+		}
+
+		indexedSections = append(indexedSections, indexedSection{
+			index:   index,
+			options: options,
+		})
+	}
+
+	sort.Slice(indexedSections, func(i, j int) bool {
+		return indexedSections[i].index < indexedSections[j].index
+	})
+	result := []Options{}
+	for _, section := range indexedSections {
+		result = append(result, section.options)
 	}
+
+	return result, nil
 }
 
-type jsonRPCRequestBody struct {
-	Method string            `json:"method"`
-	Params []json.RawMessage `json:"params"`
+func newClientOptions(
+	options []ClientOption,
+) clientOptions {
+	clientOptions := clientOptions{}
+	for _, option := range options {
+		option(&clientOptions)
+	}
+
+	return clientOptions
 }
 
-type jsonRPCResponseBody struct {
-	Error  *string          `json:"error"`
-	Result *json.RawMessage `json:"result"`
+func joinHostPort(
+	hostname string,
+	port uint16,
+) string {
+	if port == 0 {
+		return hostname
+	}
+
+	return fmt.Sprintf("%s:%d", hostname, port)
 }
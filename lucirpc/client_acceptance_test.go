@@ -374,6 +374,43 @@ func TestClientGetSectionAcceptance(t *testing.T) {
 	})
 }
 
+func TestClientListSectionsAcceptance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns sections of the given type", func(t *testing.T) {
+		t.Parallel()
+
+		// Given
+		ctx := context.Background()
+		openWrtServer := acceptancetest.RunOpenWrtServer(
+			ctx,
+			*dockerPool,
+			t,
+		)
+		client := openWrtServer.LuCIRPCClient(
+			ctx,
+			t,
+		)
+
+		// When
+		got, err := client.ListSections(
+			ctx,
+			"system",
+			"system",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, len(got), 1)
+		name, err := got[0].GetString(".name")
+		assert.NilError(t, err)
+		assert.Equal(t, name, "cfg01e48a")
+		index, err := got[0].GetInteger(".index")
+		assert.NilError(t, err)
+		assert.Equal(t, index, 0)
+	})
+}
+
 func TestMain(m *testing.M) {
 	var (
 		code     int
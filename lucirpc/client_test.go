@@ -1,58 +1,88 @@
 package lucirpc_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 	"gotest.tools/v3/assert"
 )
 
-func TestClientCreateSection(t *testing.T) {
-	t.Run("handles server not existing", func(t *testing.T) {
+func TestClientAddSection(t *testing.T) {
+	t.Run("adds the section, sets its options and commits changes", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var requests []string
 		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			if body.Method == "add" {
+				fmt.Fprintf(w, `{
+					"result": "cfg0a1b2c"
+				}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
-		close()
+		defer close()
 
 		// When
-		_, err := client.CreateSection(
+		got, err := client.AddSection(
 			ctx,
-			"",
-			"",
-			"",
-			lucirpc.Options{},
+			"firewall",
+			"rule",
+			lucirpc.Options{
+				"name": lucirpc.String("testing"),
+			},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "problem sending request to create section")
+		assert.NilError(t, err)
+		assert.Equal(t, got, "cfg0a1b2c")
+		assert.DeepEqual(t, requests, []string{
+			`add "firewall" "rule"`,
+			`tset "firewall" "cfg0a1b2c" {"name":"testing"}`,
+			`commit "firewall"`,
+		})
 	})
 
-	t.Run("makes a request to correct endpoint", func(t *testing.T) {
+	t.Run("returns error when the section cannot be added", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			switch r.URL.Path {
-			case "/cgi-bin/luci/rpc/uci":
-				fmt.Fprintf(w, `{
-					"result": true
-				}`)
-
-			default:
-				w.WriteHeader(http.StatusNotFound)
-			}
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
 		}
 		client, close := authenticatedClient(
 			t,
@@ -62,130 +92,247 @@ func TestClientCreateSection(t *testing.T) {
 		defer close()
 
 		// When
-		_, err := client.CreateSection(
+		_, err := client.AddSection(
 			ctx,
-			"",
-			"",
-			"",
+			"firewall",
+			"rule",
 			lucirpc.Options{},
 		)
 
 		// Then
-		assert.NilError(t, err)
+		assert.ErrorContains(t, err, "unable to add section: it is not clear why this happened")
 	})
 
-	t.Run("expects a 200 response", func(t *testing.T) {
+	t.Run("does not retry adding a section", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var adds int
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusAccepted)
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "add" {
+				adds++
+			}
+
+			w.WriteHeader(http.StatusGatewayTimeout)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
 		)
 		defer close()
 
 		// When
-		_, err := client.CreateSection(
+		_, err := client.AddSection(
 			ctx,
-			"",
-			"",
-			"",
+			"firewall",
+			"rule",
 			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "expected create section to respond with a 200")
+		assert.ErrorContains(t, err, "expected add section to respond with a 200: got 504 Gateway Timeout")
+		assert.Equal(t, adds, 1)
 	})
+}
 
-	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
+func TestClientAddSectionAt(t *testing.T) {
+	t.Run("adds the section and moves it, then commits once", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var requests []string
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, `[]`)
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			if body.Method == "add" {
+				fmt.Fprintf(w, `{
+					"result": "cfg0a1b2c"
+				}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.CreateSection(
+		got, err := client.AddSectionAt(
 			ctx,
-			"",
-			"",
-			"",
-			lucirpc.Options{},
+			"firewall",
+			"rule",
+			lucirpc.Options{
+				"name": lucirpc.String("testing"),
+			},
+			1,
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse create section response")
+		assert.NilError(t, err)
+		assert.Equal(t, got, "cfg0a1b2c")
+		assert.DeepEqual(t, requests, []string{
+			`add "firewall" "rule"`,
+			`tset "firewall" "cfg0a1b2c" {"name":"testing"}`,
+			`reorder "firewall" "cfg0a1b2c" 1`,
+			`commit "firewall"`,
+		})
 	})
 
-	t.Run("returns error when get section fails", func(t *testing.T) {
+	t.Run("reverts the added section when it cannot be moved", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var requests []string
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, `{
-				"error": ""
-			}`)
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			switch body.Method {
+			case "add":
+				fmt.Fprintf(w, `{
+					"result": "cfg0a1b2c"
+				}`)
+
+			case "reorder":
+				fmt.Fprintf(w, `{
+					"result": null
+				}`)
+
+			default:
+				fmt.Fprintf(w, `{
+					"result": true
+				}`)
+			}
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.CreateSection(
+		_, err := client.AddSectionAt(
 			ctx,
-			"",
-			"",
-			"",
+			"firewall",
+			"rule",
 			lucirpc.Options{},
+			1,
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to create section")
+		assert.ErrorContains(t, err, "unable to add section: the device refused to reorder section")
+		assert.DeepEqual(t, requests, []string{"add", "reorder", "revert"})
 	})
+}
 
-	t.Run("does not handle unknown stuff in result", func(t *testing.T) {
+func TestClientCommitBatchWindow(t *testing.T) {
+	t.Run("commits concurrent changes to a config once", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var mutex sync.Mutex
+		commits := map[string]int{}
 		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "commit" {
+				var config string
+				err = json.Unmarshal(body.Params[0], &config)
+				assert.NilError(t, err)
+				mutex.Lock()
+				commits[config]++
+				mutex.Unlock()
+			}
+
 			fmt.Fprintf(w, `{
-				"result": 31
+				"result": true
 			}`)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(100*time.Millisecond),
 		)
 		defer close()
 
 		// When
-		_, err := client.CreateSection(
-			ctx,
-			"",
-			"",
-			"",
-			lucirpc.Options{},
-		)
+		var wg sync.WaitGroup
+		results := make([]bool, 5)
+		errs := make([]error, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = client.CreateSection(
+					ctx,
+					"firewall",
+					"rule",
+					fmt.Sprintf("rule%d", i),
+					lucirpc.Options{},
+				)
+			}(i)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.DeleteSection(ctx, "network", "lan")
+		}()
+		wg.Wait()
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse create section response")
+		for i := range results {
+			assert.NilError(t, errs[i])
+			assert.Check(t, results[i])
+		}
+		assert.DeepEqual(t, commits, map[string]int{
+			"firewall": 1,
+			"network":  1,
+		})
 	})
 
-	t.Run("returns section data when successful", func(t *testing.T) {
+	t.Run("reports commit errors to every change", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "commit" {
+				fmt.Fprintf(w, `{
+					"error": "Something went wrong"
+				}`)
+				return
+			}
+
 			fmt.Fprintf(w, `{
 				"result": true
 			}`)
@@ -193,73 +340,304 @@ func TestClientCreateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(100*time.Millisecond),
 		)
 		defer close()
 
 		// When
-		got, err := client.CreateSection(
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = client.UpdateSection(
+					ctx,
+					"firewall",
+					fmt.Sprintf("rule%d", i),
+					lucirpc.Options{},
+				)
+			}(i)
+		}
+		wg.Wait()
+
+		// Then
+		for _, err := range errs {
+			assert.ErrorContains(t, err, "was able to update section, but could not commit changes")
+		}
+	})
+
+	t.Run("commits again for changes after a batch", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commits int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "commit" {
+				commits++
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
 			ctx,
-			"",
-			"",
-			"",
-			lucirpc.Options{},
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(time.Millisecond),
 		)
+		defer close()
 
-		// Then
+		// When
+		_, err := client.DeleteSection(ctx, "firewall", "rule1")
 		assert.NilError(t, err)
-		want := true
-		assert.DeepEqual(t, got, want)
+		_, err = client.DeleteSection(ctx, "firewall", "rule2")
+		assert.NilError(t, err)
+
+		// Then
+		assert.Equal(t, commits, 2)
 	})
 
-	t.Run("commits changes", func(t *testing.T) {
+	t.Run("fails every change in a batch that was reverted", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
-		var committed bool
+		var mutex sync.Mutex
+		var requests []string
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			switch r.URL.Path {
-			case "/cgi-bin/luci/rpc/uci":
-				decoder := json.NewDecoder(r.Body)
-				var body map[string]json.RawMessage
-				err := decoder.Decode(&body)
-				assert.NilError(t, err)
-				method, ok := body["method"]
-				assert.Check(t, ok)
-				switch string(method) {
-				case `"commit"`:
-					committed = true
-				}
-
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			mutex.Lock()
+			requests = append(requests, body.Method)
+			mutex.Unlock()
+			if body.Method == "tset" && string(body.Params[1]) == `"missing"` {
 				fmt.Fprintf(w, `{
-					"result": true
+					"result": null
 				}`)
+				return
+			}
 
-			default:
-				w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(100*time.Millisecond),
+		)
+		defer close()
+
+		// When
+		var wg sync.WaitGroup
+		var errExisting error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errExisting = client.UpdateSection(ctx, "firewall", "existing", lucirpc.Options{})
+		}()
+		time.Sleep(20 * time.Millisecond)
+		got, errMissing := client.UpdateSection(ctx, "firewall", "missing", lucirpc.Options{})
+		wg.Wait()
+
+		// Then
+		assert.NilError(t, errMissing)
+		assert.Check(t, !got)
+		assert.ErrorContains(t, errExisting, "was able to update section, but it was reverted")
+		assert.DeepEqual(t, requests, []string{"tset", "tset", "revert"})
+	})
+
+	t.Run("reverts the batch when the change committing it is cancelled", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var mutex sync.Mutex
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
 			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			mutex.Lock()
+			requests = append(requests, body.Method)
+			mutex.Unlock()
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(time.Second),
 		)
 		defer close()
+		cancelled, cancel := context.WithCancel(ctx)
 
 		// When
-		client.CreateSection(
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err := client.UpdateSection(cancelled, "firewall", "rule1", lucirpc.Options{})
+
+		// Then
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.DeepEqual(t, requests, []string{"tset", "revert"})
+		_, err = client.UpdateSection(ctx, "firewall", "rule2", lucirpc.Options{})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, requests, []string{"tset", "revert", "tset", "commit"})
+	})
+
+	t.Run("hands the batch to a waiting change when the change committing it is cancelled", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var mutex sync.Mutex
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			mutex.Lock()
+			requests = append(requests, body.Method)
+			mutex.Unlock()
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
 			ctx,
-			"",
-			"",
-			"",
-			lucirpc.Options{},
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithCommitBatchWindow(200*time.Millisecond),
 		)
+		defer close()
+		cancelled, cancel := context.WithCancel(ctx)
+
+		// When
+		var wg sync.WaitGroup
+		var errCancelled error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errCancelled = client.UpdateSection(cancelled, "firewall", "rule1", lucirpc.Options{})
+		}()
+		time.Sleep(20 * time.Millisecond)
+		time.AfterFunc(20*time.Millisecond, cancel)
+		got, err := client.UpdateSection(ctx, "firewall", "rule2", lucirpc.Options{})
+		wg.Wait()
 
 		// Then
-		assert.Check(t, committed)
+		assert.ErrorIs(t, errCancelled, context.Canceled)
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{"tset", "tset", "commit"})
 	})
 }
 
-func TestClientDeleteSection(t *testing.T) {
+func TestClientConcurrentChanges(t *testing.T) {
+	t.Run("commits every change", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		var wg sync.WaitGroup
+		sections := make([]string, 10)
+		errs := make([]error, 10)
+		for i := range sections {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sections[i], errs[i] = client.AddSection(
+					ctx,
+					"firewall",
+					"rule",
+					lucirpc.Options{
+						"name": lucirpc.String(fmt.Sprintf("rule%d", i)),
+					},
+				)
+			}(i)
+		}
+		wg.Wait()
+
+		// Then
+		for i, section := range sections {
+			assert.NilError(t, errs[i])
+			got, ok := server.CommittedSection("firewall", section)
+			assert.Assert(t, ok)
+			assert.DeepEqual(t, got["name"], lucirpc.String(fmt.Sprintf("rule%d", i)))
+		}
+		changes, err := client.ShowChanges(ctx, "firewall")
+		assert.NilError(t, err)
+		assert.Check(t, len(changes) == 0)
+	})
+
+	t.Run("reverts a failed change without losing the others", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		var wg sync.WaitGroup
+		results := make([]bool, 10)
+		errs := make([]error, 10)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = client.CreateSection(
+					ctx,
+					"firewall",
+					"rule",
+					fmt.Sprintf("rule%d", i),
+					lucirpc.Options{},
+				)
+			}(i)
+		}
+		var missing bool
+		var errMissing error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			missing, errMissing = client.UpdateSection(
+				ctx,
+				"firewall",
+				"missing",
+				lucirpc.Options{
+					"name": lucirpc.String("missing"),
+				},
+			)
+		}()
+		wg.Wait()
+
+		// Then
+		assert.NilError(t, errMissing)
+		assert.Check(t, !missing)
+		for i := range results {
+			assert.NilError(t, errs[i])
+			assert.Check(t, results[i])
+			_, ok := server.CommittedSection("firewall", fmt.Sprintf("rule%d", i))
+			assert.Check(t, ok)
+		}
+		changes, err := client.ShowChanges(ctx, "firewall")
+		assert.NilError(t, err)
+		assert.Check(t, len(changes) == 0)
+	})
+}
+
+func TestClientCreateSection(t *testing.T) {
 	t.Run("handles server not existing", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
@@ -273,14 +651,16 @@ func TestClientDeleteSection(t *testing.T) {
 		close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "problem sending request to delete section")
+		assert.ErrorContains(t, err, "problem sending request to show changes")
 	})
 
 	t.Run("makes a request to correct endpoint", func(t *testing.T) {
@@ -300,15 +680,17 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
@@ -324,19 +706,21 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "expected delete section to respond with a 200")
+		assert.ErrorContains(t, err, "expected create section to respond with a 200")
 	})
 
 	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
@@ -348,19 +732,21 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse delete section response")
+		assert.ErrorContains(t, err, "unable to parse create section response")
 	})
 
 	t.Run("returns error when get section fails", func(t *testing.T) {
@@ -374,19 +760,21 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to delete section")
+		assert.ErrorContains(t, err, "unable to create section")
 	})
 
 	t.Run("does not handle unknown stuff in result", func(t *testing.T) {
@@ -400,22 +788,24 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.DeleteSection(
+		_, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse delete section response")
+		assert.ErrorContains(t, err, "unable to parse create section response")
 	})
 
-	t.Run("returns true when successful", func(t *testing.T) {
+	t.Run("returns section data when successful", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
@@ -426,15 +816,17 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		got, err := client.DeleteSection(
+		got, err := client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
@@ -472,15 +864,17 @@ func TestClientDeleteSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		client.DeleteSection(
+		client.CreateSection(
 			ctx,
 			"",
 			"",
+			"",
+			lucirpc.Options{},
 		)
 
 		// Then
@@ -488,7 +882,353 @@ func TestClientDeleteSection(t *testing.T) {
 	})
 }
 
-func TestClientGetSection(t *testing.T) {
+func TestClientCreateSectionAt(t *testing.T) {
+	t.Run("creates the section and moves it, then commits once", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.CreateSectionAt(
+			ctx,
+			"firewall",
+			"zone",
+			"testing",
+			lucirpc.Options{},
+			0,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests[len(requests)-2:], []string{
+			`reorder "firewall" "testing" 0`,
+			`commit "firewall"`,
+		})
+	})
+}
+
+func TestClientChangeSection(t *testing.T) {
+	t.Run("sets and deletes options, then commits once", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.ChangeSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.SectionChanges{
+				DeleteOptions: []string{"dns"},
+				Options: lucirpc.Options{
+					"proto": lucirpc.String("dhcp"),
+				},
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`tset "network" "testing" {"proto":"dhcp"}`,
+			`delete "network" "testing" "dns"`,
+			`commit "network"`,
+		})
+	})
+
+	t.Run("only deletes options when there are none to set", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.ChangeSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.SectionChanges{
+				DeleteOptions: []string{"dns"},
+				Options:       lucirpc.Options{},
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{"delete", "commit"})
+	})
+
+	t.Run("moves the section before committing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+		index := 2
+
+		// When
+		got, err := client.ChangeSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.SectionChanges{
+				Index: &index,
+				Options: lucirpc.Options{
+					"proto": lucirpc.String("dhcp"),
+				},
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`tset "network" "testing" {"proto":"dhcp"}`,
+			`reorder "network" "testing" 2`,
+			`commit "network"`,
+		})
+	})
+
+	t.Run("reverts the options it set when an option cannot be deleted", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			if body.Method == "delete" {
+				fmt.Fprintf(w, `{
+					"result": null
+				}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.ChangeSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.SectionChanges{
+				DeleteOptions: []string{"dns"},
+				Options: lucirpc.Options{
+					"proto": lucirpc.String("dhcp"),
+				},
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, !got)
+		assert.DeepEqual(t, requests, []string{"tset", "delete", "revert"})
+	})
+}
+
+func TestClientDeleteOptions(t *testing.T) {
+	t.Run("deletes each option and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.DeleteOptions(
+			ctx,
+			"network",
+			"testing",
+			[]string{"dns", "ipaddr"},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`delete "network" "testing" "dns"`,
+			`delete "network" "testing" "ipaddr"`,
+			`commit "network"`,
+		})
+	})
+
+	t.Run("stops and reverts when an option cannot be deleted", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.DeleteOptions(
+			ctx,
+			"network",
+			"testing",
+			[]string{"dns", "ipaddr"},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, !got)
+		assert.DeepEqual(t, requests, []string{"delete", "revert"})
+	})
+
+	t.Run("expects a 200 response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteOptions(
+			ctx,
+			"network",
+			"testing",
+			[]string{"dns"},
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected delete options to respond with a 200")
+	})
+}
+
+func TestClientDeleteSection(t *testing.T) {
 	t.Run("handles server not existing", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
@@ -502,14 +1242,14 @@ func TestClientGetSection(t *testing.T) {
 		close()
 
 		// When
-		_, err := client.GetSection(
+		_, err := client.DeleteSection(
 			ctx,
 			"",
 			"",
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "problem sending request to get section")
+		assert.ErrorContains(t, err, "problem sending request to show changes")
 	})
 
 	t.Run("makes a request to correct endpoint", func(t *testing.T) {
@@ -519,7 +1259,7 @@ func TestClientGetSection(t *testing.T) {
 			switch r.URL.Path {
 			case "/cgi-bin/luci/rpc/uci":
 				fmt.Fprintf(w, `{
-					"result": {}
+					"result": true
 				}`)
 
 			default:
@@ -529,12 +1269,12 @@ func TestClientGetSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.GetSection(
+		_, err := client.DeleteSection(
 			ctx,
 			"",
 			"",
@@ -553,19 +1293,19 @@ func TestClientGetSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
 		// When
-		_, err := client.GetSection(
+		_, err := client.DeleteSection(
 			ctx,
 			"",
 			"",
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "expected get section to respond with a 200")
+		assert.ErrorContains(t, err, "expected delete section to respond with a 200")
 	})
 
 	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
@@ -574,6 +1314,1085 @@ func TestClientGetSection(t *testing.T) {
 		handle := func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprintf(w, `[]`)
 		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse delete section response")
+	})
+
+	t.Run("returns error when get section fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"error": ""
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to delete section")
+	})
+
+	t.Run("does not handle unknown stuff in result", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": 31
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse delete section response")
+	})
+
+	t.Run("returns true when successful", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.DeleteSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		want := true
+		assert.DeepEqual(t, got, want)
+	})
+
+	t.Run("commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var committed bool
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/uci":
+				decoder := json.NewDecoder(r.Body)
+				var body map[string]json.RawMessage
+				err := decoder.Decode(&body)
+				assert.NilError(t, err)
+				method, ok := body["method"]
+				assert.Check(t, ok)
+				switch string(method) {
+				case `"commit"`:
+					committed = true
+				}
+
+				fmt.Fprintf(w, `{
+					"result": true
+				}`)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		client.DeleteSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.Check(t, committed)
+	})
+}
+
+func TestClientGetSection(t *testing.T) {
+	t.Run("handles server not existing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "problem sending request to get section")
+	})
+
+	t.Run("makes a request to correct endpoint", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/uci":
+				fmt.Fprintf(w, `{
+					"result": {}
+				}`)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("expects a 200 response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected get section to respond with a 200")
+	})
+
+	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `[]`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse get section response")
+	})
+
+	t.Run("returns error when get section fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"error": ""
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to get section")
+	})
+
+	t.Run("handles errors in result", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": [
+					false,
+					"Invalid argument"
+				]
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, `incorrect config ("") and/or section (""): result from LuCI`)
+	})
+
+	t.Run("does not handle unknown stuff in result", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": 31
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse get section response")
+	})
+
+	t.Run("handles invalid response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "{}")
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "could not find section")
+	})
+
+	t.Run("returns section data when successful", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": {
+					".name": "section-name",
+					"baz": "1",
+					"foo": "bar"
+				}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		want := lucirpc.Options{
+			".name": lucirpc.String("section-name"),
+			"baz":   lucirpc.Boolean(true),
+			"foo":   lucirpc.String("bar"),
+		}
+		assert.DeepEqual(t, got, want)
+	})
+}
+
+func TestClientListSections(t *testing.T) {
+	t.Run("returns sections in order", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": {
+					"cfg02e48a": {
+						".anonymous": true,
+						".index": 2,
+						".name": "cfg02e48a",
+						".type": "host",
+						"name": "second"
+					},
+					"lan": {
+						".anonymous": false,
+						".index": 0,
+						".name": "lan",
+						".type": "dhcp"
+					},
+					"first": {
+						".anonymous": false,
+						".index": 1,
+						".name": "first",
+						".type": "host"
+					}
+				}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.ListSections(
+			ctx,
+			"dhcp",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []lucirpc.Options{
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(0),
+				".name":      lucirpc.String("lan"),
+				".type":      lucirpc.String("dhcp"),
+			},
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(1),
+				".name":      lucirpc.String("first"),
+				".type":      lucirpc.String("host"),
+			},
+			{
+				".anonymous": lucirpc.Boolean(true),
+				".index":     lucirpc.Integer(2),
+				".name":      lucirpc.String("cfg02e48a"),
+				".type":      lucirpc.String("host"),
+				"name":       lucirpc.String("second"),
+			},
+		})
+	})
+
+	t.Run("only returns sections of the given type", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": {
+					"lan": {
+						".anonymous": false,
+						".index": 0,
+						".name": "lan",
+						".type": "dhcp"
+					},
+					"first": {
+						".anonymous": false,
+						".index": 1,
+						".name": "first",
+						".type": "host"
+					}
+				}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.ListSections(
+			ctx,
+			"dhcp",
+			"host",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []lucirpc.Options{
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(1),
+				".name":      lucirpc.String("first"),
+				".type":      lucirpc.String("host"),
+			},
+		})
+	})
+
+	t.Run("returns error when the config does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.ListSections(
+			ctx,
+			"missing",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, `could not find config "missing"`)
+	})
+}
+
+func TestClientPendingChanges(t *testing.T) {
+	t.Run("only undoes its own changes when a change fails after warning about pending changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "lan", map[string]any{
+			"proto": "static",
+		})
+		server.SetSection("network", "interface", "wan", map[string]any{
+			"proto": "static",
+		})
+		server.StageOptions("network", "lan", map[string]any{
+			"proto": "dhcp",
+		})
+		client := server.LuCIRPCClient(ctx, t)
+
+		// When
+		_, err := client.ChangeSection(ctx, "network", "wan", lucirpc.SectionChanges{
+			DeleteOptions: []string{"missing"},
+			Options: lucirpc.Options{
+				"proto":  lucirpc.String("dhcp"),
+				"ifname": lucirpc.String("eth1"),
+			},
+		})
+
+		// Then
+		assert.NilError(t, err)
+		lan, err := client.GetSection(ctx, "network", "lan")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, lan["proto"], lucirpc.String("dhcp"))
+		wan, err := client.GetSection(ctx, "network", "wan")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, wan["proto"], lucirpc.String("static"))
+		_, ok := wan["ifname"]
+		assert.Check(t, !ok)
+		committed, ok := server.CommittedSection("network", "lan")
+		assert.Assert(t, ok)
+		assert.DeepEqual(t, committed["proto"], lucirpc.String("static"))
+	})
+
+	t.Run("only undoes its own changes when a batch fails after warning about pending changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "lan", map[string]any{
+			"proto": "static",
+		})
+		server.SetSection("network", "interface", "wan", map[string]any{
+			"proto": "dhcp",
+		})
+		server.SetSection("network", "interface", "guest", map[string]any{
+			"proto": "static",
+		})
+		server.StageOptions("network", "lan", map[string]any{
+			"proto": "dhcp",
+		})
+		client := server.LuCIRPCClient(
+			ctx,
+			t,
+			lucirpc.WithCommitBatchWindow(100*time.Millisecond),
+		)
+
+		// When
+		var wg sync.WaitGroup
+		var errDelete error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errDelete = client.DeleteSection(ctx, "network", "wan")
+		}()
+		time.Sleep(20 * time.Millisecond)
+		_, errUpdate := client.UpdateSection(ctx, "network", "missing", lucirpc.Options{})
+		wg.Wait()
+
+		// Then
+		assert.NilError(t, errUpdate)
+		assert.ErrorContains(t, errDelete, "was able to delete section, but it was reverted")
+		lan, err := client.GetSection(ctx, "network", "lan")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, lan["proto"], lucirpc.String("dhcp"))
+		sections, err := client.ListSections(ctx, "network", "interface")
+		assert.NilError(t, err)
+		names := []string{}
+		for _, section := range sections {
+			name, err := section.GetString(".name")
+			assert.NilError(t, err)
+			names = append(names, name)
+		}
+		assert.DeepEqual(t, names, []string{"lan", "wan", "guest"})
+		wan, err := client.GetSection(ctx, "network", "wan")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, wan["proto"], lucirpc.String("dhcp"))
+	})
+
+	t.Run("changes a config without pending changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			if body.Method == "changes" {
+				fmt.Fprintf(w, `{
+					"result": []
+				}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithPendingChanges(lucirpc.PendingChangesFail),
+		)
+		defer close()
+
+		// When
+		_, err := client.UpdateSection(ctx, "network", "wan", lucirpc.Options{})
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, requests, []string{"changes", "tset", "commit"})
+	})
+
+	t.Run("refuses to change a config with pending changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			fmt.Fprintf(w, `{
+				"result": [["set", "lan", "proto", "dhcp"]]
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithPendingChanges(lucirpc.PendingChangesFail),
+		)
+		defer close()
+
+		// When
+		_, err := client.UpdateSection(ctx, "network", "wan", lucirpc.Options{})
+
+		// Then
+		var pendingChangesError lucirpc.PendingChangesError
+		assert.Assert(t, errors.As(err, &pendingChangesError))
+		assert.DeepEqual(t, pendingChangesError.Changes(), []string{"network.lan.proto='dhcp'"})
+		assert.ErrorContains(t, err, `config "network" has pending changes: network.lan.proto='dhcp'`)
+		assert.DeepEqual(t, requests, []string{"changes"})
+	})
+
+	t.Run("reports pending changes it commits by default", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "lan", map[string]any{
+			"proto": "static",
+		})
+		server.StageOptions("network", "lan", map[string]any{
+			"proto": "dhcp",
+		})
+		client := server.LuCIRPCClient(ctx, t)
+		var reported []lucirpc.PendingChangesError
+		ctx = lucirpc.ContextWithPendingChangesReporter(
+			ctx,
+			func(pending lucirpc.PendingChangesError, policy lucirpc.PendingChangesPolicy) {
+				assert.Equal(t, policy, lucirpc.PendingChangesWarn)
+				reported = append(reported, pending)
+			},
+		)
+
+		// When
+		_, err := client.CreateSection(ctx, "network", "interface", "wan", lucirpc.Options{})
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, len(reported), 1)
+		assert.DeepEqual(t, reported[0].Changes(), []string{"network.lan.proto='dhcp'"})
+		got, ok := server.CommittedSection("network", "lan")
+		assert.Assert(t, ok)
+		assert.DeepEqual(t, got["proto"], lucirpc.String("dhcp"))
+	})
+
+	t.Run("reverts pending changes before changing the config", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := lucirpctest.NewServer(t)
+		server.SetSection("network", "interface", "lan", map[string]any{
+			"proto": "static",
+		})
+		server.StageOptions("network", "lan", map[string]any{
+			"proto": "dhcp",
+		})
+		client := server.LuCIRPCClient(
+			ctx,
+			t,
+			lucirpc.WithPendingChanges(lucirpc.PendingChangesRevert),
+		)
+		var reported []lucirpc.PendingChangesError
+		ctx = lucirpc.ContextWithPendingChangesReporter(
+			ctx,
+			func(pending lucirpc.PendingChangesError, policy lucirpc.PendingChangesPolicy) {
+				assert.Equal(t, policy, lucirpc.PendingChangesRevert)
+				reported = append(reported, pending)
+			},
+		)
+
+		// When
+		_, err := client.CreateSection(ctx, "network", "interface", "wan", lucirpc.Options{})
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, len(reported), 1)
+		assert.DeepEqual(t, reported[0].Changes(), []string{"network.lan.proto='dhcp'"})
+		got, ok := server.CommittedSection("network", "lan")
+		assert.Assert(t, ok)
+		assert.DeepEqual(t, got["proto"], lucirpc.String("static"))
+		_, ok = server.CommittedSection("network", "wan")
+		assert.Check(t, ok)
+	})
+}
+
+func TestClientReorderSection(t *testing.T) {
+	t.Run("reorders the section and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.ReorderSection(
+			ctx,
+			"firewall",
+			"testing",
+			3,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`reorder "firewall" "testing" 3`,
+			`commit "firewall"`,
+		})
+	})
+
+	t.Run("reverts instead of committing when the section cannot be reordered", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			requests = append(requests, body.Method)
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+		)
+		defer close()
+
+		// When
+		got, err := client.ReorderSection(
+			ctx,
+			"firewall",
+			"testing",
+			3,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, !got)
+		assert.DeepEqual(t, requests, []string{"reorder", "revert"})
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("handles server not existing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			"http",
+			"non.existent",
+			80,
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "problem sending request to login")
+	})
+
+	t.Run("makes request to correct endpoint", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/auth":
+				fmt.Fprintf(w, `{
+					"result": "correct path"
+				}`)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("expects a 200 response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected login to respond with a 200")
+	})
+
+	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "[]")
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse login response")
+	})
+
+	t.Run("returns error when authentication fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"error": "invalid password"
+			}`)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to login")
+	})
+
+	t.Run("handles invalid responses", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "{}")
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "invalid login response")
+	})
+
+	t.Run("does not error when authentication succeeds", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": "abc123"
+			}`)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+	})
+}
+
+func TestClientRevertChanges(t *testing.T) {
+	t.Run("reverts changes to the config", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.RevertChanges(ctx, "network")
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{`revert "network"`})
+	})
+}
+
+func TestClientRunServiceAction(t *testing.T) {
+	t.Run("reloads the service through the sys library", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := r.URL.Path + " " + body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
@@ -582,22 +2401,22 @@ func TestClientGetSection(t *testing.T) {
 		defer close()
 
 		// When
-		_, err := client.GetSection(
-			ctx,
-			"",
-			"",
-		)
+		got, err := client.RunServiceAction(ctx, "network", lucirpc.ServiceReload)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse get section response")
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`/cgi-bin/luci/rpc/sys init.reload "network"`,
+		})
 	})
 
-	t.Run("returns error when get section fails", func(t *testing.T) {
+	t.Run("handles the exit code of the init script", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprintf(w, `{
-				"error": ""
+				"result": 1
 			}`)
 		}
 		client, close := authenticatedClient(
@@ -608,25 +2427,19 @@ func TestClientGetSection(t *testing.T) {
 		defer close()
 
 		// When
-		_, err := client.GetSection(
-			ctx,
-			"",
-			"",
-		)
+		got, err := client.RunServiceAction(ctx, "network", lucirpc.ServiceRestart)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to get section")
+		assert.NilError(t, err)
+		assert.Check(t, !got)
 	})
 
-	t.Run("handles errors in result", func(t *testing.T) {
+	t.Run("returns false when the service does not exist", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprintf(w, `{
-				"result": [
-					false,
-					"Invalid argument"
-				]
+				"result": null
 			}`)
 		}
 		client, close := authenticatedClient(
@@ -637,130 +2450,216 @@ func TestClientGetSection(t *testing.T) {
 		defer close()
 
 		// When
-		_, err := client.GetSection(
-			ctx,
-			"",
-			"",
-		)
+		got, err := client.RunServiceAction(ctx, "nonexistent", lucirpc.ServiceReload)
 
 		// Then
-		assert.ErrorContains(t, err, `incorrect config ("") and/or section (""): result from LuCI`)
+		assert.NilError(t, err)
+		assert.Check(t, !got)
 	})
+}
 
-	t.Run("does not handle unknown stuff in result", func(t *testing.T) {
+func TestClientRollback(t *testing.T) {
+	t.Run("applies and confirms changes with the token the device hands back", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var requests []string
 		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			params := []string{}
+			for _, param := range body.Params {
+				params = append(params, string(param))
+			}
+			requests = append(requests, fmt.Sprintf("%s %s", body.Method, strings.Join(params, " ")))
+			result := "true"
+			if body.Method == "apply" {
+				result = `"rollback-token"`
+			}
+
 			fmt.Fprintf(w, `{
-				"result": 31
-			}`)
+				"result": %s
+			}`, result)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRollback(2*time.Second),
 		)
 		defer close()
 
 		// When
-		_, err := client.GetSection(
+		got, err := client.UpdateSection(
 			ctx,
-			"",
-			"",
+			"network",
+			"lan",
+			lucirpc.Options{},
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse get section response")
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`tset "network" "lan" {}`,
+			`apply true`,
+			`confirm "rollback-token"`,
+		})
 	})
 
-	t.Run("handles invalid response", func(t *testing.T) {
+	t.Run("keeps trying to confirm until the device is reachable", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var confirms int
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, "{}")
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			result := "true"
+			switch body.Method {
+			case "apply":
+				result = `"rollback-token"`
+
+			case "confirm":
+				confirms++
+				if confirms == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+			}
+
+			fmt.Fprintf(w, `{
+				"result": %s
+			}`, result)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRollback(4*time.Second),
 		)
 		defer close()
 
 		// When
-		_, err := client.GetSection(
+		got, err := client.DeleteSection(
 			ctx,
-			"",
-			"",
+			"network",
+			"lan",
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "could not find section")
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.Equal(t, confirms, 2)
 	})
 
-	t.Run("returns section data when successful", func(t *testing.T) {
+	t.Run("returns error when the device does not hand back a token", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			result := "true"
+			if body.Method == "apply" {
+				result = "false"
+			}
+
 			fmt.Fprintf(w, `{
-				"result": {
-					".name": "section-name",
-					"baz": "1",
-					"foo": "bar"
-				}
-			}`)
+				"result": %s
+			}`, result)
 		}
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRollback(2*time.Second),
 		)
 		defer close()
 
 		// When
-		got, err := client.GetSection(
+		_, err := client.DeleteSection(
 			ctx,
-			"",
-			"",
+			"network",
+			"lan",
 		)
 
 		// Then
-		assert.NilError(t, err)
-		want := lucirpc.Options{
-			".name": lucirpc.String("section-name"),
-			"baz":   lucirpc.Boolean(true),
-			"foo":   lucirpc.String("bar"),
-		}
-		assert.DeepEqual(t, got, want)
+		assert.ErrorContains(t, err, "unable to apply changes: the device did not hand back a token to confirm them with")
 	})
-}
 
-func TestNewClient(t *testing.T) {
-	t.Run("handles server not existing", func(t *testing.T) {
+	t.Run("returns error when the device already rolled back", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			result := "true"
+			switch body.Method {
+			case "apply":
+				result = `"rollback-token"`
+
+			case "confirm":
+				result = "false"
+			}
+
+			fmt.Fprintf(w, `{
+				"result": %s
+			}`, result)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRollback(2*time.Second),
+		)
+		defer close()
 
 		// When
-		_, err := lucirpc.NewClient(
+		_, err := client.DeleteSection(
 			ctx,
-			"http",
-			"non.existent",
-			80,
-			"root",
-			"",
+			"network",
+			"lan",
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "problem sending request to login")
+		assert.ErrorContains(t, err, "unable to confirm changes, the device will roll back the changes: the device has no changes waiting for this token")
 	})
+}
 
-	t.Run("makes request to correct endpoint", func(t *testing.T) {
+func TestClientSessionExpiry(t *testing.T) {
+	t.Run("logs in again when the session expires", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var logins int
 		handle := func(w http.ResponseWriter, r *http.Request) {
 			switch r.URL.Path {
 			case "/cgi-bin/luci/rpc/auth":
+				logins++
 				fmt.Fprintf(w, `{
-					"result": "correct path"
+					"result": "token-%d"
+				}`, logins)
+
+			case "/cgi-bin/luci/rpc/uci":
+				if r.URL.Query().Get("auth") != "token-2" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				fmt.Fprintf(w, `{
+					"result": {
+						".name": "section-name"
+					}
 				}`)
 
 			default:
@@ -769,9 +2668,7 @@ func TestNewClient(t *testing.T) {
 		}
 		address, port, close := newServer(t, http.HandlerFunc(handle))
 		defer close()
-
-		// When
-		_, err := lucirpc.NewClient(
+		client, err := lucirpc.NewClient(
 			ctx,
 			address.Scheme,
 			address.Hostname(),
@@ -779,45 +2676,48 @@ func TestNewClient(t *testing.T) {
 			"root",
 			"",
 		)
-
-		// Then
 		assert.NilError(t, err)
-	})
-
-	t.Run("expects a 200 response", func(t *testing.T) {
-		// Given
-		ctx := context.Background()
-		handle := func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusAccepted)
-		}
-		address, port, close := newServer(t, http.HandlerFunc(handle))
-		defer close()
 
 		// When
-		_, err := lucirpc.NewClient(
+		got, err := client.GetSection(
 			ctx,
-			address.Scheme,
-			address.Hostname(),
-			uint16(port),
-			"root",
+			"",
 			"",
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "expected login to respond with a 200")
+		assert.NilError(t, err)
+		want := lucirpc.Options{
+			".name": lucirpc.String("section-name"),
+		}
+		assert.DeepEqual(t, got, want)
+		assert.Equal(t, logins, 2)
 	})
 
-	t.Run("expects a valid JSON-RPC response", func(t *testing.T) {
+	t.Run("returns access denied without logging in again when the call is not allowed", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var logins int
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, "[]")
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/auth":
+				logins++
+				fmt.Fprintf(w, `{
+					"result": "token-%d"
+				}`, logins)
+
+			case "/cgi-bin/luci/rpc/uci":
+				fmt.Fprintf(w, `{
+					"error": "Access denied"
+				}`)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
 		}
 		address, port, close := newServer(t, http.HandlerFunc(handle))
 		defer close()
-
-		// When
-		_, err := lucirpc.NewClient(
+		client, err := lucirpc.NewClient(
 			ctx,
 			address.Scheme,
 			address.Hostname(),
@@ -825,24 +2725,46 @@ func TestNewClient(t *testing.T) {
 			"root",
 			"",
 		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.CommitChanges(
+			ctx,
+			"",
+		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to parse login response")
+		var accessDenied lucirpc.AccessDeniedError
+		assert.Check(t, errors.As(err, &accessDenied))
+		assert.Equal(t, logins, 1)
 	})
 
-	t.Run("returns error when authentication fails", func(t *testing.T) {
+	t.Run("only retries once", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var (
+			logins   int
+			requests int
+		)
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, `{
-				"error": "invalid password"
-			}`)
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/auth":
+				logins++
+				fmt.Fprintf(w, `{
+					"result": "token-%d"
+				}`, logins)
+
+			case "/cgi-bin/luci/rpc/uci":
+				requests++
+				w.WriteHeader(http.StatusForbidden)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
 		}
 		address, port, close := newServer(t, http.HandlerFunc(handle))
 		defer close()
-
-		// When
-		_, err := lucirpc.NewClient(
+		client, err := lucirpc.NewClient(
 			ctx,
 			address.Scheme,
 			address.Hostname(),
@@ -850,22 +2772,50 @@ func TestNewClient(t *testing.T) {
 			"root",
 			"",
 		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.GetSection(
+			ctx,
+			"",
+			"",
+		)
 
 		// Then
-		assert.ErrorContains(t, err, "unable to login")
+		assert.ErrorContains(t, err, "expected get section to respond with a 200")
+		assert.Equal(t, logins, 2)
+		assert.Equal(t, requests, 2)
 	})
 
-	t.Run("handles invalid responses", func(t *testing.T) {
+	t.Run("returns error when logging in again fails", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var logins int
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, "{}")
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/auth":
+				logins++
+				if logins > 1 {
+					fmt.Fprintf(w, `{
+						"error": "invalid password"
+					}`)
+					return
+				}
+
+				fmt.Fprintf(w, `{
+					"result": "token-%d"
+				}`, logins)
+
+			case "/cgi-bin/luci/rpc/uci":
+				w.WriteHeader(http.StatusForbidden)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
 		}
 		address, port, close := newServer(t, http.HandlerFunc(handle))
 		defer close()
-
-		// When
-		_, err := lucirpc.NewClient(
+		client, err := lucirpc.NewClient(
 			ctx,
 			address.Scheme,
 			address.Hostname(),
@@ -873,24 +2823,48 @@ func TestNewClient(t *testing.T) {
 			"root",
 			"",
 		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.GetSection(
+			ctx,
+			"",
+			"",
+		)
 
 		// Then
-		assert.ErrorContains(t, err, "invalid login response")
+		assert.ErrorContains(t, err, "session expired during get section: unable to login")
 	})
 
-	t.Run("does not error when authentication succeeds", func(t *testing.T) {
+	t.Run("shares the new session between copies of the client", func(t *testing.T) {
 		// Given
 		ctx := context.Background()
+		var logins int
 		handle := func(w http.ResponseWriter, r *http.Request) {
-			fmt.Fprintf(w, `{
-				"result": "abc123"
-			}`)
+			switch r.URL.Path {
+			case "/cgi-bin/luci/rpc/auth":
+				logins++
+				fmt.Fprintf(w, `{
+					"result": "token-%d"
+				}`, logins)
+
+			case "/cgi-bin/luci/rpc/uci":
+				if r.URL.Query().Get("auth") != "token-2" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				fmt.Fprintf(w, `{
+					"result": true
+				}`)
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
 		}
 		address, port, close := newServer(t, http.HandlerFunc(handle))
 		defer close()
-
-		// When
-		_, err := lucirpc.NewClient(
+		client, err := lucirpc.NewClient(
 			ctx,
 			address.Scheme,
 			address.Hostname(),
@@ -898,9 +2872,17 @@ func TestNewClient(t *testing.T) {
 			"root",
 			"",
 		)
+		assert.NilError(t, err)
+		copied := *client
+
+		// When
+		_, err = client.CommitChanges(ctx, "")
+		assert.NilError(t, err)
+		_, err = copied.CommitChanges(ctx, "")
 
 		// Then
 		assert.NilError(t, err)
+		assert.Equal(t, logins, 2)
 	})
 }
 
@@ -926,7 +2908,7 @@ func TestClientUpdateSection(t *testing.T) {
 		)
 
 		// Then
-		assert.ErrorContains(t, err, "problem sending request to update section")
+		assert.ErrorContains(t, err, "problem sending request to show changes")
 	})
 
 	t.Run("makes a request to correct endpoint", func(t *testing.T) {
@@ -946,7 +2928,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -971,7 +2953,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -996,7 +2978,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -1023,7 +3005,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -1050,7 +3032,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -1077,7 +3059,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -1124,7 +3106,7 @@ func TestClientUpdateSection(t *testing.T) {
 		client, close := authenticatedClient(
 			t,
 			ctx,
-			http.HandlerFunc(handle),
+			withoutPendingChanges(http.HandlerFunc(handle)),
 		)
 		defer close()
 
@@ -1145,6 +3127,7 @@ func authenticatedClient(
 	t *testing.T,
 	ctx context.Context,
 	handler http.Handler,
+	options ...lucirpc.ClientOption,
 ) (*lucirpc.Client, func()) {
 	t.Helper()
 	handleWithAuth := func(w http.ResponseWriter, r *http.Request) {
@@ -1168,6 +3151,7 @@ func authenticatedClient(
 		uint16(port),
 		"root",
 		"",
+		options...,
 	)
 	if err != nil {
 		close()
@@ -1197,3 +3181,31 @@ func newServer(
 
 	return address, port, server.Close
 }
+
+// withoutPendingChanges answers the check for pending changes like a device with nothing staged,
+// and passes every other request to the `handler`.
+func withoutPendingChanges(
+	handler http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Method string `json:"method"`
+		}
+		err = json.Unmarshal(raw, &body)
+		if err == nil && body.Method == "changes" {
+			fmt.Fprintf(w, `{
+				"result": []
+			}`)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		handler.ServeHTTP(w, r)
+	})
+}
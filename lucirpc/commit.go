@@ -0,0 +1,169 @@
+package lucirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/internal/detachedcontext"
+)
+
+// commitBatcher groups commits of the same config together.
+//
+// Committing a config makes the device reload the services that depend on it.
+// When lots of sections in the same config change at once,
+// committing after every change makes those services restart over and over.
+// Instead, the first change to a config waits for `window` to pass,
+// and every change staged in the meantime is committed along with it.
+type commitBatcher struct {
+	commit  func(context.Context, string) (bool, error)
+	mutex   sync.Mutex
+	pending map[string]*commitBatch
+	revert  func(context.Context, string) error
+	window  time.Duration
+}
+
+// commitBatch is a single commit shared by every change that joined it.
+type commitBatch struct {
+	deadline time.Time
+	done     chan struct{}
+	err      error
+
+	// lead is sent to when the change committing the batch is cancelled,
+	// so one of the waiting changes commits it instead.
+	lead chan struct{}
+
+	result bool
+
+	// waiters counts the changes waiting for the batch that could still commit it,
+	// not counting one that was sent to `lead` and has not taken over yet.
+	// It is guarded by the [commitBatcher]'s mutex.
+	waiters int
+}
+
+// commitChanges waits for the next commit of `config` and returns its result.
+//
+// The first caller for a given `config` is the one that actually commits.
+// Everyone else waits for it to finish,
+// so any error is reported to every change that contributed to the commit.
+//
+// If the caller committing the batch is cancelled,
+// a waiting change takes over.
+// If nobody is waiting,
+// the staged changes are reverted rather than left for some later commit.
+func (b *commitBatcher) commitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	b.mutex.Lock()
+	batch, ok := b.pending[config]
+	if !ok {
+		batch = &commitBatch{
+			deadline: time.Now().Add(b.window),
+			done:     make(chan struct{}),
+			lead:     make(chan struct{}, 1),
+		}
+		b.pending[config] = batch
+	} else {
+		batch.waiters++
+	}
+	b.mutex.Unlock()
+
+	if !ok {
+		return b.lead(ctx, config, batch)
+	}
+
+	select {
+	case <-batch.done:
+		return batch.result, batch.err
+
+	case <-batch.lead:
+		return b.lead(ctx, config, batch)
+
+	case <-ctx.Done():
+		b.mutex.Lock()
+		select {
+		case <-batch.lead:
+			// This change was handed the batch as it was cancelled,
+			// so it was already left out of the waiters.
+			b.mutex.Unlock()
+			return false, b.abandon(ctx, config, batch)
+
+		default:
+		}
+		batch.waiters--
+		b.mutex.Unlock()
+		return false, ctx.Err()
+	}
+}
+
+// abandon gives up committing the `batch` because `ctx` was cancelled.
+// The batch is handed to a waiting change if there is one,
+// otherwise the staged changes are reverted.
+func (b *commitBatcher) abandon(
+	ctx context.Context,
+	config string,
+	batch *commitBatch,
+) error {
+	b.mutex.Lock()
+	if batch.waiters > 0 {
+		batch.waiters--
+		batch.lead <- struct{}{}
+		b.mutex.Unlock()
+		return ctx.Err()
+	}
+
+	// Anything staged from here on needs another commit.
+	delete(b.pending, config)
+	b.mutex.Unlock()
+
+	// The revert has to be sent even though `ctx` is cancelled.
+	batch.err = ctx.Err()
+	err := b.revert(detachedcontext.New(ctx), config)
+	if err != nil {
+		batch.err = errors.Join(batch.err, fmt.Errorf("unable to %s: %w", humanReadableRevertChanges, err))
+	}
+
+	close(batch.done)
+	return batch.err
+}
+
+// lead waits for the window of the `batch` to pass, then commits it.
+func (b *commitBatcher) lead(
+	ctx context.Context,
+	config string,
+	batch *commitBatch,
+) (bool, error) {
+	timer := time.NewTimer(time.Until(batch.deadline))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+
+	case <-ctx.Done():
+		return false, b.abandon(ctx, config, batch)
+	}
+
+	// Anything staged from here on needs another commit.
+	b.mutex.Lock()
+	delete(b.pending, config)
+	b.mutex.Unlock()
+
+	batch.result, batch.err = b.commit(ctx, config)
+	close(batch.done)
+	return batch.result, batch.err
+}
+
+func newCommitBatcher(
+	commit func(context.Context, string) (bool, error),
+	revert func(context.Context, string) error,
+	window time.Duration,
+) *commitBatcher {
+	return &commitBatcher{
+		commit:  commit,
+		pending: map[string]*commitBatch{},
+		revert:  revert,
+		window:  window,
+	}
+}
@@ -0,0 +1,120 @@
+package lucirpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	humanReadablePasswordCommand = "run password command"
+	humanReadablePasswordFile    = "read password file"
+)
+
+var (
+	errSessionTokenExpired = errors.New("the session token expired, and there are no credentials to login again with")
+)
+
+// credentials are how a [Client] authenticates with the device.
+// At most one of the ways other than `password` can be set.
+type credentials struct {
+	password        string
+	passwordCommand []string
+	passwordFile    string
+	sessionToken    string
+}
+
+// currentPassword returns the password to login with.
+//
+// The password file is read, or the password command is run, every time,
+// so a password that changes while the [Client] is in use is picked up when it logs in again.
+func (c credentials) currentPassword(
+	ctx context.Context,
+) (string, error) {
+	switch {
+	case len(c.passwordCommand) > 0:
+		stdout := bytes.Buffer{}
+		stderr := bytes.Buffer{}
+		command := exec.CommandContext(ctx, c.passwordCommand[0], c.passwordCommand[1:]...)
+		command.Stderr = &stderr
+		command.Stdout = &stdout
+		err := command.Run()
+		if err != nil {
+			return "", NewAuthenticationError(fmt.Errorf("unable to %s %q: %w: %s", humanReadablePasswordCommand, c.passwordCommand[0], err, strings.TrimSpace(stderr.String())))
+		}
+
+		return trimLineEnding(stdout.String()), nil
+
+	case c.passwordFile != "":
+		contents, err := os.ReadFile(c.passwordFile)
+		if err != nil {
+			return "", NewAuthenticationError(fmt.Errorf("unable to %s: %w", humanReadablePasswordFile, err))
+		}
+
+		return trimLineEnding(string(contents)), nil
+
+	default:
+		return c.password, nil
+	}
+}
+
+// newSession starts a session with the device.
+// If there is a session token, that session is used as-is.
+// Otherwise, `login` is used with the current password.
+func (c credentials) newSession(
+	ctx context.Context,
+	login func(context.Context, string) (string, error),
+) (*session, error) {
+	if c.sessionToken != "" {
+		return newSessionWithToken(c.sessionToken), nil
+	}
+
+	authenticate := func(ctx context.Context) (string, error) {
+		password, err := c.currentPassword(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return login(ctx, password)
+	}
+	return newSession(ctx, authenticate)
+}
+
+// validate checks that only one way to authenticate is set.
+func (c credentials) validate() error {
+	ways := []string{}
+	if c.password != "" {
+		ways = append(ways, "a password")
+	}
+
+	if len(c.passwordCommand) > 0 {
+		ways = append(ways, "a password command")
+	}
+
+	if c.passwordFile != "" {
+		ways = append(ways, "a password file")
+	}
+
+	if c.sessionToken != "" {
+		ways = append(ways, "a session token")
+	}
+
+	if len(ways) > 1 {
+		return fmt.Errorf("only one way to authenticate can be used, got %s", strings.Join(ways, " and "))
+	}
+
+	return nil
+}
+
+// trimLineEnding removes the line ending that files and command output usually end with.
+// Anything else is part of the credential.
+func trimLineEnding(
+	value string,
+) string {
+	value = strings.TrimSuffix(value, "\n")
+	return strings.TrimSuffix(value, "\r")
+}
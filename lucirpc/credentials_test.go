@@ -0,0 +1,221 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithPasswordCommand(t *testing.T) {
+	t.Run("logs in with what the command prints", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var passwords []string
+		address, port, close := newServer(t, loginHandler(t, &passwords))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithPasswordCommand([]string{"echo", "from a command"}),
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, passwords, []string{"from a command"})
+	})
+
+	t.Run("returns an AuthenticationError when the command fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var passwords []string
+		address, port, close := newServer(t, loginHandler(t, &passwords))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithPasswordCommand([]string{"false"}),
+		)
+
+		// Then
+		var authenticationError lucirpc.AuthenticationError
+		assert.Check(t, errors.As(err, &authenticationError))
+		assert.ErrorContains(t, err, "unable to run password command")
+		assert.Check(t, len(passwords) == 0)
+	})
+}
+
+func TestWithPasswordFile(t *testing.T) {
+	t.Run("logs in with the contents of the file", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var passwords []string
+		address, port, close := newServer(t, loginHandler(t, &passwords))
+		defer close()
+		file := filepath.Join(t.TempDir(), "password")
+		err := os.WriteFile(file, []byte("from a file\n"), 0600)
+		assert.NilError(t, err)
+
+		// When
+		_, err = lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithPasswordFile(file),
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, passwords, []string{"from a file"})
+	})
+
+	t.Run("cannot be used with a password", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			"http",
+			"non.existent",
+			80,
+			"root",
+			"hunter2",
+			lucirpc.WithPasswordFile("password"),
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "only one way to authenticate can be used, got a password and a password file")
+	})
+}
+
+func TestWithSessionToken(t *testing.T) {
+	t.Run("uses the token without logging in", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var tokens []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/cgi-bin/luci/rpc/auth" {
+				t.Errorf("unexpected login")
+			}
+
+			tokens = append(tokens, r.URL.Query().Get("auth"))
+			fmt.Fprintf(w, `{
+				"result": {
+					".name": "lan",
+					".type": "interface"
+				}
+			}`)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithSessionToken("existing"),
+		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.GetSection(ctx, "network", "lan")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, tokens, []string{"existing"})
+	})
+
+	t.Run("returns an AuthenticationError once the session expires", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"id": 0,
+				"jsonrpc": "2.0",
+				"error": {"code": -32002, "message": "Access denied"}
+			}`)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithSessionToken("expired"),
+		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.GetSection(ctx, "network", "lan")
+
+		// Then
+		var authenticationError lucirpc.AuthenticationError
+		assert.Check(t, errors.As(err, &authenticationError))
+	})
+
+	t.Run("is not supported over SSH", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		// When
+		_, err := lucirpc.NewSSHClient(
+			ctx,
+			"non.existent",
+			22,
+			"root",
+			lucirpc.SSHOptions{},
+			lucirpc.WithSessionToken("existing"),
+		)
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotSupportedError("login with a session token", "the LuCI RPC transport", "the ubus transport"))
+	})
+}
+
+// loginHandler responds to LuCI RPC logins, and records each password it was given.
+func loginHandler(
+	t *testing.T,
+	passwords *[]string,
+) http.Handler {
+	t.Helper()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Params []string `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.Check(t, err)
+		assert.Check(t, len(body.Params) == 2)
+		*passwords = append(*passwords, body.Params[len(body.Params)-1])
+		fmt.Fprintf(w, `{
+			"result": "abc123"
+		}`)
+	})
+}
@@ -0,0 +1,294 @@
+package lucirpc
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// NewAccessDeniedError constructs a new [AccessDeniedError].
+func NewAccessDeniedError(
+	err error,
+) AccessDeniedError {
+	return AccessDeniedError{
+		err: err,
+	}
+}
+
+// NewAuthenticationError constructs a new [AuthenticationError].
+func NewAuthenticationError(
+	err error,
+) AuthenticationError {
+	return AuthenticationError{
+		err: err,
+	}
+}
+
+// NewFileNotFoundError constructs a new [FileNotFoundError].
+// The `path` should be what was looked for.
+func NewFileNotFoundError(
+	path string,
+) FileNotFoundError {
+	return FileNotFoundError{
+		path: path,
+	}
+}
+
+// NewNotFoundError constructs a new [NotFoundError].
+// The `config` and `section` should be what was looked for.
+func NewNotFoundError(
+	config string,
+	section string,
+) NotFoundError {
+	return NotFoundError{
+		config:  config,
+		section: section,
+	}
+}
+
+// NewNotSupportedError constructs a new [NotSupportedError].
+// The `action` should describe what was attempted (e.g. "read file").
+// The `supportedBy` are the transports that can do it instead (e.g. "LuCI RPC").
+func NewNotSupportedError(
+	action string,
+	supportedBy ...string,
+) NotSupportedError {
+	return NotSupportedError{
+		action:      action,
+		supportedBy: strings.Join(supportedBy, " or "),
+	}
+}
+
+// NewPendingChangesError constructs a new [PendingChangesError].
+// The `changes` should be what [Client.ShowChanges] returned for the `config`.
+func NewPendingChangesError(
+	config string,
+	changes [][]string,
+) PendingChangesError {
+	return PendingChangesError{
+		changes: changes,
+		config:  config,
+	}
+}
+
+// NewProtocolError constructs a new [ProtocolError].
+func NewProtocolError(
+	err error,
+) ProtocolError {
+	return ProtocolError{
+		err: err,
+	}
+}
+
+// NewRPCError constructs a new [RPCError].
+func NewRPCError(
+	err error,
+) RPCError {
+	return RPCError{
+		err: err,
+	}
+}
+
+// NewTransportError constructs a new [TransportError].
+func NewTransportError(
+	err error,
+) TransportError {
+	return TransportError{
+		err: err,
+	}
+}
+
+// AccessDeniedError represents the device refusing a request from an authenticated user.
+// This usually means the user's ACLs do not allow the request.
+type AccessDeniedError struct {
+	err error
+}
+
+func (e AccessDeniedError) Error() string {
+	return e.err.Error()
+}
+
+func (e AccessDeniedError) Unwrap() error {
+	return e.err
+}
+
+// AuthenticationError represents a failure to login to the device.
+// The underlying cause could be anything from wrong credentials to a [TransportError].
+type AuthenticationError struct {
+	err error
+}
+
+func (e AuthenticationError) Error() string {
+	return e.err.Error()
+}
+
+func (e AuthenticationError) Unwrap() error {
+	return e.err
+}
+
+// FileNotFoundError represents a file that does not exist on the device.
+type FileNotFoundError struct {
+	path string
+}
+
+func (e FileNotFoundError) Equal(other FileNotFoundError) bool {
+	return e.path == other.path
+}
+
+func (e FileNotFoundError) Error() string {
+	return fmt.Sprintf("could not find file %s", e.path)
+}
+
+// NotFoundError represents a section that does not exist on the device.
+type NotFoundError struct {
+	config  string
+	section string
+}
+
+func (e NotFoundError) Equal(other NotFoundError) bool {
+	return e.config == other.config &&
+		e.section == other.section
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("could not find section %s.%s", e.config, e.section)
+}
+
+// NotSupportedError represents something the transport the [Client] uses cannot do.
+type NotSupportedError struct {
+	action      string
+	supportedBy string
+}
+
+func (e NotSupportedError) Equal(other NotSupportedError) bool {
+	return e.action == other.action &&
+		e.supportedBy == other.supportedBy
+}
+
+func (e NotSupportedError) Error() string {
+	if e.supportedBy == "" {
+		return fmt.Sprintf("unable to %s: not supported by this transport", e.action)
+	}
+
+	return fmt.Sprintf("unable to %s: not supported by this transport, only by %s", e.action, e.supportedBy)
+}
+
+// PendingChangesError represents a config that already had staged changes before the [Client] changed it.
+// Committing the config would commit those changes too.
+type PendingChangesError struct {
+	changes [][]string
+	config  string
+}
+
+// Changes are the pending changes, formatted the same way as `uci changes`.
+func (e PendingChangesError) Changes() []string {
+	result := []string{}
+	for _, change := range e.changes {
+		result = append(result, formatChange(e.config, change))
+	}
+
+	return result
+}
+
+// Config is the config with pending changes.
+func (e PendingChangesError) Config() string {
+	return e.config
+}
+
+func (e PendingChangesError) Equal(other PendingChangesError) bool {
+	return e.config == other.config &&
+		slices.EqualFunc(e.changes, other.changes, slices.Equal[string])
+}
+
+func (e PendingChangesError) Error() string {
+	return fmt.Sprintf("config %q has pending changes: %s", e.config, strings.Join(e.Changes(), ", "))
+}
+
+// ProtocolError represents a response from the device that could not be understood.
+type ProtocolError struct {
+	err error
+}
+
+func (e ProtocolError) Error() string {
+	return e.err.Error()
+}
+
+func (e ProtocolError) Unwrap() error {
+	return e.err
+}
+
+// RPCError represents the device answering a call with an error of its own.
+// This usually means the call does not make sense on the device (e.g. a config it does not have).
+type RPCError struct {
+	err error
+}
+
+func (e RPCError) Error() string {
+	return e.err.Error()
+}
+
+func (e RPCError) Unwrap() error {
+	return e.err
+}
+
+// TransportError represents a failure to send a request to the device, or to get a response back.
+type TransportError struct {
+	err error
+}
+
+func (e TransportError) Error() string {
+	return e.err.Error()
+}
+
+func (e TransportError) Unwrap() error {
+	return e.err
+}
+
+// formatChange formats a single `change` to `config` the same way as `uci changes`.
+// Each change is the operation, followed by the section, and then any option and value.
+func formatChange(
+	config string,
+	change []string,
+) string {
+	if len(change) < 2 {
+		return strings.Join(change, " ")
+	}
+
+	operation := change[0]
+	path := fmt.Sprintf("%s.%s", config, change[1])
+	switch {
+	case operation == "add" && len(change) == 3:
+		return fmt.Sprintf("%s=%s", path, change[2])
+
+	case operation == "set" && len(change) == 3:
+		return fmt.Sprintf("%s=%s", path, change[2])
+
+	case operation == "set" && len(change) == 4:
+		return fmt.Sprintf("%s.%s='%s'", path, change[2], change[3])
+
+	case operation == "remove" && len(change) == 2:
+		return fmt.Sprintf("-%s", path)
+
+	case operation == "remove" && len(change) >= 3:
+		return fmt.Sprintf("-%s.%s", path, change[2])
+
+	case operation == "list-add" && len(change) == 4:
+		return fmt.Sprintf("%s.%s+='%s'", path, change[2], change[3])
+
+	case operation == "list-del" && len(change) == 4:
+		return fmt.Sprintf("%s.%s-='%s'", path, change[2], change[3])
+
+	case operation == "rename" && len(change) == 3:
+		return fmt.Sprintf("@%s=%s", path, change[2])
+
+	case operation == "rename" && len(change) == 4:
+		return fmt.Sprintf("@%s.%s=%s", path, change[2], change[3])
+
+	case operation == "order" && len(change) == 3:
+		return fmt.Sprintf("%s=^%s", path, change[2])
+
+	default:
+		return strings.Join(change, " ")
+	}
+}
@@ -0,0 +1,261 @@
+package lucirpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestClientErrors(t *testing.T) {
+	t.Run("returns a NotFoundError when the section does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		var got lucirpc.NotFoundError
+		assert.Assert(t, errors.As(err, &got))
+		assert.DeepEqual(t, got, lucirpc.NewNotFoundError("network", "testing"))
+	})
+
+	t.Run("returns a NotFoundError when ubus cannot find the section", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[4]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		var got lucirpc.NotFoundError
+		assert.Assert(t, errors.As(err, &got))
+		assert.DeepEqual(t, got, lucirpc.NewNotFoundError("network", "testing"))
+	})
+
+	t.Run("returns an AccessDeniedError when the device refuses a new session", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		var got lucirpc.AccessDeniedError
+		assert.Assert(t, errors.As(err, &got))
+	})
+
+	t.Run("returns an AccessDeniedError when ubus denies permission", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[6]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		var got lucirpc.AccessDeniedError
+		assert.Assert(t, errors.As(err, &got))
+	})
+
+	t.Run("returns an AuthenticationError when login fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		var got lucirpc.AuthenticationError
+		assert.Assert(t, errors.As(err, &got))
+	})
+
+	t.Run("returns a ProtocolError when the response cannot be parsed", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": "not changes"
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		var got lucirpc.ProtocolError
+		assert.Assert(t, errors.As(err, &got))
+	})
+
+	t.Run("returns an RPCError when the device answers with an error", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"error": "Method not found"
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		var got lucirpc.RPCError
+		assert.Assert(t, errors.As(err, &got))
+		assert.ErrorContains(t, err, "show changes error: Method not found")
+	})
+
+	t.Run("returns an RPCError when LuCI answers a read with an array", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": [null, "Invalid argument"]
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		var got lucirpc.RPCError
+		assert.Assert(t, errors.As(err, &got))
+		assert.ErrorContains(t, err, `incorrect config ("network") and/or section ("testing")`)
+	})
+
+	t.Run("returns an RPCError when ubus answers with an error", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[2]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		var got lucirpc.RPCError
+		assert.Assert(t, errors.As(err, &got))
+		assert.ErrorContains(t, err, "invalid argument")
+	})
+
+	t.Run("returns a TransportError when the device cannot be reached", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		var got lucirpc.TransportError
+		assert.Assert(t, errors.As(err, &got))
+	})
+}
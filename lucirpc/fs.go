@@ -0,0 +1,90 @@
+package lucirpc
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	humanReadableReadFile  = "read file"
+	humanReadableStatFile  = "stat file"
+	humanReadableWriteFile = "write file"
+)
+
+// FileInfo describes a file on the device.
+type FileInfo struct {
+	// GroupID is the id of the group that owns the file.
+	GroupID int
+
+	// Mode is the permissions of the file, formatted like `ls -l` (e.g. `rw-r--r--`).
+	Mode string
+
+	// ModifiedTime is when the file was last changed.
+	ModifiedTime time.Time
+
+	// Size is the size of the file in bytes.
+	Size int64
+
+	// Type is the kind of file (e.g. `reg` for a regular file, or `dir` for a directory).
+	Type string
+
+	// UserID is the id of the user that owns the file.
+	UserID int
+}
+
+// ReadFile reads the whole file at `path` on the device.
+// If the file does not exist, a [FileNotFoundError] is returned.
+//
+// Only the LuCI RPC transport can read files.
+func (c *Client) ReadFile(
+	ctx context.Context,
+	path string,
+) ([]byte, error) {
+	transport, ok := c.transport.(fsTransport)
+	if !ok {
+		return nil, NewNotSupportedError(humanReadableReadFile, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.readFile(ctx, path)
+}
+
+// StatFile describes the file at `path` on the device.
+// If the file does not exist, a [FileNotFoundError] is returned.
+//
+// Only the LuCI RPC transport can stat files.
+func (c *Client) StatFile(
+	ctx context.Context,
+	path string,
+) (FileInfo, error) {
+	transport, ok := c.transport.(fsTransport)
+	if !ok {
+		return FileInfo{}, NewNotSupportedError(humanReadableStatFile, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.statFile(ctx, path)
+}
+
+// WriteFile replaces the contents of the file at `path` on the device with `data`.
+// The file is created if it does not exist,
+// but the directory it is in must already exist.
+//
+// Only the LuCI RPC transport can write files.
+func (c *Client) WriteFile(
+	ctx context.Context,
+	path string,
+	data []byte,
+) (bool, error) {
+	transport, ok := c.transport.(fsTransport)
+	if !ok {
+		return false, NewNotSupportedError(humanReadableWriteFile, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.writeFile(ctx, path, data)
+}
+
+// fsTransport is implemented by any [transport] that can read and write files on the device.
+type fsTransport interface {
+	readFile(ctx context.Context, path string) ([]byte, error)
+	statFile(ctx context.Context, path string) (FileInfo, error)
+	writeFile(ctx context.Context, path string, data []byte) (bool, error)
+}
@@ -0,0 +1,194 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestClientReadFile(t *testing.T) {
+	t.Run("decodes the contents of the file", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := r.URL.Path + " " + body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": "b3BlbndydAo="
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.ReadFile(ctx, "/etc/hostname")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []byte("openwrt\n"))
+		assert.DeepEqual(t, requests, []string{
+			`/cgi-bin/luci/rpc/fs readfile "/etc/hostname"`,
+		})
+	})
+
+	t.Run("fails when the file does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": null
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.ReadFile(ctx, "/nonexistent")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewFileNotFoundError("/nonexistent"))
+	})
+
+	t.Run("is not supported over ubus", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			t.Errorf("unexpected call: %#v", call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.ReadFile(ctx, "/etc/hostname")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotSupportedError("read file", "the LuCI RPC transport"))
+	})
+}
+
+func TestClientStatFile(t *testing.T) {
+	t.Run("describes the file", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, r.URL.Path, "/cgi-bin/luci/rpc/fs")
+			fmt.Fprintf(w, `{
+				"result": {
+					"gid": 0,
+					"modestr": "rw-r--r--",
+					"mtime": 1700000000,
+					"size": 8,
+					"type": "reg",
+					"uid": 0
+				}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.StatFile(ctx, "/etc/hostname")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.FileInfo{
+			GroupID:      0,
+			Mode:         "rw-r--r--",
+			ModifiedTime: time.Unix(1700000000, 0),
+			Size:         8,
+			Type:         "reg",
+			UserID:       0,
+		})
+	})
+
+	t.Run("fails when the file does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": [null, "No such file or directory", 2]
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		_, err := client.StatFile(ctx, "/nonexistent")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewFileNotFoundError("/nonexistent"))
+	})
+}
+
+func TestClientWriteFile(t *testing.T) {
+	t.Run("encodes the contents of the file", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := r.URL.Path + " " + body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.WriteFile(ctx, "/etc/hostname", []byte("openwrt\n"))
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, requests, []string{
+			`/cgi-bin/luci/rpc/fs writefile "/etc/hostname" "b3BlbndydAo="`,
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package lucirpc
+
+import (
+	"sync"
+)
+
+// configLocks makes changes to each UCI config one at a time.
+//
+// Changes are staged on the device before they are committed,
+// and committing a config commits everything staged in it.
+// Some changes take more than one request to stage (e.g. adding a section and then setting its options).
+// If another change committed the config in between,
+// it would commit a change that was only partly staged.
+type configLocks struct {
+	locks map[string]*configLock
+	mutex sync.Mutex
+}
+
+// configLock is held while staging, committing, or reverting changes to a config.
+type configLock struct {
+	sync.Mutex
+
+	// clean are the configs that were checked to have nothing else staged in them,
+	// before the [Client] staged its own changes.
+	clean map[string]bool
+
+	// events counts every time the staged changes were committed or reverted.
+	events uint64
+
+	// reverts are the events that reverted staged changes instead of committing them,
+	// kept only until every change waiting on them has checked.
+	reverts map[uint64]bool
+
+	// staged are the configs the [Client] has staged changes in that are waiting to be committed.
+	// Usually that is only the config the lock is for,
+	// but every config shares the same lock when changes are applied with a rollback.
+	staged map[string]bool
+
+	// undo are how to undo each operation the [Client] staged in a config that is not clean,
+	// in the order they were staged.
+	undo map[string][]undoFunc
+
+	// waiting counts the changes staged after each event that have not checked how they ended yet.
+	waiting map[uint64]int
+}
+
+// committed records that the staged changes were committed.
+func (l *configLock) committed() {
+	l.events++
+	l.reset()
+}
+
+// hasStaged checks if the [Client] has staged changes in any config that are waiting to be committed.
+func (l *configLock) hasStaged() bool {
+	return len(l.staged) > 0
+}
+
+// lock waits for any other change to `config` to finish,
+// and returns the held lock.
+func (ls *configLocks) lock(
+	config string,
+) *configLock {
+	ls.mutex.Lock()
+	lock, ok := ls.locks[config]
+	if !ok {
+		lock = &configLock{
+			reverts: map[uint64]bool{},
+			waiting: map[uint64]int{},
+		}
+		lock.reset()
+		ls.locks[config] = lock
+	}
+	ls.mutex.Unlock()
+
+	lock.Lock()
+	return lock
+}
+
+// reverted records that the staged changes were reverted.
+// Nothing is kept if no change is waiting to check.
+func (l *configLock) reverted() {
+	if l.waiting[l.events] > 0 {
+		l.reverts[l.events+1] = true
+	}
+
+	l.events++
+	l.reset()
+}
+
+// reset forgets about the changes that were staged,
+// once they were committed or reverted.
+func (l *configLock) reset() {
+	l.clean = map[string]bool{}
+	l.staged = map[string]bool{}
+	l.undo = map[string][]undoFunc{}
+}
+
+// revertedAfter checks if changes staged after the `event` were reverted rather than committed.
+// The next event after staging a change is always the one that committed or reverted it.
+// Each change that called [configLock.wait] must call this exactly once,
+// and the last one forgets about the `event`.
+func (l *configLock) revertedAfter(
+	event uint64,
+) bool {
+	reverted := l.reverts[event+1]
+	l.waiting[event]--
+	if l.waiting[event] <= 0 {
+		delete(l.reverts, event+1)
+		delete(l.waiting, event)
+	}
+
+	return reverted
+}
+
+// wait records that a change was staged and is waiting for the next event,
+// and returns the current event to check with [configLock.revertedAfter].
+func (l *configLock) wait() uint64 {
+	l.waiting[l.events]++
+	return l.events
+}
+
+func newConfigLocks() *configLocks {
+	return &configLocks{
+		locks: map[string]*configLock{},
+	}
+}
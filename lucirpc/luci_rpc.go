@@ -0,0 +1,1436 @@
+package lucirpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	methodAdd         = "add"
+	methodApply       = "apply"
+	methodChanges     = "changes"
+	methodCommit      = "commit"
+	methodConfirm     = "confirm"
+	methodDelete      = "delete"
+	methodExec        = "exec"
+	methodGetAll      = "get_all"
+	methodHostname    = "hostname"
+	methodInitReload  = "init.reload"
+	methodInitRestart = "init.restart"
+	methodLogin       = "login"
+	methodNetDevices  = "net.devices"
+	methodReadFile    = "readfile"
+	methodReboot      = "reboot"
+	methodReorder     = "reorder"
+	methodRevert      = "revert"
+	methodSection     = "section"
+	methodStat        = "stat"
+	methodTSet        = "tset"
+	methodWriteFile   = "writefile"
+
+	pathAuth = "/cgi-bin/luci/rpc/auth"
+	pathFS   = "/cgi-bin/luci/rpc/fs"
+	pathSys  = "/cgi-bin/luci/rpc/sys"
+	pathUCI  = "/cgi-bin/luci/rpc/uci"
+
+	queryKeyAuth = "auth"
+)
+
+// luciRPCTransport talks to UCI through the JSON-RPC API provided by `luci-mod-rpc`.
+// See https://github.com/openwrt/luci/wiki/JsonRpcHowTo for more information.
+type luciRPCTransport struct {
+	jsonRPCClientFS  jsonRPCClient
+	jsonRPCClientSys jsonRPCClient
+	jsonRPCClientUCI jsonRPCClient
+}
+
+func (t luciRPCTransport) addSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	options Options,
+) (string, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableAddSection, err)
+	}
+
+	marshalledSectionType, err := json.Marshal(sectionType)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize sectionType %q for %s: %w", sectionType, humanReadableAddSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodAdd,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSectionType,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableAddSection,
+		requestBody,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableAddSection, err)
+	}
+
+	// The result can be the name of the new section to indicate success,
+	// or `null` to indicate failure.
+	if responseBody == nil {
+		return "", fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableAddSection)
+	}
+
+	var section string
+	err = json.Unmarshal(*responseBody, &section)
+	if err != nil {
+		return "", NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableAddSection, err))
+	}
+
+	if len(options) == 0 {
+		return section, nil
+	}
+
+	// LuCI cannot set the options while adding the section,
+	// so they are set afterwards.
+	result, err := t.updateSection(
+		ctx,
+		config,
+		section,
+		options,
+	)
+	if err != nil {
+		return "", fmt.Errorf("was able to %s %q, but could not set its options: %w", humanReadableAddSection, section, err)
+	}
+
+	if !result {
+		return "", fmt.Errorf("was able to %s %q, but could not set its options: it is not clear why this happened", humanReadableAddSection, section)
+	}
+
+	return section, nil
+}
+
+// applyChangesWithRollback commits every staged change and reloads the affected services,
+// with the rollback that LuCI's `uci` library provides.
+//
+// LuCI picks how long the device waits before rolling back
+// (the `luci.apply.rollback` setting, and never less than 90 seconds),
+// so `timeout` only decides how long we keep trying to confirm the changes.
+// LuCI hands back a token when it applies the changes,
+// and only confirms them when it gets that token back.
+// After waiting for `holdoff` to let the services reload,
+// we connect to the device again and confirm the changes.
+// If the session expired in the meantime,
+// LuCI rejects the request before confirming anything,
+// so logging in again and sending it once more is safe.
+// If we cannot reach the device before `timeout` passes,
+// we stop trying and let the device roll back.
+func (t luciRPCTransport) applyChangesWithRollback(
+	ctx context.Context,
+	timeout time.Duration,
+	holdoff time.Duration,
+) error {
+	deadline := time.Now().Add(timeout)
+	marshalledRollback, err := json.Marshal(true)
+	if err != nil {
+		return fmt.Errorf("unable to serialize rollback for %s: %w", humanReadableApplyChanges, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodApply,
+		Params: []json.RawMessage{
+			marshalledRollback,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableApplyChanges,
+		requestBody,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to %s: %w", humanReadableApplyChanges, err)
+	}
+
+	// The result can be the token to confirm the changes with to indicate success,
+	// or `false` or `null` to indicate failure.
+	var token string
+	if responseBody != nil {
+		err = json.Unmarshal(*responseBody, &token)
+	}
+
+	if err != nil || token == "" {
+		return fmt.Errorf("unable to %s: the device did not hand back a token to confirm them with", humanReadableApplyChanges)
+	}
+
+	marshalledToken, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("unable to serialize token for %s: %w", humanReadableConfirmChanges, err)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	wait := holdoff
+	for {
+		select {
+		case <-time.After(wait):
+
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+
+			return fmt.Errorf("could not reach the device to %s, it will roll back the changes: %w", humanReadableConfirmChanges, err)
+		}
+
+		// Make sure we can open a new connection,
+		// rather than reusing one from before the services reloaded.
+		t.jsonRPCClientUCI.client.CloseIdleConnections()
+		requestBody := jsonRPCRequestBody{
+			Method: methodConfirm,
+			Params: []json.RawMessage{
+				marshalledToken,
+			},
+		}
+		responseBody, err = t.jsonRPCClientUCI.Invoke(
+			ctx,
+			humanReadableConfirmChanges,
+			requestBody,
+		)
+		if err == nil {
+			return parseConfirmResult(responseBody)
+		}
+
+		if !errors.As(err, &temporaryError{}) {
+			return fmt.Errorf("unable to %s, the device will roll back the changes: %w", humanReadableConfirmChanges, err)
+		}
+
+		wait = rollbackConfirmInterval
+	}
+}
+
+// capabilities lists the configs with the `sys` library and reads the release with the `fs` library.
+// Each library that answers is one of the RPC modules.
+func (t luciRPCTransport) capabilities(
+	ctx context.Context,
+) (Capabilities, error) {
+	result := Capabilities{}
+	rpcModules := []string{}
+	output, err := t.exec(ctx, capabilitiesListConfigsCommand)
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil {
+		result.Configs = parseConfigs(output)
+		rpcModules = append(rpcModules, "sys")
+	}
+
+	contents, err := t.readFile(ctx, capabilitiesReleaseFile)
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil {
+		result.Release = parseRelease(string(contents))
+		rpcModules = append(rpcModules, "fs")
+	}
+
+	sort.Strings(rpcModules)
+	result.RPCModules = rpcModules
+	return result, nil
+}
+
+func (t luciRPCTransport) commitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableCommitChanges, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodCommit,
+		Params: []json.RawMessage{
+			marshalledConfig,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableCommitChanges,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCommitChanges, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableCommitChanges, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) createSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	section string,
+	options Options,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableCreateSection, err)
+	}
+
+	marshalledSectionType, err := json.Marshal(sectionType)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize sectionType %q for %s: %w", sectionType, humanReadableCreateSection, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableCreateSection, err)
+	}
+
+	marshalledOptions, err := json.Marshal(options)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize options %q for %s: %w", options, humanReadableCreateSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodSection,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSectionType,
+			marshalledSection,
+			marshalledOptions,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableCreateSection,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCreateSection, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableCreateSection, err))
+	}
+
+	if !result {
+		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableCreateSection)
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) deleteOptions(
+	ctx context.Context,
+	config string,
+	section string,
+	options []string,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableDeleteOptions, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableDeleteOptions, err)
+	}
+
+	// LuCI can only delete one option at a time.
+	for _, option := range options {
+		marshalledOption, err := json.Marshal(option)
+		if err != nil {
+			return false, fmt.Errorf("unable to serialize option %q for %s: %w", option, humanReadableDeleteOptions, err)
+		}
+
+		requestBody := jsonRPCRequestBody{
+			Method: methodDelete,
+			Params: []json.RawMessage{
+				marshalledConfig,
+				marshalledSection,
+				marshalledOption,
+			},
+		}
+		responseBody, err := t.jsonRPCClientUCI.Invoke(
+			ctx,
+			humanReadableDeleteOptions,
+			requestBody,
+		)
+		if err != nil {
+			return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteOptions, err)
+		}
+
+		// The result can be `true` to indicate success,
+		// or `null` to indicate failure.
+		var result bool
+		if responseBody == nil {
+			return false, nil
+		}
+
+		err = json.Unmarshal(*responseBody, &result)
+		if err != nil {
+			return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableDeleteOptions, err))
+		}
+
+		if !result {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (t luciRPCTransport) deleteSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableDeleteSection, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableDeleteSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodDelete,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSection,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableDeleteSection,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteSection, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableDeleteSection, err))
+	}
+
+	if !result {
+		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableDeleteSection)
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) exec(
+	ctx context.Context,
+	command string,
+) (string, error) {
+	marshalledCommand, err := json.Marshal(command)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize command for %s: %w", humanReadableExec, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodExec,
+		Params: []json.RawMessage{
+			marshalledCommand,
+		},
+	}
+	responseBody, err := t.jsonRPCClientSys.Invoke(
+		ctx,
+		humanReadableExec,
+		requestBody,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableExec, err)
+	}
+
+	if responseBody == nil {
+		return "", nil
+	}
+
+	var result string
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return "", NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableExec, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) getSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (Options, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableGetSection, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableGetSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodGetAll,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSection,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableGetSection,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableGetSection, err)
+	}
+
+	if responseBody == nil {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	// Depending on the `config` and `section`,
+	// this method can return a response that is an array instead of an object.
+	// We have to handle that case as well.
+	var unknownResult any
+	err = json.Unmarshal(*responseBody, &unknownResult)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to determine type of %s response: %w", humanReadableGetSection, err))
+	}
+
+	_, ok := unknownResult.([]any)
+	if ok {
+		return nil, NewRPCError(fmt.Errorf("incorrect config (%q) and/or section (%q): result from LuCI: %s", config, section, *responseBody))
+	}
+
+	var result Options
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableGetSection, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) hostname(
+	ctx context.Context,
+) (string, error) {
+	requestBody := jsonRPCRequestBody{
+		Method: methodHostname,
+		Params: []json.RawMessage{},
+	}
+	responseBody, err := t.jsonRPCClientSys.InvokeNotNull(
+		ctx,
+		humanReadableHostname,
+		requestBody,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableHostname, err)
+	}
+
+	var result string
+	err = json.Unmarshal(responseBody, &result)
+	if err != nil {
+		return "", NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableHostname, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) listSections(
+	ctx context.Context,
+	config string,
+	sectionType string,
+) ([]Options, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableListSections, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodGetAll,
+		Params: []json.RawMessage{
+			marshalledConfig,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableListSections,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableListSections, err)
+	}
+
+	if responseBody == nil {
+		return nil, fmt.Errorf("unable to %s: could not find config %q", humanReadableListSections, config)
+	}
+
+	var sections map[string]Options
+	err = json.Unmarshal(*responseBody, &sections)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableListSections, err))
+	}
+
+	return orderSections(sections, sectionType)
+}
+
+func (t luciRPCTransport) networkDevices(
+	ctx context.Context,
+) ([]string, error) {
+	requestBody := jsonRPCRequestBody{
+		Method: methodNetDevices,
+		Params: []json.RawMessage{},
+	}
+	responseBody, err := t.jsonRPCClientSys.Invoke(
+		ctx,
+		humanReadableNetworkDevices,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableNetworkDevices, err)
+	}
+
+	result := []string{}
+	if responseBody == nil {
+		return result, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableNetworkDevices, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) readFile(
+	ctx context.Context,
+	path string,
+) ([]byte, error) {
+	marshalledPath, err := json.Marshal(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize path %q for %s: %w", path, humanReadableReadFile, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodReadFile,
+		Params: []json.RawMessage{
+			marshalledPath,
+		},
+	}
+	responseBody, err := t.jsonRPCClientFS.Invoke(
+		ctx,
+		humanReadableReadFile,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableReadFile, err)
+	}
+
+	// The result is the base64 encoded contents of the file,
+	// or `null` if the file could not be opened.
+	if responseBody == nil {
+		return nil, NewFileNotFoundError(path)
+	}
+
+	var encoded string
+	err = json.Unmarshal(*responseBody, &encoded)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableReadFile, err))
+	}
+
+	result, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to decode %s response: %w", humanReadableReadFile, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) reboot(
+	ctx context.Context,
+) (bool, error) {
+	requestBody := jsonRPCRequestBody{
+		Method: methodReboot,
+		Params: []json.RawMessage{},
+	}
+	responseBody, err := t.jsonRPCClientSys.Invoke(
+		ctx,
+		humanReadableReboot,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableReboot, err)
+	}
+
+	return parseCommandResult(humanReadableReboot, responseBody)
+}
+
+func (t luciRPCTransport) reorderSection(
+	ctx context.Context,
+	config string,
+	section string,
+	index int,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableReorderSection, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableReorderSection, err)
+	}
+
+	marshalledIndex, err := json.Marshal(index)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize index %d for %s: %w", index, humanReadableReorderSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodReorder,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSection,
+			marshalledIndex,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableReorderSection,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableReorderSection, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableReorderSection, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) revertChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableRevertChanges, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodRevert,
+		Params: []json.RawMessage{
+			marshalledConfig,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableRevertChanges,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableRevertChanges, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableRevertChanges, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) serviceAction(
+	ctx context.Context,
+	service string,
+	action ServiceAction,
+) (bool, error) {
+	var method string
+	switch action {
+	case ServiceReload:
+		method = methodInitReload
+
+	case ServiceRestart:
+		method = methodInitRestart
+
+	default:
+		return false, fmt.Errorf("unable to %s: unknown action %q", humanReadableServiceAction, action)
+	}
+
+	marshalledService, err := json.Marshal(service)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize service %q for %s: %w", service, humanReadableServiceAction, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: method,
+		Params: []json.RawMessage{
+			marshalledService,
+		},
+	}
+	responseBody, err := t.jsonRPCClientSys.Invoke(
+		ctx,
+		humanReadableServiceAction,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableServiceAction, err)
+	}
+
+	// The result is `null` if the service does not exist.
+	return parseCommandResult(humanReadableServiceAction, responseBody)
+}
+
+func (t luciRPCTransport) showChanges(
+	ctx context.Context,
+	config string,
+) ([][]string, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableShowChanges, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodChanges,
+		Params: []json.RawMessage{
+			marshalledConfig,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableShowChanges,
+		requestBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableShowChanges, err)
+	}
+
+	result := [][]string{}
+	if responseBody == nil {
+		return result, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableShowChanges, err))
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) statFile(
+	ctx context.Context,
+	path string,
+) (FileInfo, error) {
+	marshalledPath, err := json.Marshal(path)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("unable to serialize path %q for %s: %w", path, humanReadableStatFile, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodStat,
+		Params: []json.RawMessage{
+			marshalledPath,
+		},
+	}
+	responseBody, err := t.jsonRPCClientFS.Invoke(
+		ctx,
+		humanReadableStatFile,
+		requestBody,
+	)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("unable to %s: %w", humanReadableStatFile, err)
+	}
+
+	// The result is an object describing the file.
+	// If the file does not exist,
+	// it is either `null` or an array of `null`, the error message, and the error code.
+	if responseBody == nil || !bytes.HasPrefix(bytes.TrimSpace(*responseBody), []byte("{")) {
+		return FileInfo{}, NewFileNotFoundError(path)
+	}
+
+	var stat struct {
+		GID     int    `json:"gid"`
+		Modestr string `json:"modestr"`
+		Mtime   int64  `json:"mtime"`
+		Size    int64  `json:"size"`
+		Type    string `json:"type"`
+		UID     int    `json:"uid"`
+	}
+	err = json.Unmarshal(*responseBody, &stat)
+	if err != nil {
+		return FileInfo{}, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableStatFile, err))
+	}
+
+	result := FileInfo{
+		GroupID:      stat.GID,
+		Mode:         stat.Modestr,
+		ModifiedTime: time.Unix(stat.Mtime, 0),
+		Size:         stat.Size,
+		Type:         stat.Type,
+		UserID:       stat.UID,
+	}
+	return result, nil
+}
+
+func (t luciRPCTransport) updateSection(
+	ctx context.Context,
+	config string,
+	section string,
+	options Options,
+) (bool, error) {
+	marshalledConfig, err := json.Marshal(config)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize config %q for %s: %w", config, humanReadableUpdateSection, err)
+	}
+
+	marshalledSection, err := json.Marshal(section)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize section %q for %s: %w", section, humanReadableUpdateSection, err)
+	}
+
+	marshalledOptions, err := json.Marshal(options)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize options %q for %s: %w", options, humanReadableCreateSection, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodTSet,
+		Params: []json.RawMessage{
+			marshalledConfig,
+			marshalledSection,
+			marshalledOptions,
+		},
+	}
+	responseBody, err := t.jsonRPCClientUCI.Invoke(
+		ctx,
+		humanReadableUpdateSection,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableUpdateSection, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableUpdateSection, err))
+	}
+
+	if !result {
+		return false, fmt.Errorf("unable to %s: it is not clear why this happened", humanReadableUpdateSection)
+	}
+
+	return result, nil
+}
+
+func (t luciRPCTransport) writeFile(
+	ctx context.Context,
+	path string,
+	data []byte,
+) (bool, error) {
+	marshalledPath, err := json.Marshal(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize path %q for %s: %w", path, humanReadableWriteFile, err)
+	}
+
+	// The data is sent base64 encoded,
+	// so files that are not text survive the trip.
+	marshalledData, err := json.Marshal(base64.StdEncoding.EncodeToString(data))
+	if err != nil {
+		return false, fmt.Errorf("unable to serialize data for %s: %w", humanReadableWriteFile, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodWriteFile,
+		Params: []json.RawMessage{
+			marshalledPath,
+			marshalledData,
+		},
+	}
+	responseBody, err := t.jsonRPCClientFS.Invoke(
+		ctx,
+		humanReadableWriteFile,
+		requestBody,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableWriteFile, err)
+	}
+
+	// The result can be `true` to indicate success,
+	// or `null` to indicate failure.
+	var result bool
+	if responseBody == nil {
+		return false, nil
+	}
+
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableWriteFile, err))
+	}
+
+	return result, nil
+}
+
+func newLuCIRPCTransport(
+	ctx context.Context,
+	httpClient http.Client,
+	scheme string,
+	host string,
+	username string,
+	credentials credentials,
+	retryPolicy retryPolicy,
+) (luciRPCTransport, error) {
+	address := url.URL{
+		Host:   host,
+		Path:   pathAuth,
+		Scheme: scheme,
+	}
+	jsonRPCClientAuth := jsonRPCNewClient(
+		httpClient,
+		address,
+		retryPolicy,
+		nil,
+	)
+	login := func(ctx context.Context, password string) (string, error) {
+		return luciRPCLogin(
+			ctx,
+			jsonRPCClientAuth,
+			username,
+			password,
+		)
+	}
+	session, err := credentials.newSession(ctx, login)
+	if err != nil {
+		return luciRPCTransport{}, err
+	}
+
+	addressFS := url.URL{
+		Host:   host,
+		Path:   pathFS,
+		Scheme: scheme,
+	}
+	jsonRPCClientFS := jsonRPCNewClient(
+		httpClient,
+		addressFS,
+		retryPolicy,
+		session,
+	)
+	addressSys := url.URL{
+		Host:   host,
+		Path:   pathSys,
+		Scheme: scheme,
+	}
+	jsonRPCClientSys := jsonRPCNewClient(
+		httpClient,
+		addressSys,
+		retryPolicy,
+		session,
+	)
+	addressUCI := url.URL{
+		Host:   host,
+		Path:   pathUCI,
+		Scheme: scheme,
+	}
+	jsonRPCClientUCI := jsonRPCNewClient(
+		httpClient,
+		addressUCI,
+		retryPolicy,
+		session,
+	)
+	transport := luciRPCTransport{
+		jsonRPCClientFS:  jsonRPCClientFS,
+		jsonRPCClientSys: jsonRPCClientSys,
+		jsonRPCClientUCI: jsonRPCClientUCI,
+	}
+	return transport, nil
+}
+
+type jsonRPCClient struct {
+	address     url.URL
+	client      http.Client
+	retryPolicy retryPolicy
+	session     *session
+}
+
+func (c jsonRPCClient) InvokeNotNull(
+	ctx context.Context,
+	humanReadableMethod string,
+	requestBody jsonRPCRequestBody,
+) (json.RawMessage, error) {
+	result, err := c.Invoke(
+		ctx,
+		humanReadableMethod,
+		requestBody,
+	)
+	if err != nil {
+		return json.RawMessage{}, err
+	}
+
+	if result == nil {
+		return nil, NewProtocolError(fmt.Errorf("invalid %s response: expected either an error or a result, got neither", humanReadableMethod))
+	}
+
+	return *result, nil
+}
+
+// Invoke sends the request to the JSON-RPC endpoint.
+//
+// If the client is tied to a session,
+// the session's token is added to the request.
+// When the device reports that the token has expired,
+// we login again and retry the request exactly once.
+//
+// Temporary problems sending the request are retried according to the client's [retryPolicy].
+func (c jsonRPCClient) Invoke(
+	ctx context.Context,
+	humanReadableMethod string,
+	requestBody jsonRPCRequestBody,
+) (*json.RawMessage, error) {
+	if c.session == nil {
+		return c.invokeWithRetries(
+			ctx,
+			humanReadableMethod,
+			c.address,
+			requestBody,
+		)
+	}
+
+	token := c.session.currentToken()
+	result, err := c.invokeWithRetries(
+		ctx,
+		humanReadableMethod,
+		c.addressWithToken(token),
+		requestBody,
+	)
+	if !errors.Is(err, errSessionExpired) {
+		return result, err
+	}
+
+	token, err = c.session.refresh(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("session expired during %s: %w", humanReadableMethod, err)
+	}
+
+	result, err = c.invokeWithRetries(
+		ctx,
+		humanReadableMethod,
+		c.addressWithToken(token),
+		requestBody,
+	)
+	if errors.Is(err, errSessionExpired) {
+		// We just logged in, so the session is not the problem.
+		return nil, NewAccessDeniedError(err)
+	}
+
+	return result, err
+}
+
+func (c jsonRPCClient) addressWithToken(
+	token string,
+) url.URL {
+	query := url.Values{}
+	query.Add(queryKeyAuth, token)
+	address := c.address
+	address.RawQuery = query.Encode()
+	return address
+}
+
+func (c jsonRPCClient) invoke(
+	ctx context.Context,
+	humanReadableMethod string,
+	address url.URL,
+	requestBody jsonRPCRequestBody,
+) (*json.RawMessage, error) {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	err := encoder.Encode(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("problem encoding %s request: %w", humanReadableMethod, err)
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		address.String(),
+		&buffer,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating %s request: %w", humanReadableMethod, err)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, newSendError(humanReadableMethod, err)
+	}
+
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+
+	case http.StatusForbidden:
+		// LuCI only answers with a 403 when the token does not belong to a valid session.
+		return nil, fmt.Errorf("expected %s to respond with a 200: got %s: %w", humanReadableMethod, response.Status, errSessionExpired)
+
+	default:
+		return nil, newResponseStatusError(humanReadableMethod, response)
+	}
+
+	var responseBody jsonRPCResponseBody
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&responseBody)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableMethod, err))
+	}
+
+	if responseBody.Error != nil {
+		if isAccessDeniedMessage(*responseBody.Error) {
+			return nil, NewAccessDeniedError(fmt.Errorf("%s error: %s", humanReadableMethod, *responseBody.Error))
+		}
+
+		return nil, NewRPCError(fmt.Errorf("%s error: %s", humanReadableMethod, *responseBody.Error))
+	}
+
+	return responseBody.Result, nil
+}
+
+func (c jsonRPCClient) invokeWithRetries(
+	ctx context.Context,
+	humanReadableMethod string,
+	address url.URL,
+	requestBody jsonRPCRequestBody,
+) (*json.RawMessage, error) {
+	return retry(
+		ctx,
+		c.retryPolicy,
+		humanReadableMethod,
+		isIdempotentMethod(requestBody.Method),
+		func() (*json.RawMessage, error) {
+			return c.invoke(
+				ctx,
+				humanReadableMethod,
+				address,
+				requestBody,
+			)
+		},
+	)
+}
+
+func jsonRPCNewClient(
+	httpClient http.Client,
+	address url.URL,
+	retryPolicy retryPolicy,
+	session *session,
+) jsonRPCClient {
+	return jsonRPCClient{
+		address:     address,
+		client:      httpClient,
+		retryPolicy: retryPolicy,
+		session:     session,
+	}
+}
+
+type jsonRPCRequestBody struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type jsonRPCResponseBody struct {
+	Error  *string          `json:"error"`
+	Result *json.RawMessage `json:"result"`
+}
+
+// isAccessDeniedMessage checks if a JSON-RPC error is from the session not being allowed to make the call.
+// LuCI rejects an expired session before the call is made (with a 403),
+// so this is never from an expired session,
+// and logging in again would not help.
+func isAccessDeniedMessage(
+	message string,
+) bool {
+	return strings.Contains(strings.ToLower(message), "access denied")
+}
+
+// isIdempotentMethod checks if the JSON-RPC `method` is safe to send again after the device might have seen it.
+// Only methods that read from the device are,
+// along with logging in,
+// which only hands out another session.
+// Anything that changes the device is sent again only when it never reached the device:
+// committing or reverting again acts on whatever was staged in between,
+// restarting a service again restarts it twice,
+// and deleting or adding a section again either fails or adds another one.
+func isIdempotentMethod(
+	method string,
+) bool {
+	switch method {
+	case methodChanges, methodGetAll, methodHostname, methodLogin, methodNetDevices, methodReadFile, methodStat:
+		return true
+
+	default:
+		return false
+	}
+}
+
+func luciRPCLogin(
+	ctx context.Context,
+	jsonRPCClientAuth jsonRPCClient,
+	username string,
+	password string,
+) (string, error) {
+	marshalledUsername, err := json.Marshal(username)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize username for %s: %w", humanReadableLogin, err)
+	}
+
+	marshalledPassword, err := json.Marshal(password)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize password for %s: %w", humanReadableLogin, err)
+	}
+
+	requestBody := jsonRPCRequestBody{
+		Method: methodLogin,
+		Params: []json.RawMessage{
+			marshalledUsername,
+			marshalledPassword,
+		},
+	}
+	responseBody, err := jsonRPCClientAuth.InvokeNotNull(
+		ctx,
+		humanReadableLogin,
+		requestBody,
+	)
+	if err != nil {
+		return "", NewAuthenticationError(fmt.Errorf("unable to %s: %w", humanReadableLogin, err))
+	}
+
+	var token string
+	err = json.Unmarshal(responseBody, &token)
+	if err != nil {
+		return "", NewAuthenticationError(NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableLogin, err)))
+	}
+
+	return token, nil
+}
+
+// parseCommandResult checks the result of a `sys` method that runs a command on the device.
+// Depending on the version of LuCI,
+// the result can be `true` to indicate success,
+// or the exit code of the command.
+// It is `null` if the command could not be run at all.
+func parseCommandResult(
+	humanReadableMethod string,
+	responseBody *json.RawMessage,
+) (bool, error) {
+	if responseBody == nil {
+		return false, nil
+	}
+
+	var result any
+	err := json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableMethod, err))
+	}
+
+	switch result := result.(type) {
+	case bool:
+		return result, nil
+
+	case float64:
+		return result == 0, nil
+
+	default:
+		return false, NewProtocolError(fmt.Errorf("unable to parse %s response: unexpected result %v", humanReadableMethod, result))
+	}
+}
+
+// parseConfirmResult checks the result of confirming changes.
+// The result can be `true` to indicate success,
+// or `false` or `null` when there was nothing to confirm with the token
+// (e.g. because the device already rolled back).
+func parseConfirmResult(
+	responseBody *json.RawMessage,
+) error {
+	var result bool
+	if responseBody != nil {
+		err := json.Unmarshal(*responseBody, &result)
+		if err != nil {
+			return NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableConfirmChanges, err))
+		}
+	}
+
+	if !result {
+		return fmt.Errorf("unable to %s, the device will roll back the changes: the device has no changes waiting for this token", humanReadableConfirmChanges)
+	}
+
+	return nil
+}
@@ -326,9 +326,22 @@ func (o *optionInteger) MarshalJSON() ([]byte, error) {
 //
 // Integers are stored in UCI as a string.
 // We try to parse one of these out of the raw JSON by first making sure it's a valid string.
+//
+// However, integer metadata (like `.index`) from LuCI's JSON-RPC API is returned as a JSON number.
+// We first try to parse the value as a normal JSON number,
+// in case it happens to be metadata.
 func (o *optionInteger) UnmarshalJSON(raw []byte) error {
+	// First try to parse as a JSON number.
+	// We could be dealing with metadata.
+	var number int
+	err := json.Unmarshal(raw, &number)
+	if err == nil {
+		o.value = number
+		return nil
+	}
+
 	var intish string
-	err := json.Unmarshal(raw, &intish)
+	err = json.Unmarshal(raw, &intish)
 	if err != nil {
 		return fmt.Errorf("could not convert to a string: %w", err)
 	}
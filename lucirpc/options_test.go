@@ -246,7 +246,8 @@ func TestOptionsUnmarshalJSON(t *testing.T) {
 				"value1",
 				"value2",
 				"value3"
-			]
+			],
+			"option16": 2
 		}`
 
 		// When
@@ -273,6 +274,7 @@ func TestOptionsUnmarshalJSON(t *testing.T) {
 				"value2",
 				"value3",
 			}),
+			"option16": lucirpc.Integer(2),
 		}
 		assert.NilError(t, err)
 		assert.DeepEqual(t, options, want)
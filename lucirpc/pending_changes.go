@@ -0,0 +1,65 @@
+package lucirpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// PendingChangesReporter is told about changes that were already staged in a config,
+// when the [PendingChangesPolicy] lets the [Client] change the config anyway.
+// The `policy` says what happened to the changes.
+type PendingChangesReporter func(pending PendingChangesError, policy PendingChangesPolicy)
+
+type pendingChangesReporterKey struct{}
+
+// ContextWithPendingChangesReporter returns a copy of `ctx` that reports pending changes to `report`.
+// Changes made with the returned context call `report` when they find changes they did not stage,
+// and either commit them ([PendingChangesWarn]) or revert them ([PendingChangesRevert]).
+func ContextWithPendingChangesReporter(
+	ctx context.Context,
+	report PendingChangesReporter,
+) context.Context {
+	return context.WithValue(ctx, pendingChangesReporterKey{}, report)
+}
+
+// checkPendingChanges looks for changes already staged in the `config`,
+// and handles them according to the [PendingChangesPolicy].
+// The result is whether the `config` is clean,
+// meaning nothing else is staged in it.
+// The lock for the `config` must be held,
+// and the [Client] must not have staged any changes of its own.
+func (c *Client) checkPendingChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	changes, err := c.transport.showChanges(ctx, config)
+	if err != nil {
+		return false, fmt.Errorf("unable to check for pending changes: %w", err)
+	}
+
+	if len(changes) == 0 {
+		return true, nil
+	}
+
+	clean := false
+	pending := NewPendingChangesError(config, changes)
+	switch c.pendingChanges {
+	case PendingChangesFail:
+		return false, pending
+
+	case PendingChangesRevert:
+		_, err := c.transport.revertChanges(ctx, config)
+		if err != nil {
+			return false, fmt.Errorf("unable to %s that were pending: %w", humanReadableRevertChanges, err)
+		}
+
+		clean = true
+	}
+
+	report, ok := ctx.Value(pendingChangesReporterKey{}).(PendingChangesReporter)
+	if ok {
+		report(pending, c.pendingChanges)
+	}
+
+	return clean, nil
+}
@@ -0,0 +1,134 @@
+package lucirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// retryPolicy decides whether a failed request is sent again, and how long to wait before doing so.
+//
+// Only temporary problems are retried:
+// the request not making it to the device, timeouts, and server errors.
+// Whether it is safe to retry also depends on the request.
+// Sending a read (or a write that sets the same values) more than once is harmless,
+// so those are retried for any temporary problem.
+// Other writes are only retried if the device could not have seen the request,
+// otherwise we might do the same thing twice.
+type retryPolicy struct {
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	retries        int
+}
+
+// backoff returns how long to wait before the given retry `attempt` (starting at 0).
+// The wait doubles with each attempt, up to the maximum.
+func (p retryPolicy) backoff(
+	attempt int,
+) time.Duration {
+	result := p.initialBackoff
+	for i := 0; i < attempt; i++ {
+		result *= 2
+		if p.maxBackoff > 0 && result >= p.maxBackoff {
+			return p.maxBackoff
+		}
+	}
+
+	return result
+}
+
+// temporaryError is a problem that might go away if the request is sent again.
+type temporaryError struct {
+	err error
+
+	// sent is whether the request might have made it to the device.
+	sent bool
+}
+
+func (e temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e temporaryError) Unwrap() error {
+	return e.err
+}
+
+// newResponseStatusError constructs an error for an unexpected HTTP status.
+// Statuses that mean the device is busy or unavailable are temporary.
+func newResponseStatusError(
+	humanReadableMethod string,
+	response *http.Response,
+) error {
+	err := NewTransportError(fmt.Errorf("expected %s to respond with a 200: got %s", humanReadableMethod, response.Status))
+	switch response.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return temporaryError{
+			err:  err,
+			sent: true,
+		}
+	}
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return temporaryError{
+			err:  err,
+			sent: true,
+		}
+	}
+
+	return err
+}
+
+// newSendError constructs an error for a request that could not be sent.
+// These are all temporary,
+// but only a failure to connect means the device never saw the request.
+func newSendError(
+	humanReadableMethod string,
+	err error,
+) error {
+	var opError *net.OpError
+	sent := !errors.As(err, &opError) || opError.Op != "dial"
+	return temporaryError{
+		err:  NewTransportError(fmt.Errorf("problem sending request to %s: %w", humanReadableMethod, err)),
+		sent: sent,
+	}
+}
+
+// retry calls `call` until it succeeds, fails with a problem that should not be retried, or runs out of retries.
+func retry[Result any](
+	ctx context.Context,
+	policy retryPolicy,
+	humanReadableMethod string,
+	idempotent bool,
+	call func() (Result, error),
+) (Result, error) {
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		var temporary temporaryError
+		if err == nil || !errors.As(err, &temporary) || attempt >= policy.retries {
+			return result, err
+		}
+
+		if temporary.sent && !idempotent {
+			return result, err
+		}
+
+		wait := policy.backoff(attempt)
+		tflog.Warn(ctx, fmt.Sprintf("Retrying %s after a temporary problem", humanReadableMethod), map[string]any{
+			"attempt": attempt + 1,
+			"backoff": wait.String(),
+			"error":   err.Error(),
+			"retries": policy.retries,
+		})
+		select {
+		case <-time.After(wait):
+
+		case <-ctx.Done():
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestClientRetries(t *testing.T) {
+	t.Run("retries reads after a temporary problem", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var attempts int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": {
+					".name": "testing"
+				}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
+		)
+		defer close()
+
+		// When
+		got, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".name": lucirpc.String("testing"),
+		})
+		assert.Equal(t, attempts, 3)
+	})
+
+	t.Run("gives up after running out of retries", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var attempts int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected show changes to respond with a 200: got 502 Bad Gateway")
+		assert.Equal(t, attempts, 3)
+	})
+
+	t.Run("does not retry other problems", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var attempts int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusNotFound)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected get section to respond with a 200: got 404 Not Found")
+		assert.Equal(t, attempts, 1)
+	})
+
+	t.Run("does not retry deletes the device might have seen", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var deletes int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "delete" {
+				deletes++
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected delete section to respond with a 200: got 504 Gateway Timeout")
+		assert.Equal(t, deletes, 1)
+	})
+
+	t.Run("does not retry commits the device might have seen", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commits int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string `json:"method"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			if body.Method == "commit" {
+				commits++
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"result": true
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			withoutPendingChanges(http.HandlerFunc(handle)),
+			lucirpc.WithRetries(2, time.Millisecond, time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.CommitChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected commit changes to respond with a 200: got 504 Gateway Timeout")
+		assert.Equal(t, commits, 1)
+	})
+
+	t.Run("times out slow requests", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprintf(w, `{
+				"result": {}
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+			lucirpc.WithRequestTimeout(10*time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "problem sending request to get section")
+	})
+}
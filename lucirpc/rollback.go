@@ -0,0 +1,70 @@
+package lucirpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// rollbackConfirmInterval is how long to wait between attempts to confirm changes,
+	// while the device is not reachable.
+	rollbackConfirmInterval = time.Second
+
+	// rollbackHoldoff is how long to wait for services to reload before confirming changes.
+	// This is the same default LuCI uses.
+	rollbackHoldoff = 4 * time.Second
+)
+
+// rollback applies changes in a way that the device can undo.
+//
+// Applying changes is not scoped to a single config,
+// and the device only keeps track of one pending rollback at a time.
+// So, only one apply can be in progress at any given time.
+type rollback struct {
+	holdoff   time.Duration
+	mutex     sync.Mutex
+	timeout   time.Duration
+	transport rollbackTransport
+}
+
+// commitChanges applies every staged change (including those for `config`),
+// and confirms them if the device is still reachable.
+func (r *rollback) commitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	err := r.transport.applyChangesWithRollback(
+		ctx,
+		r.timeout,
+		r.holdoff,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// rollbackTransport is implemented by any [transport] that can apply changes with a rollback.
+type rollbackTransport interface {
+	applyChangesWithRollback(ctx context.Context, timeout time.Duration, holdoff time.Duration) error
+}
+
+func newRollback(
+	transport rollbackTransport,
+	timeout time.Duration,
+) *rollback {
+	holdoff := rollbackHoldoff
+	if holdoff > timeout/2 {
+		holdoff = timeout / 2
+	}
+
+	return &rollback{
+		holdoff:   holdoff,
+		timeout:   timeout,
+		transport: transport,
+	}
+}
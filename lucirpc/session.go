@@ -0,0 +1,85 @@
+package lucirpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	errSessionExpired = errors.New("session expired")
+)
+
+// session keeps track of the token used to authenticate with the device.
+//
+// rpcd expires sessions after a period of time,
+// so the token can become invalid while the client is still in use.
+// The credentials are captured by `authenticate` so we can login again when that happens.
+// The token is shared between copies of a [Client],
+// so access to it has to be synchronized.
+type session struct {
+	authenticate func(context.Context) (string, error)
+	mutex        sync.RWMutex
+	token        string
+}
+
+// currentToken returns the token that should be used for the next request.
+func (s *session) currentToken() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.token
+}
+
+// refresh logs in again if the `expiredToken` is still the current token.
+//
+// Multiple requests can notice an expired session at the same time.
+// Only the first one should login again,
+// the rest can use the token it got back.
+func (s *session) refresh(
+	ctx context.Context,
+	expiredToken string,
+) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.token != expiredToken {
+		return s.token, nil
+	}
+
+	token, err := s.authenticate(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	return s.token, nil
+}
+
+func newSession(
+	ctx context.Context,
+	authenticate func(context.Context) (string, error),
+) (*session, error) {
+	token, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &session{
+		authenticate: authenticate,
+		token:        token,
+	}
+	return result, nil
+}
+
+// newSessionWithToken uses the `token` of a session that already exists, instead of logging in.
+// There are no credentials to login again with,
+// so once the session expires every request fails with an [AuthenticationError].
+func newSessionWithToken(
+	token string,
+) *session {
+	return &session{
+		authenticate: func(context.Context) (string, error) {
+			return "", NewAuthenticationError(errSessionTokenExpired)
+		},
+		token: token,
+	}
+}
@@ -0,0 +1,792 @@
+package lucirpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	sshAgentSocketEnvironmentVariable = "SSH_AUTH_SOCK"
+
+	sshInitScriptDirectory = "/etc/init.d"
+
+	// sshPlaceholderValue is set on an option right before deleting it.
+	// `uci delete` fails if the option does not exist,
+	// but setting it first means there is always something to delete.
+	sshPlaceholderValue = "x"
+
+	uciMessageNotFound = "Entry not found"
+)
+
+// SSHOptions describes how to connect to the device over SSH.
+type SSHOptions struct {
+	// Agent uses the keys held by the SSH agent listening on `SSH_AUTH_SOCK`.
+	Agent bool
+
+	// InsecureIgnoreHostKey disables verification of the device's host key.
+	InsecureIgnoreHostKey bool
+
+	// KnownHostsFile is the path to the known_hosts file the device's host key is verified against.
+	// Defaults to `~/.ssh/known_hosts`.
+	KnownHostsFile string
+
+	// Password is used for password (and keyboard-interactive) authentication.
+	Password string
+
+	// PrivateKey is the PEM-encoded private key to authenticate with.
+	// Keys protected by a passphrase are not supported,
+	// add them to the SSH agent instead.
+	PrivateKey []byte
+}
+
+// sshTransport talks to UCI by running the `uci` command line tool over SSH.
+// This works on devices without any HTTP server,
+// as long as they run an SSH server (e.g. dropbear).
+//
+// Staged changes are written with `uci batch`,
+// so every change is a single command no matter how many options it sets.
+type sshTransport struct {
+	connection     *sshConnection
+	requestTimeout time.Duration
+	retryPolicy    retryPolicy
+}
+
+func (t sshTransport) addSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	options Options,
+) (string, error) {
+	// The new section is the last one of its type,
+	// so its options can be set before we know its name.
+	commands := []string{
+		fmt.Sprintf("add %s %s", uciQuote(config), uciQuote(sectionType)),
+	}
+	optionCommands, err := uciSetOptionCommands(
+		config,
+		fmt.Sprintf("@%s[-1]", sectionType),
+		options,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableAddSection, err)
+	}
+
+	commands = append(commands, optionCommands...)
+	output, err := t.batch(ctx, humanReadableAddSection, false, commands)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableAddSection, err)
+	}
+
+	section, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if section == "" {
+		return "", NewProtocolError(fmt.Errorf("unable to %s: no section was returned", humanReadableAddSection))
+	}
+
+	return section, nil
+}
+
+// capabilities lists the configs and reads the release with the usual command line tools.
+// There are no RPC modules over SSH.
+func (t sshTransport) capabilities(
+	ctx context.Context,
+) (Capabilities, error) {
+	result := Capabilities{}
+	output, err := t.run(ctx, humanReadableListConfigs, true, capabilitiesListConfigsCommand, "")
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil {
+		result.Configs = parseConfigs(output)
+	}
+
+	output, err = t.run(ctx, humanReadableReadRelease, true, fmt.Sprintf("cat %s", capabilitiesReleaseFile), "")
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil {
+		result.Release = parseRelease(output)
+	}
+
+	return result, nil
+}
+
+func (t sshTransport) commitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	_, err := t.run(
+		ctx,
+		humanReadableCommitChanges,
+		false,
+		fmt.Sprintf("uci commit %s", uciQuote(config)),
+		"",
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCommitChanges, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) createSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	section string,
+	options Options,
+) (bool, error) {
+	commands := []string{
+		fmt.Sprintf("set %s", uciQuote(fmt.Sprintf("%s.%s=%s", config, section, sectionType))),
+	}
+	optionCommands, err := uciSetOptionCommands(config, section, options)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCreateSection, err)
+	}
+
+	commands = append(commands, optionCommands...)
+	_, err = t.batch(ctx, humanReadableCreateSection, false, commands)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCreateSection, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) deleteOptions(
+	ctx context.Context,
+	config string,
+	section string,
+	options []string,
+) (bool, error) {
+	commands := []string{}
+	for _, option := range options {
+		commands = append(commands, uciDeleteOptionCommands(config, section, option)...)
+	}
+
+	_, err := t.batch(ctx, humanReadableDeleteOptions, false, commands)
+	if isUCINotFound(err) {
+		return false, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteOptions, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) deleteSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (bool, error) {
+	commands := []string{
+		fmt.Sprintf("delete %s", uciQuote(fmt.Sprintf("%s.%s", config, section))),
+	}
+	_, err := t.batch(ctx, humanReadableDeleteSection, false, commands)
+	if isUCINotFound(err) {
+		return false, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteSection, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) getSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (Options, error) {
+	sections, err := t.exportSections(ctx, humanReadableGetSection, config)
+	if isUCINotFound(err) {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableGetSection, err)
+	}
+
+	options, ok := sections[section]
+	if !ok {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	return options, nil
+}
+
+func (t sshTransport) listSections(
+	ctx context.Context,
+	config string,
+	sectionType string,
+) ([]Options, error) {
+	sections, err := t.exportSections(ctx, humanReadableListSections, config)
+	if isUCINotFound(err) {
+		return nil, fmt.Errorf("unable to %s: could not find config %q", humanReadableListSections, config)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableListSections, err)
+	}
+
+	return orderSections(sections, sectionType)
+}
+
+func (t sshTransport) reorderSection(
+	ctx context.Context,
+	config string,
+	section string,
+	index int,
+) (bool, error) {
+	commands := []string{
+		fmt.Sprintf("reorder %s", uciQuote(fmt.Sprintf("%s.%s=%d", config, section, index))),
+	}
+	_, err := t.batch(ctx, humanReadableReorderSection, false, commands)
+	if isUCINotFound(err) {
+		return false, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableReorderSection, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) revertChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	_, err := t.run(
+		ctx,
+		humanReadableRevertChanges,
+		false,
+		fmt.Sprintf("uci revert %s", uciQuote(config)),
+		"",
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableRevertChanges, err)
+	}
+
+	return true, nil
+}
+
+// serviceAction runs the init script directly.
+// Like LuCI, the result is false if the script fails or does not exist.
+func (t sshTransport) serviceAction(
+	ctx context.Context,
+	service string,
+	action ServiceAction,
+) (bool, error) {
+	_, err := t.run(
+		ctx,
+		humanReadableServiceAction,
+		false,
+		fmt.Sprintf("%s %s", uciQuote(fmt.Sprintf("%s/%s", sshInitScriptDirectory, service)), uciQuote(string(action))),
+		"",
+	)
+	var commandError sshCommandError
+	if errors.As(err, &commandError) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableServiceAction, err)
+	}
+
+	return true, nil
+}
+
+func (t sshTransport) showChanges(
+	ctx context.Context,
+	config string,
+) ([][]string, error) {
+	output, err := t.run(
+		ctx,
+		humanReadableShowChanges,
+		true,
+		fmt.Sprintf("uci changes %s", uciQuote(config)),
+		"",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableShowChanges, err)
+	}
+
+	result, err := parseUCIChanges(config, output)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s output: %w", humanReadableShowChanges, err))
+	}
+
+	return result, nil
+}
+
+func (t sshTransport) updateSection(
+	ctx context.Context,
+	config string,
+	section string,
+	options Options,
+) (bool, error) {
+	commands, err := uciSetOptionCommands(config, section, options)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableUpdateSection, err)
+	}
+
+	_, err = t.batch(ctx, humanReadableUpdateSection, false, commands)
+	if isUCINotFound(err) {
+		return false, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableUpdateSection, err)
+	}
+
+	return true, nil
+}
+
+// batch stages the `commands` with a single `uci batch`.
+// Each command is written the same way as on the command line, without the leading `uci`.
+func (t sshTransport) batch(
+	ctx context.Context,
+	humanReadableCommand string,
+	idempotent bool,
+	commands []string,
+) (string, error) {
+	if len(commands) == 0 {
+		return "", nil
+	}
+
+	return t.run(
+		ctx,
+		humanReadableCommand,
+		idempotent,
+		"uci batch",
+		strings.Join(commands, "\n")+"\n",
+	)
+}
+
+// exportSections reads every section of the `config`, keyed by name.
+//
+// `uci show` cannot tell a list with one value apart from an option,
+// so the config is read with `uci export` instead.
+// That either names every section, or leaves anonymous sections unnamed.
+// We need both the names and which sections are anonymous,
+// so the config is exported both ways with a single command.
+func (t sshTransport) exportSections(
+	ctx context.Context,
+	humanReadableCommand string,
+	config string,
+) (map[string]Options, error) {
+	output, err := t.run(
+		ctx,
+		humanReadableCommand,
+		true,
+		fmt.Sprintf("uci -N export %[1]s && uci -n export %[1]s", uciQuote(config)),
+		"",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseUCIExports(output)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s output: %w", humanReadableCommand, err))
+	}
+
+	return result, nil
+}
+
+// run runs the `command` on the device with the given `stdin`,
+// and returns what it wrote to stdout.
+//
+// Temporary problems running the command are retried according to the transport's [retryPolicy].
+func (t sshTransport) run(
+	ctx context.Context,
+	humanReadableCommand string,
+	idempotent bool,
+	command string,
+	stdin string,
+) (string, error) {
+	return retry(
+		ctx,
+		t.retryPolicy,
+		humanReadableCommand,
+		idempotent,
+		func() (string, error) {
+			ctx := ctx
+			if t.requestTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, t.requestTimeout)
+				defer cancel()
+			}
+
+			return t.connection.run(
+				ctx,
+				humanReadableCommand,
+				command,
+				stdin,
+			)
+		},
+	)
+}
+
+func newSSHTransport(
+	ctx context.Context,
+	host string,
+	username string,
+	options SSHOptions,
+	requestTimeout time.Duration,
+	retryPolicy retryPolicy,
+) (sshTransport, error) {
+	config, agent, err := newSSHClientConfig(username, options, requestTimeout)
+	if err != nil {
+		return sshTransport{}, err
+	}
+
+	connection := &sshConnection{
+		address: host,
+		agent:   agent,
+		config:  config,
+	}
+	_, err = retry(
+		ctx,
+		retryPolicy,
+		humanReadableLogin,
+		true,
+		func() (*ssh.Client, error) {
+			return connection.currentClient(ctx)
+		},
+	)
+	if err != nil {
+		return sshTransport{}, err
+	}
+
+	transport := sshTransport{
+		connection:     connection,
+		requestTimeout: requestTimeout,
+		retryPolicy:    retryPolicy,
+	}
+	return transport, nil
+}
+
+// sshCommandError represents a command that ran on the device, but exited with a failure.
+type sshCommandError struct {
+	humanReadableCommand string
+	status               int
+	stderr               string
+}
+
+func (e sshCommandError) Error() string {
+	return fmt.Sprintf("%s exited with status %d: %s", e.humanReadableCommand, e.status, strings.TrimSpace(e.stderr))
+}
+
+// sshConnection is the connection every command is run over.
+//
+// The device can drop the connection (e.g. when it reboots or the network reloads),
+// so we connect again whenever that happens.
+// The connection is shared between copies of a [Client],
+// so access to it has to be synchronized.
+type sshConnection struct {
+	address string
+	agent   *sshAgentConnection
+	client  *ssh.Client
+	config  *ssh.ClientConfig
+	mutex   sync.Mutex
+}
+
+// sshAgentConnection connects to the SSH agent for the keys to login with.
+// The agent is only needed while logging in,
+// so each login connects to it again and closes it afterwards.
+type sshAgentConnection struct {
+	conn   net.Conn
+	socket string
+}
+
+// close closes the connection to the agent, if there is one.
+func (a *sshAgentConnection) close() {
+	if a == nil || a.conn == nil {
+		return
+	}
+
+	a.conn.Close()
+	a.conn = nil
+}
+
+// signers connects to the agent and lists the keys it holds.
+// The connection is left open for the agent to sign with them.
+func (a *sshAgentConnection) signers() ([]ssh.Signer, error) {
+	a.close()
+	conn, err := net.Dial("unix", a.socket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use SSH agent: %w", err)
+	}
+
+	a.conn = conn
+	return agent.NewClient(conn).Signers()
+}
+
+// currentClient returns the open connection, connecting first if there isn't one.
+func (c *sshConnection) currentClient(
+	ctx context.Context,
+) (*ssh.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	dialer := net.Dialer{
+		Timeout: c.config.Timeout,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, newSendError(humanReadableLogin, err)
+	}
+
+	// The handshake does not wrap the error from verifying the host key,
+	// so hold onto it to tell it apart from a failure to login.
+	config := *c.config
+	var hostKeyError error
+	config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		hostKeyError = c.config.HostKeyCallback(hostname, remote, key)
+		return hostKeyError
+	}
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, c.address, &config)
+	c.agent.close()
+	if err != nil {
+		conn.Close()
+		if hostKeyError != nil {
+			return nil, fmt.Errorf("unable to verify the device's host key: %w", hostKeyError)
+		}
+
+		return nil, NewAuthenticationError(fmt.Errorf("unable to %s: %w", humanReadableLogin, err))
+	}
+
+	c.client = ssh.NewClient(clientConn, channels, requests)
+	return c.client, nil
+}
+
+// reset closes the `client` if it is still the open connection,
+// so the next command connects again.
+func (c *sshConnection) reset(
+	client *ssh.Client,
+) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.client != client {
+		return
+	}
+
+	c.client.Close()
+	c.client = nil
+}
+
+// run runs the `command` in a new session on the connection.
+func (c *sshConnection) run(
+	ctx context.Context,
+	humanReadableCommand string,
+	command string,
+	stdin string,
+) (string, error) {
+	client, err := c.currentClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		// The connection is gone, so the command never made it to the device.
+		c.reset(client)
+		return "", temporaryError{
+			err:  NewTransportError(fmt.Errorf("problem starting %s: %w", humanReadableCommand, err)),
+			sent: false,
+		}
+	}
+
+	defer session.Close()
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	session.Stdin = strings.NewReader(stdin)
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	select {
+	case err = <-done:
+
+	case <-ctx.Done():
+		return "", temporaryError{
+			err:  NewTransportError(fmt.Errorf("problem running %s: %w", humanReadableCommand, ctx.Err())),
+			sent: true,
+		}
+	}
+
+	var exitError *ssh.ExitError
+	if errors.As(err, &exitError) {
+		return "", sshCommandError{
+			humanReadableCommand: humanReadableCommand,
+			status:               exitError.ExitStatus(),
+			stderr:               stderr.String(),
+		}
+	}
+
+	if err != nil {
+		c.reset(client)
+		return "", temporaryError{
+			err:  NewTransportError(fmt.Errorf("problem running %s: %w", humanReadableCommand, err)),
+			sent: true,
+		}
+	}
+
+	return stdout.String(), nil
+}
+
+// isUCINotFound checks if `uci` failed because what it was given does not exist.
+func isUCINotFound(
+	err error,
+) bool {
+	var commandError sshCommandError
+	return errors.As(err, &commandError) &&
+		strings.Contains(commandError.stderr, uciMessageNotFound)
+}
+
+func newSSHClientConfig(
+	username string,
+	options SSHOptions,
+	timeout time.Duration,
+) (*ssh.ClientConfig, *sshAgentConnection, error) {
+	authMethods := []ssh.AuthMethod{}
+	if len(options.PrivateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(options.PrivateKey)
+		var passphraseMissingError *ssh.PassphraseMissingError
+		if errors.As(err, &passphraseMissingError) {
+			return nil, nil, fmt.Errorf("unable to use SSH private key: keys protected by a passphrase are not supported, add the key to the SSH agent instead")
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to use SSH private key: %w", err)
+		}
+
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+
+	var agentConnection *sshAgentConnection
+	if options.Agent {
+		socket := os.Getenv(sshAgentSocketEnvironmentVariable)
+		if socket == "" {
+			return nil, nil, fmt.Errorf("unable to use SSH agent: %s is not set", sshAgentSocketEnvironmentVariable)
+		}
+
+		agentConnection = &sshAgentConnection{
+			socket: socket,
+		}
+		authMethods = append(authMethods, ssh.PublicKeysCallback(agentConnection.signers))
+	}
+
+	authMethods = append(
+		authMethods,
+		ssh.Password(options.Password),
+		ssh.KeyboardInteractive(func(name string, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = options.Password
+			}
+
+			return answers, nil
+		}),
+	)
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !options.InsecureIgnoreHostKey {
+		knownHostsFile := options.KnownHostsFile
+		if knownHostsFile == "" {
+			homeDirectory, err := os.UserHomeDir()
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to find known_hosts file: %w", err)
+			}
+
+			knownHostsFile = filepath.Join(homeDirectory, ".ssh", "known_hosts")
+		}
+
+		var err error
+		hostKeyCallback, err = knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read known_hosts file: %w", err)
+		}
+	}
+
+	config := &ssh.ClientConfig{
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+		User:            username,
+	}
+	return config, agentConnection, nil
+}
+
+// uciDeleteOptionCommands are the `uci batch` commands that delete the `option`, whether or not it exists.
+func uciDeleteOptionCommands(
+	config string,
+	section string,
+	option string,
+) []string {
+	path := fmt.Sprintf("%s.%s.%s", config, section, option)
+	return []string{
+		fmt.Sprintf("set %s", uciQuote(fmt.Sprintf("%s=%s", path, sshPlaceholderValue))),
+		fmt.Sprintf("delete %s", uciQuote(path)),
+	}
+}
+
+// uciSetOptionCommands are the `uci batch` commands that set every one of the `options` on the `section`.
+// Lists replace whatever was there before.
+func uciSetOptionCommands(
+	config string,
+	section string,
+	options Options,
+) ([]string, error) {
+	names := []string{}
+	for name := range options {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	commands := []string{}
+	for _, name := range names {
+		path := fmt.Sprintf("%s.%s.%s", config, section, name)
+		values, isList, err := uciValues(options[name])
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize option %q: %w", name, err)
+		}
+
+		if !isList {
+			commands = append(commands, fmt.Sprintf("set %s", uciQuote(fmt.Sprintf("%s=%s", path, values[0]))))
+			continue
+		}
+
+		commands = append(commands, uciDeleteOptionCommands(config, section, name)...)
+		for _, value := range values {
+			commands = append(commands, fmt.Sprintf("add_list %s", uciQuote(fmt.Sprintf("%s=%s", path, value))))
+		}
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,725 @@
+package lucirpc_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gotest.tools/v3/assert"
+)
+
+func TestSSHClientAddSection(t *testing.T) {
+	t.Run("sets options on the new section before committing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commands []sshCommand
+		handle := func(command sshCommand) sshReply {
+			commands = append(commands, command)
+			if command.Command == "uci batch" {
+				return sshReply{Stdout: "cfg0a1b2c\n"}
+			}
+
+			return sshReply{}
+		}
+		client, close := authenticatedSSHClient(t, ctx, withoutPendingSSHChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.AddSection(
+			ctx,
+			"dhcp",
+			"host",
+			lucirpc.Options{
+				"ip": lucirpc.String("192.168.1.100"),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, got, "cfg0a1b2c")
+		assert.DeepEqual(t, commands, []sshCommand{
+			{
+				Command: "uci batch",
+				Stdin:   "add 'dhcp' 'host'\nset 'dhcp.@host[-1].ip=192.168.1.100'\n",
+			},
+			{
+				Command: "uci commit 'dhcp'",
+			},
+		})
+	})
+}
+
+func TestSSHClientCreateSection(t *testing.T) {
+	t.Run("stages every option in one batch", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commands []sshCommand
+		handle := func(command sshCommand) sshReply {
+			commands = append(commands, command)
+			return sshReply{}
+		}
+		client, close := authenticatedSSHClient(t, ctx, withoutPendingSSHChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"testing",
+			lucirpc.Options{
+				"auto":  lucirpc.Boolean(true),
+				"dns":   lucirpc.ListString([]string{"1.1.1.1"}),
+				"proto": lucirpc.String("it's static"),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, commands, []sshCommand{
+			{
+				Command: "uci batch",
+				Stdin: "set 'network.testing=interface'\n" +
+					"set 'network.testing.auto=1'\n" +
+					"set 'network.testing.dns=x'\n" +
+					"delete 'network.testing.dns'\n" +
+					"add_list 'network.testing.dns=1.1.1.1'\n" +
+					`set 'network.testing.proto=it'\''s static'` + "\n",
+			},
+			{
+				Command: "uci commit 'network'",
+			},
+		})
+	})
+}
+
+func TestSSHClientDeleteSection(t *testing.T) {
+	t.Run("reverts the config when the section does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commands []string
+		handle := func(command sshCommand) sshReply {
+			commands = append(commands, command.Command)
+			if command.Command == "uci batch" {
+				return sshReply{
+					Status: 1,
+					Stderr: "uci: Entry not found\n",
+				}
+			}
+
+			return sshReply{}
+		}
+		client, close := authenticatedSSHClient(t, ctx, withoutPendingSSHChanges(handle))
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(ctx, "network", "testing")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotFoundError("network", "testing"))
+		assert.DeepEqual(t, commands, []string{
+			"uci batch",
+			"uci revert 'network'",
+		})
+	})
+}
+
+func TestSSHClientGetSection(t *testing.T) {
+	t.Run("parses the exported section", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commands []string
+		handle := func(command sshCommand) sshReply {
+			commands = append(commands, command.Command)
+			return sshReply{Stdout: `package network
+
+config interface 'loopback'
+	option device 'lo'
+
+config interface 'lan'
+	option proto 'static'
+	list dns '1.1.1.1'
+	option description 'multiple
+lines'
+package network
+
+config interface 'loopback'
+	option device 'lo'
+
+config interface 'lan'
+	option proto 'static'
+	list dns '1.1.1.1'
+	option description 'multiple
+lines'
+`}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.GetSection(ctx, "network", "lan")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, lucirpc.Options{
+			".anonymous":  lucirpc.Boolean(false),
+			".index":      lucirpc.Integer(1),
+			".name":       lucirpc.String("lan"),
+			".type":       lucirpc.String("interface"),
+			"description": lucirpc.String("multiple\nlines"),
+			"dns":         lucirpc.ListString([]string{"1.1.1.1"}),
+			"proto":       lucirpc.String("static"),
+		})
+		assert.DeepEqual(t, commands, []string{
+			"uci -N export 'network' && uci -n export 'network'",
+		})
+	})
+
+	t.Run("marks anonymous sections", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(command sshCommand) sshReply {
+			return sshReply{Stdout: `package dhcp
+
+config dnsmasq
+	option domainneeded '1'
+package dhcp
+
+config dnsmasq 'cfg01411c'
+	option domainneeded '1'
+`}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.GetSection(ctx, "dhcp", "cfg01411c")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got[".anonymous"], lucirpc.Boolean(true))
+	})
+
+	t.Run("returns a NotFoundError when the config does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(command sshCommand) sshReply {
+			return sshReply{
+				Status: 1,
+				Stderr: "uci: Entry not found\n",
+			}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.GetSection(ctx, "nonexistent", "lan")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotFoundError("nonexistent", "lan"))
+	})
+}
+
+func TestSSHClientListSections(t *testing.T) {
+	t.Run("returns sections of the type in order", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		export := `package firewall
+
+config zone 'wan'
+	option name 'wan'
+
+config rule
+	option name 'Allow-Ping'
+
+config zone 'lan'
+	option name 'lan'
+`
+		handle := func(command sshCommand) sshReply {
+			return sshReply{Stdout: export + export}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.ListSections(ctx, "firewall", "zone")
+
+		// Then
+		assert.NilError(t, err)
+		names := []string{}
+		for _, section := range got {
+			name, err := section.GetString(".name")
+			assert.NilError(t, err)
+			names = append(names, name)
+		}
+
+		assert.DeepEqual(t, names, []string{"wan", "lan"})
+	})
+}
+
+func TestSSHClientRunServiceAction(t *testing.T) {
+	t.Run("runs the init script", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var commands []string
+		handle := func(command sshCommand) sshReply {
+			commands = append(commands, command.Command)
+			return sshReply{}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.RunServiceAction(ctx, "network", lucirpc.ServiceReload)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, commands, []string{
+			"'/etc/init.d/network' 'reload'",
+		})
+	})
+
+	t.Run("returns false when the init script fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(command sshCommand) sshReply {
+			return sshReply{Status: 127}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.RunServiceAction(ctx, "nonexistent", lucirpc.ServiceRestart)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, !got)
+	})
+}
+
+func TestSSHClientShowChanges(t *testing.T) {
+	t.Run("parses the changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(command sshCommand) sshReply {
+			return sshReply{Stdout: `network.testing=interface
+network.testing.proto='static'
+network.lan.dns+='1.1.1.1'
+-network.wan.ipv6
+-network.guest
+`}
+		}
+		client, close := authenticatedSSHClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.ShowChanges(ctx, "network")
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, [][]string{
+			{"set", "testing", "interface"},
+			{"set", "testing", "proto", "static"},
+			{"list-add", "lan", "dns", "1.1.1.1"},
+			{"remove", "wan", "ipv6"},
+			{"remove", "guest"},
+		})
+	})
+}
+
+func TestNewSSHClient(t *testing.T) {
+	t.Run("does not support rolling back changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		hostname, port, close := newSSHServer(t, newSSHSigner(t), func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+
+		// When
+		_, err := lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				InsecureIgnoreHostKey: true,
+			},
+			lucirpc.WithRollback(time.Minute),
+		)
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotSupportedError("roll back changes", "the LuCI RPC transport", "the ubus transport"))
+	})
+
+	t.Run("trusts host keys in the known_hosts file", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		hostKey := newSSHSigner(t)
+		hostname, port, close := newSSHServer(t, hostKey, func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+		knownHostsFile := writeKnownHostsFile(t, hostname, port, hostKey.PublicKey())
+
+		// When
+		_, err := lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				KnownHostsFile: knownHostsFile,
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("does not trust unknown host keys", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		hostKey := newSSHSigner(t)
+		hostname, port, close := newSSHServer(t, hostKey, func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+		knownHostsFile := writeKnownHostsFile(t, hostname, port, newSSHSigner(t).PublicKey())
+
+		// When
+		_, err := lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				KnownHostsFile: knownHostsFile,
+			},
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to verify the device's host key")
+	})
+
+	t.Run("returns an AuthenticationError when login fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		hostname, port, close := newSSHServer(t, newSSHSigner(t), func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+
+		// When
+		_, err := lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				InsecureIgnoreHostKey: true,
+				Password:              "wrong",
+			},
+		)
+
+		// Then
+		var authenticationError lucirpc.AuthenticationError
+		assert.Check(t, errors.As(err, &authenticationError))
+	})
+
+	t.Run("authenticates with a private key", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		assert.NilError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		assert.NilError(t, err)
+		privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+			Bytes: der,
+			Type:  "PRIVATE KEY",
+		})
+		hostname, port, close := newSSHServer(t, newSSHSigner(t), func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+
+		// When
+		_, err = lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				InsecureIgnoreHostKey: true,
+				Password:              "wrong",
+				PrivateKey:            privateKeyPEM,
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("closes the connection to the SSH agent after logging in", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		assert.NilError(t, err)
+		keyring := agent.NewKeyring()
+		err = keyring.Add(agent.AddedKey{
+			PrivateKey: privateKey,
+		})
+		assert.NilError(t, err)
+		directory, err := os.MkdirTemp("", "agent")
+		assert.NilError(t, err)
+		defer os.RemoveAll(directory)
+		socket := filepath.Join(directory, "agent.sock")
+		listener, err := net.Listen("unix", socket)
+		assert.NilError(t, err)
+		defer listener.Close()
+		closed := make(chan struct{}, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			agent.ServeAgent(keyring, conn)
+			closed <- struct{}{}
+		}()
+		t.Setenv("SSH_AUTH_SOCK", socket)
+		hostname, port, close := newSSHServer(t, newSSHSigner(t), func(sshCommand) sshReply {
+			return sshReply{}
+		})
+		defer close()
+
+		// When
+		_, err = lucirpc.NewSSHClient(
+			ctx,
+			hostname,
+			uint16(port),
+			"root",
+			lucirpc.SSHOptions{
+				Agent:                 true,
+				InsecureIgnoreHostKey: true,
+				Password:              "wrong",
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		select {
+		case <-closed:
+
+		case <-time.After(time.Second):
+			t.Error("expected the connection to the SSH agent to be closed")
+		}
+	})
+}
+
+// sshCommand is a command the client ran on the fake SSH server.
+type sshCommand struct {
+	Command string
+	Stdin   string
+}
+
+// sshReply is how the fake SSH server responds to a command.
+type sshReply struct {
+	Status int
+	Stderr string
+	Stdout string
+}
+
+func authenticatedSSHClient(
+	t *testing.T,
+	ctx context.Context,
+	handle func(sshCommand) sshReply,
+	options ...lucirpc.ClientOption,
+) (*lucirpc.Client, func()) {
+	t.Helper()
+	hostname, port, close := newSSHServer(t, newSSHSigner(t), handle)
+	client, err := lucirpc.NewSSHClient(
+		ctx,
+		hostname,
+		uint16(port),
+		"root",
+		lucirpc.SSHOptions{
+			InsecureIgnoreHostKey: true,
+		},
+		options...,
+	)
+	if err != nil {
+		close()
+		assert.NilError(t, err)
+	}
+
+	return client, close
+}
+
+// newSSHServer starts an SSH server that accepts the user `root` with an empty password, or any public key.
+// Every command is answered by `handle`.
+func newSSHServer(
+	t *testing.T,
+	hostKey ssh.Signer,
+	handle func(sshCommand) sshReply,
+) (string, int, func()) {
+	t.Helper()
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() != "root" || string(password) != "" {
+				return nil, fmt.Errorf("wrong username or password")
+			}
+
+			return nil, nil
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	mutex := sync.Mutex{}
+	handleSynchronized := func(command sshCommand) sshReply {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return handle(command)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSSH(conn, config, handleSynchronized)
+		}
+	}()
+
+	address := listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), address.Port, func() {
+		listener.Close()
+	}
+}
+
+func newSSHSigner(
+	t *testing.T,
+) ssh.Signer {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.NilError(t, err)
+	return signer
+}
+
+func serveSSH(
+	conn net.Conn,
+	config *ssh.ServerConfig,
+	handle func(sshCommand) sshReply,
+) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		go serveSSHSession(channel, channelRequests, handle)
+	}
+}
+
+func serveSSHSession(
+	channel ssh.Channel,
+	requests <-chan *ssh.Request,
+	handle func(sshCommand) sshReply,
+) {
+	defer channel.Close()
+	for request := range requests {
+		if request.Type != "exec" {
+			request.Reply(false, nil)
+			continue
+		}
+
+		var payload struct {
+			Command string
+		}
+		err := ssh.Unmarshal(request.Payload, &payload)
+		if err != nil {
+			request.Reply(false, nil)
+			continue
+		}
+
+		request.Reply(true, nil)
+		stdin, _ := io.ReadAll(channel)
+		reply := handle(sshCommand{
+			Command: payload.Command,
+			Stdin:   string(stdin),
+		})
+		io.WriteString(channel, reply.Stdout)
+		io.WriteString(channel.Stderr(), reply.Stderr)
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct {
+			Status uint32
+		}{
+			Status: uint32(reply.Status),
+		}))
+		return
+	}
+}
+
+func writeKnownHostsFile(
+	t *testing.T,
+	hostname string,
+	port int,
+	key ssh.PublicKey,
+) string {
+	t.Helper()
+	address := knownhosts.Normalize(net.JoinHostPort(hostname, fmt.Sprint(port)))
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	err := os.WriteFile(file, []byte(knownhosts.Line([]string{address}, key)+"\n"), 0600)
+	assert.NilError(t, err)
+	return file
+}
+
+// withoutPendingSSHChanges answers the check for pending changes like a device with nothing staged,
+// and passes every other command to `handle`.
+func withoutPendingSSHChanges(
+	handle func(sshCommand) sshReply,
+) func(sshCommand) sshReply {
+	return func(command sshCommand) sshReply {
+		if strings.HasPrefix(command.Command, "uci changes ") {
+			return sshReply{}
+		}
+
+		return handle(command)
+	}
+}
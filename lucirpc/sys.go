@@ -0,0 +1,80 @@
+package lucirpc
+
+import (
+	"context"
+)
+
+const (
+	humanReadableExec           = "run command"
+	humanReadableHostname       = "get hostname"
+	humanReadableNetworkDevices = "list network devices"
+	humanReadableReboot         = "reboot"
+)
+
+// Exec runs the `command` in a shell on the device, and returns what it wrote to stdout.
+// The exit code of the `command` is not available,
+// so check its output instead.
+//
+// Only the LuCI RPC transport can run commands.
+func (c *Client) Exec(
+	ctx context.Context,
+	command string,
+) (string, error) {
+	transport, ok := c.transport.(sysTransport)
+	if !ok {
+		return "", NewNotSupportedError(humanReadableExec, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.exec(ctx, command)
+}
+
+// Hostname gets the current hostname of the device.
+//
+// Only the LuCI RPC transport can get the hostname.
+func (c *Client) Hostname(
+	ctx context.Context,
+) (string, error) {
+	transport, ok := c.transport.(sysTransport)
+	if !ok {
+		return "", NewNotSupportedError(humanReadableHostname, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.hostname(ctx)
+}
+
+// NetworkDevices lists the names of the network devices on the device (e.g. `eth0`, `br-lan`).
+//
+// Only the LuCI RPC transport can list network devices.
+func (c *Client) NetworkDevices(
+	ctx context.Context,
+) ([]string, error) {
+	transport, ok := c.transport.(sysTransport)
+	if !ok {
+		return nil, NewNotSupportedError(humanReadableNetworkDevices, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.networkDevices(ctx)
+}
+
+// Reboot restarts the device.
+// The device is unreachable until it finishes booting.
+//
+// Only the LuCI RPC transport can reboot the device.
+func (c *Client) Reboot(
+	ctx context.Context,
+) (bool, error) {
+	transport, ok := c.transport.(sysTransport)
+	if !ok {
+		return false, NewNotSupportedError(humanReadableReboot, humanReadableTransportLuCIRPC)
+	}
+
+	return transport.reboot(ctx)
+}
+
+// sysTransport is implemented by any [transport] that can run commands and read system information on the device.
+type sysTransport interface {
+	exec(ctx context.Context, command string) (string, error)
+	hostname(ctx context.Context) (string, error)
+	networkDevices(ctx context.Context) ([]string, error)
+	reboot(ctx context.Context) (bool, error)
+}
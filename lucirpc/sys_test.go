@@ -0,0 +1,145 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestClientExec(t *testing.T) {
+	t.Run("returns the output of the command", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var requests []string
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			request := r.URL.Path + " " + body.Method
+			for _, param := range body.Params {
+				request += " " + string(param)
+			}
+			requests = append(requests, request)
+			fmt.Fprintf(w, `{
+				"result": "Linux\n"
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.Exec(ctx, "uname")
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, got, "Linux\n")
+		assert.DeepEqual(t, requests, []string{
+			`/cgi-bin/luci/rpc/sys exec "uname"`,
+		})
+	})
+
+	t.Run("is not supported over ubus", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			t.Errorf("unexpected call: %#v", call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.Exec(ctx, "uname")
+
+		// Then
+		assert.ErrorIs(t, err, lucirpc.NewNotSupportedError("run command", "the LuCI RPC transport"))
+	})
+}
+
+func TestClientHostname(t *testing.T) {
+	t.Run("returns the hostname", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, r.URL.Path, "/cgi-bin/luci/rpc/sys")
+			fmt.Fprintf(w, `{
+				"result": "OpenWrt"
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.Hostname(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, got, "OpenWrt")
+	})
+}
+
+func TestClientNetworkDevices(t *testing.T) {
+	t.Run("returns the network devices", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": ["br-lan", "eth0", "lo"]
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.NetworkDevices(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, []string{"br-lan", "eth0", "lo"})
+	})
+}
+
+func TestClientReboot(t *testing.T) {
+	t.Run("handles the exit code of the command", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"result": 0
+			}`)
+		}
+		client, close := authenticatedClient(
+			t,
+			ctx,
+			http.HandlerFunc(handle),
+		)
+		defer close()
+
+		// When
+		got, err := client.Reboot(ctx)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+	})
+}
@@ -0,0 +1,117 @@
+package lucirpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TLSOptions describes how to secure HTTPS connections to the device.
+type TLSOptions struct {
+	// CACertificates are PEM-encoded certificates to trust in addition to the system's.
+	CACertificates []byte
+
+	// CertificateFingerprint is the hex-encoded SHA-256 fingerprint of the device's certificate.
+	// Colons between bytes are allowed.
+	// When set, the device's certificate must match this fingerprint,
+	// and it is not otherwise verified.
+	// This allows trusting a self-signed certificate without trusting anything else.
+	CertificateFingerprint string
+
+	// ClientCertificate is the PEM-encoded certificate to authenticate with.
+	// It must be given along with ClientKey.
+	ClientCertificate []byte
+
+	// ClientKey is the PEM-encoded private key for ClientCertificate.
+	ClientKey []byte
+
+	// InsecureSkipVerify disables verification of the device's certificate.
+	// A CertificateFingerprint is still checked if it is given.
+	InsecureSkipVerify bool
+}
+
+// NewTLSConfig constructs a [tls.Config] from the given `options`.
+// Use it with [WithTLSConfig].
+func NewTLSConfig(
+	options TLSOptions,
+) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+
+	if len(options.CACertificates) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(options.CACertificates) {
+			return nil, fmt.Errorf("unable to parse CA certificates: no PEM-encoded certificates found")
+		}
+
+		config.RootCAs = rootCAs
+	}
+
+	if len(options.ClientCertificate) > 0 || len(options.ClientKey) > 0 {
+		if len(options.ClientCertificate) == 0 || len(options.ClientKey) == 0 {
+			return nil, fmt.Errorf("unable to use client certificate: both a certificate and a key are required")
+		}
+
+		certificate, err := tls.X509KeyPair(options.ClientCertificate, options.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to use client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if options.CertificateFingerprint != "" {
+		fingerprint, err := parseCertificateFingerprint(options.CertificateFingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		// The pinned fingerprint takes the place of the usual verification.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = verifyCertificateFingerprint(fingerprint)
+	}
+
+	return config, nil
+}
+
+func parseCertificateFingerprint(
+	fingerprint string,
+) ([]byte, error) {
+	hexFingerprint := strings.ReplaceAll(fingerprint, ":", "")
+	result, err := hex.DecodeString(hexFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate fingerprint: %w", err)
+	}
+
+	if len(result) != sha256.Size {
+		return nil, fmt.Errorf("unable to parse certificate fingerprint: expected a SHA-256 fingerprint of %d bytes, got %d bytes", sha256.Size, len(result))
+	}
+
+	return result, nil
+}
+
+func verifyCertificateFingerprint(
+	fingerprint []byte,
+) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("expected the device to present a certificate, got none")
+		}
+
+		got := sha256.Sum256(state.PeerCertificates[0].Raw)
+		if !bytes.Equal(got[:], fingerprint) {
+			return fmt.Errorf("expected the device's certificate to have fingerprint %x, got %x", fingerprint, got)
+		}
+
+		return nil
+	}
+}
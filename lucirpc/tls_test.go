@@ -0,0 +1,236 @@
+package lucirpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("rejects invalid CA certificates", func(t *testing.T) {
+		// When
+		_, err := lucirpc.NewTLSConfig(lucirpc.TLSOptions{
+			CACertificates: []byte("not a certificate"),
+		})
+
+		// Then
+		assert.ErrorContains(t, err, "unable to parse CA certificates")
+	})
+
+	t.Run("rejects invalid fingerprints", func(t *testing.T) {
+		// When
+		_, err := lucirpc.NewTLSConfig(lucirpc.TLSOptions{
+			CertificateFingerprint: "ab:cd",
+		})
+
+		// Then
+		assert.ErrorContains(t, err, "expected a SHA-256 fingerprint of 32 bytes, got 2 bytes")
+	})
+
+	t.Run("requires both a client certificate and key", func(t *testing.T) {
+		// Given
+		certificate, _ := newCertificate(t)
+
+		// When
+		_, err := lucirpc.NewTLSConfig(lucirpc.TLSOptions{
+			ClientCertificate: certificate,
+		})
+
+		// Then
+		assert.ErrorContains(t, err, "both a certificate and a key are required")
+	})
+}
+
+func TestClientTLS(t *testing.T) {
+	t.Run("does not trust unknown certificates by default", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := newTLSServer(t)
+		defer server.Close()
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{})
+
+		// Then
+		assert.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("trusts the given CA certificates", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := newTLSServer(t)
+		defer server.Close()
+		caCertificates := pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		})
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{
+			CACertificates: caCertificates,
+		})
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("trusts a certificate matching the fingerprint", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := newTLSServer(t)
+		defer server.Close()
+		fingerprint := sha256.Sum256(server.Certificate().Raw)
+		var parts []string
+		for _, b := range fingerprint {
+			parts = append(parts, fmt.Sprintf("%02X", b))
+		}
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{
+			CertificateFingerprint: strings.Join(parts, ":"),
+		})
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("does not trust a certificate with a different fingerprint", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := newTLSServer(t)
+		defer server.Close()
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{
+			CertificateFingerprint: strings.Repeat("00", 32),
+			InsecureSkipVerify:     true,
+		})
+
+		// Then
+		assert.ErrorContains(t, err, "expected the device's certificate to have fingerprint")
+	})
+
+	t.Run("skips verification when asked", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		server := newTLSServer(t)
+		defer server.Close()
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{
+			InsecureSkipVerify: true,
+		})
+
+		// Then
+		assert.NilError(t, err)
+	})
+
+	t.Run("sends the client certificate", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		certificate, key := newCertificate(t)
+		server := httptest.NewUnstartedServer(luciLoginHandler())
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAnyClientCert,
+		}
+		server.StartTLS()
+		defer server.Close()
+
+		// When
+		_, err := newTLSClient(ctx, t, server, lucirpc.TLSOptions{
+			ClientCertificate:  certificate,
+			ClientKey:          key,
+			InsecureSkipVerify: true,
+		})
+
+		// Then
+		assert.NilError(t, err)
+	})
+}
+
+func luciLoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{
+			"result": "abc123"
+		}`)
+	})
+}
+
+// newCertificate generates a PEM-encoded self-signed certificate and private key.
+func newCertificate(
+	t *testing.T,
+) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := x509.Certificate{
+		NotAfter:     time.Now().Add(time.Hour),
+		NotBefore:    time.Now(),
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "client",
+		},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	certificatePEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certificate,
+	})
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyBytes,
+	})
+	return certificatePEM, keyPEM
+}
+
+func newTLSClient(
+	ctx context.Context,
+	t *testing.T,
+	server *httptest.Server,
+	options lucirpc.TLSOptions,
+) (*lucirpc.Client, error) {
+	t.Helper()
+	address, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+	port, err := strconv.Atoi(address.Port())
+	assert.NilError(t, err)
+	tlsConfig, err := lucirpc.NewTLSConfig(options)
+	assert.NilError(t, err)
+	return lucirpc.NewClient(
+		ctx,
+		address.Scheme,
+		address.Hostname(),
+		uint16(port),
+		"root",
+		"",
+		lucirpc.WithTLSConfig(tlsConfig),
+	)
+}
+
+func newTLSServer(
+	t *testing.T,
+) *httptest.Server {
+	t.Helper()
+	return httptest.NewTLSServer(luciLoginHandler())
+}
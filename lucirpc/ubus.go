@@ -0,0 +1,1019 @@
+package lucirpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	pathUbus = "/ubus"
+
+	ubusErrorCodeAccessDenied = -32002
+
+	ubusJSONRPCVersion = "2.0"
+
+	ubusMethodAccess  = "access"
+	ubusMethodAdd     = "add"
+	ubusMethodApply   = "apply"
+	ubusMethodBoard   = "board"
+	ubusMethodCall    = "call"
+	ubusMethodChanges = "changes"
+	ubusMethodCommit  = "commit"
+	ubusMethodConfigs = "configs"
+	ubusMethodConfirm = "confirm"
+	ubusMethodDelete  = "delete"
+	ubusMethodGet     = "get"
+	ubusMethodInit    = "init"
+	ubusMethodLogin   = "login"
+	ubusMethodOrder   = "order"
+	ubusMethodRevert  = "revert"
+	ubusMethodSet     = "set"
+
+	ubusNullSession = "00000000000000000000000000000000"
+
+	ubusObjectRC      = "rc"
+	ubusObjectSession = "session"
+	ubusObjectSystem  = "system"
+	ubusObjectUCI     = "uci"
+)
+
+const (
+	ubusStatusOK ubusStatus = iota
+	ubusStatusInvalidCommand
+	ubusStatusInvalidArgument
+	ubusStatusMethodNotFound
+	ubusStatusNotFound
+	ubusStatusNoData
+	ubusStatusPermissionDenied
+	ubusStatusTimeout
+	ubusStatusNotSupported
+	ubusStatusUnknownError
+	ubusStatusConnectionFailed
+)
+
+// ubusTransport talks to UCI through the JSON-RPC API provided by `uhttpd-mod-ubus`.
+// See https://openwrt.org/docs/techref/ubus#access_to_ubus_over_http for more information.
+type ubusTransport struct {
+	jsonRPCClient ubusJSONRPCClient
+}
+
+// applyChangesWithRollback commits every staged change and reloads the affected services.
+//
+// The device keeps a copy of the previous configuration,
+// and restores it if the changes are not confirmed within `timeout`.
+// The device counts the timeout in whole seconds,
+// so it is rounded up rather than cut short.
+// After waiting for `holdoff` to let the services reload,
+// we connect to the device again,
+// make sure the session is still valid (logging in again if it expired),
+// and confirm the changes.
+// If we cannot reach the device before `timeout` passes,
+// we stop trying and let the device roll back.
+func (t ubusTransport) applyChangesWithRollback(
+	ctx context.Context,
+	timeout time.Duration,
+	holdoff time.Duration,
+) error {
+	deadline := time.Now().Add(timeout)
+	arguments := map[string]any{
+		"rollback": true,
+		"timeout":  int(math.Ceil(timeout.Seconds())),
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableApplyChanges,
+		ubusObjectUCI,
+		ubusMethodApply,
+		arguments,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to %s: %w", humanReadableApplyChanges, err)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	wait := holdoff
+	for {
+		select {
+		case <-time.After(wait):
+
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+
+			return fmt.Errorf("could not reach the device to %s, it will roll back the changes: %w", humanReadableConfirmChanges, err)
+		}
+
+		// Make sure we can open a new connection,
+		// rather than reusing one from before the services reloaded.
+		t.jsonRPCClient.client.CloseIdleConnections()
+		err = t.jsonRPCClient.checkSession(ctx)
+		if err != nil {
+			if !errors.As(err, &temporaryError{}) {
+				return fmt.Errorf("unable to %s, the device will roll back the changes: %w", humanReadableConfirmChanges, err)
+			}
+
+			wait = rollbackConfirmInterval
+			continue
+		}
+
+		_, err = t.jsonRPCClient.Call(
+			ctx,
+			humanReadableConfirmChanges,
+			ubusObjectUCI,
+			ubusMethodConfirm,
+			map[string]any{},
+		)
+		if err == nil {
+			return nil
+		}
+
+		var status ubusStatus
+		if errors.As(err, &status) {
+			return fmt.Errorf("unable to %s, the device will roll back the changes: %w", humanReadableConfirmChanges, err)
+		}
+
+		wait = rollbackConfirmInterval
+	}
+}
+
+func (t ubusTransport) addSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	options Options,
+) (string, error) {
+	arguments := map[string]any{
+		"config": config,
+		"type":   sectionType,
+		"values": options,
+	}
+	responseBody, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableAddSection,
+		ubusObjectUCI,
+		ubusMethodAdd,
+		arguments,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to %s: %w", humanReadableAddSection, err)
+	}
+
+	if responseBody == nil {
+		return "", NewProtocolError(fmt.Errorf("unable to %s: no section was returned", humanReadableAddSection))
+	}
+
+	var result struct {
+		Section string `json:"section"`
+	}
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return "", NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableAddSection, err))
+	}
+
+	if result.Section == "" {
+		return "", NewProtocolError(fmt.Errorf("unable to %s: no section was returned", humanReadableAddSection))
+	}
+
+	return result.Section, nil
+}
+
+// capabilities lists the configs with `uci configs` and reads the release with `system board`.
+// Each object that answers is one of the RPC modules.
+func (t ubusTransport) capabilities(
+	ctx context.Context,
+) (Capabilities, error) {
+	result := Capabilities{}
+	rpcModules := []string{}
+	responseBody, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableListConfigs,
+		ubusObjectUCI,
+		ubusMethodConfigs,
+		map[string]any{},
+	)
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil && responseBody != nil {
+		var configs struct {
+			Configs []string `json:"configs"`
+		}
+		err = json.Unmarshal(*responseBody, &configs)
+		if err != nil {
+			return Capabilities{}, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableListConfigs, err))
+		}
+
+		result.Configs = append([]string{}, configs.Configs...)
+		sort.Strings(result.Configs)
+		rpcModules = append(rpcModules, ubusObjectUCI)
+	}
+
+	responseBody, err = t.jsonRPCClient.Call(
+		ctx,
+		humanReadableReadRelease,
+		ubusObjectSystem,
+		ubusMethodBoard,
+		map[string]any{},
+	)
+	if isProbeFailure(err) {
+		return Capabilities{}, fmt.Errorf("unable to %s: %w", humanReadableCapabilities, err)
+	}
+
+	if err == nil && responseBody != nil {
+		var board struct {
+			Release struct {
+				Description string `json:"description"`
+			} `json:"release"`
+		}
+		err = json.Unmarshal(*responseBody, &board)
+		if err != nil {
+			return Capabilities{}, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableReadRelease, err))
+		}
+
+		result.Release = board.Release.Description
+		rpcModules = append(rpcModules, ubusObjectSystem)
+	}
+
+	sort.Strings(rpcModules)
+	result.RPCModules = rpcModules
+	return result, nil
+}
+
+func (t ubusTransport) commitChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	arguments := map[string]any{
+		"config": config,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableCommitChanges,
+		ubusObjectUCI,
+		ubusMethodCommit,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCommitChanges, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) createSection(
+	ctx context.Context,
+	config string,
+	sectionType string,
+	section string,
+	options Options,
+) (bool, error) {
+	arguments := map[string]any{
+		"config": config,
+		"name":   section,
+		"type":   sectionType,
+		"values": options,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableCreateSection,
+		ubusObjectUCI,
+		ubusMethodAdd,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableCreateSection, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) deleteOptions(
+	ctx context.Context,
+	config string,
+	section string,
+	options []string,
+) (bool, error) {
+	arguments := map[string]any{
+		"config":  config,
+		"options": options,
+		"section": section,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableDeleteOptions,
+		ubusObjectUCI,
+		ubusMethodDelete,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteOptions, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) deleteSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (bool, error) {
+	arguments := map[string]any{
+		"config":  config,
+		"section": section,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableDeleteSection,
+		ubusObjectUCI,
+		ubusMethodDelete,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableDeleteSection, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) getSection(
+	ctx context.Context,
+	config string,
+	section string,
+) (Options, error) {
+	arguments := map[string]any{
+		"config":  config,
+		"section": section,
+	}
+	responseBody, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableGetSection,
+		ubusObjectUCI,
+		ubusMethodGet,
+		arguments,
+	)
+	if errors.Is(err, ubusStatusNotFound) {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableGetSection, err)
+	}
+
+	if responseBody == nil {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	var result struct {
+		Values *Options `json:"values"`
+	}
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableGetSection, err))
+	}
+
+	if result.Values == nil {
+		return nil, NewNotFoundError(config, section)
+	}
+
+	return *result.Values, nil
+}
+
+func (t ubusTransport) listSections(
+	ctx context.Context,
+	config string,
+	sectionType string,
+) ([]Options, error) {
+	arguments := map[string]any{
+		"config": config,
+	}
+	if sectionType != "" {
+		arguments["type"] = sectionType
+	}
+
+	responseBody, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableListSections,
+		ubusObjectUCI,
+		ubusMethodGet,
+		arguments,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableListSections, err)
+	}
+
+	if responseBody == nil {
+		return nil, fmt.Errorf("unable to %s: could not find config %q", humanReadableListSections, config)
+	}
+
+	var result struct {
+		Values map[string]Options `json:"values"`
+	}
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableListSections, err))
+	}
+
+	return orderSections(result.Values, sectionType)
+}
+
+// reorderSection moves the `section` with `uci order`.
+// That call sets the order of every section it is given,
+// so the current order of the whole config is needed first.
+func (t ubusTransport) reorderSection(
+	ctx context.Context,
+	config string,
+	section string,
+	index int,
+) (bool, error) {
+	sections, err := t.listSections(ctx, config, "")
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableReorderSection, err)
+	}
+
+	names := []string{}
+	found := false
+	for _, options := range sections {
+		name, err := options.GetString(".name")
+		if err != nil {
+			return false, NewProtocolError(fmt.Errorf("unable to %s: %w", humanReadableReorderSection, err))
+		}
+
+		if name == section {
+			found = true
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	if !found {
+		return false, NewNotFoundError(config, section)
+	}
+
+	if index < 0 || index > len(names) {
+		index = len(names)
+	}
+
+	names = append(names[:index], append([]string{section}, names[index:]...)...)
+	arguments := map[string]any{
+		"config":   config,
+		"sections": names,
+	}
+	_, err = t.jsonRPCClient.Call(
+		ctx,
+		humanReadableReorderSection,
+		ubusObjectUCI,
+		ubusMethodOrder,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableReorderSection, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) revertChanges(
+	ctx context.Context,
+	config string,
+) (bool, error) {
+	arguments := map[string]any{
+		"config": config,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableRevertChanges,
+		ubusObjectUCI,
+		ubusMethodRevert,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableRevertChanges, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) serviceAction(
+	ctx context.Context,
+	service string,
+	action ServiceAction,
+) (bool, error) {
+	arguments := map[string]any{
+		"action": action,
+		"name":   service,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableServiceAction,
+		ubusObjectRC,
+		ubusMethodInit,
+		arguments,
+	)
+	if errors.Is(err, ubusStatusNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableServiceAction, err)
+	}
+
+	return true, nil
+}
+
+func (t ubusTransport) showChanges(
+	ctx context.Context,
+	config string,
+) ([][]string, error) {
+	arguments := map[string]any{
+		"config": config,
+	}
+	responseBody, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableShowChanges,
+		ubusObjectUCI,
+		ubusMethodChanges,
+		arguments,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s: %w", humanReadableShowChanges, err)
+	}
+
+	result := [][]string{}
+	if responseBody == nil {
+		return result, nil
+	}
+
+	var changes struct {
+		Changes [][]string `json:"changes"`
+	}
+	err = json.Unmarshal(*responseBody, &changes)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableShowChanges, err))
+	}
+
+	result = append(result, changes.Changes...)
+	return result, nil
+}
+
+func (t ubusTransport) updateSection(
+	ctx context.Context,
+	config string,
+	section string,
+	options Options,
+) (bool, error) {
+	arguments := map[string]any{
+		"config":  config,
+		"section": section,
+		"values":  options,
+	}
+	_, err := t.jsonRPCClient.Call(
+		ctx,
+		humanReadableUpdateSection,
+		ubusObjectUCI,
+		ubusMethodSet,
+		arguments,
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to %s: %w", humanReadableUpdateSection, err)
+	}
+
+	return true, nil
+}
+
+func newUbusTransport(
+	ctx context.Context,
+	httpClient http.Client,
+	scheme string,
+	host string,
+	username string,
+	credentials credentials,
+	retryPolicy retryPolicy,
+) (ubusTransport, error) {
+	address := url.URL{
+		Host:   host,
+		Path:   pathUbus,
+		Scheme: scheme,
+	}
+	jsonRPCClientAuth := ubusJSONRPCNewClient(
+		httpClient,
+		address,
+		retryPolicy,
+		nil,
+	)
+	login := func(ctx context.Context, password string) (string, error) {
+		return ubusLogin(
+			ctx,
+			jsonRPCClientAuth,
+			username,
+			password,
+		)
+	}
+	session, err := credentials.newSession(ctx, login)
+	if err != nil {
+		return ubusTransport{}, err
+	}
+
+	jsonRPCClient := ubusJSONRPCNewClient(
+		httpClient,
+		address,
+		retryPolicy,
+		session,
+	)
+	transport := ubusTransport{
+		jsonRPCClient: jsonRPCClient,
+	}
+	return transport, nil
+}
+
+type ubusJSONRPCClient struct {
+	address     url.URL
+	client      http.Client
+	retryPolicy retryPolicy
+	session     *session
+}
+
+// Call invokes the `method` on the ubus `object` with the given `arguments`.
+//
+// ubus responds with a status code and optionally some data.
+// Any status code other than success is returned as an error.
+// Otherwise, the data is returned if there was any.
+//
+// If the client is tied to a session,
+// the session's token is used for the call.
+// ubus denies access the same way whether the session expired or is not allowed to make the call.
+// So when access is denied,
+// we ask the device if the session is still valid.
+// Only if it is not do we login again and retry the call exactly once.
+//
+// Temporary problems sending the call are retried according to the client's [retryPolicy].
+func (c ubusJSONRPCClient) Call(
+	ctx context.Context,
+	humanReadableMethod string,
+	object string,
+	method string,
+	arguments any,
+) (*json.RawMessage, error) {
+	if c.session == nil {
+		return c.callWithRetries(
+			ctx,
+			humanReadableMethod,
+			ubusNullSession,
+			object,
+			method,
+			arguments,
+		)
+	}
+
+	token := c.session.currentToken()
+	result, err := c.callWithRetries(
+		ctx,
+		humanReadableMethod,
+		token,
+		object,
+		method,
+		arguments,
+	)
+	if !errors.Is(err, errSessionExpired) {
+		return result, err
+	}
+
+	if c.sessionValid(ctx, token, object, method) {
+		return nil, NewAccessDeniedError(err)
+	}
+
+	token, err = c.session.refresh(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("session expired during %s: %w", humanReadableMethod, err)
+	}
+
+	result, err = c.callWithRetries(
+		ctx,
+		humanReadableMethod,
+		token,
+		object,
+		method,
+		arguments,
+	)
+	if errors.Is(err, errSessionExpired) {
+		// We just logged in, so the session is not the problem.
+		return nil, NewAccessDeniedError(err)
+	}
+
+	return result, err
+}
+
+func (c ubusJSONRPCClient) call(
+	ctx context.Context,
+	humanReadableMethod string,
+	token string,
+	object string,
+	method string,
+	arguments any,
+) (*json.RawMessage, error) {
+	requestBody := ubusRequestBody{
+		ID:      1,
+		JSONRPC: ubusJSONRPCVersion,
+		Method:  ubusMethodCall,
+		Params: []any{
+			token,
+			object,
+			method,
+			arguments,
+		},
+	}
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	err := encoder.Encode(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("problem encoding %s request: %w", humanReadableMethod, err)
+	}
+
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.address.String(),
+		&buffer,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("problem creating %s request: %w", humanReadableMethod, err)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, newSendError(humanReadableMethod, err)
+	}
+
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, newResponseStatusError(humanReadableMethod, response)
+	}
+
+	var responseBody ubusResponseBody
+	decoder := json.NewDecoder(response.Body)
+	err = decoder.Decode(&responseBody)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableMethod, err))
+	}
+
+	if responseBody.Error != nil {
+		if responseBody.Error.Code == ubusErrorCodeAccessDenied {
+			return nil, fmt.Errorf("%s error: %s: %w", humanReadableMethod, responseBody.Error.Message, errSessionExpired)
+		}
+
+		return nil, NewRPCError(fmt.Errorf("%s error: %s", humanReadableMethod, responseBody.Error.Message))
+	}
+
+	if len(responseBody.Result) == 0 {
+		return nil, NewProtocolError(fmt.Errorf("invalid %s response: expected either an error or a result, got neither", humanReadableMethod))
+	}
+
+	var status ubusStatus
+	err = json.Unmarshal(responseBody.Result[0], &status)
+	if err != nil {
+		return nil, NewProtocolError(fmt.Errorf("unable to parse %s response status: %w", humanReadableMethod, err))
+	}
+
+	if status == ubusStatusPermissionDenied {
+		return nil, NewAccessDeniedError(fmt.Errorf("%s error: %w", humanReadableMethod, status))
+	}
+
+	if status != ubusStatusOK {
+		return nil, NewRPCError(fmt.Errorf("%s error: %w", humanReadableMethod, status))
+	}
+
+	if len(responseBody.Result) < 2 {
+		return nil, nil
+	}
+
+	return &responseBody.Result[1], nil
+}
+
+func (c ubusJSONRPCClient) callWithRetries(
+	ctx context.Context,
+	humanReadableMethod string,
+	token string,
+	object string,
+	method string,
+	arguments any,
+) (*json.RawMessage, error) {
+	return retry(
+		ctx,
+		c.retryPolicy,
+		humanReadableMethod,
+		isIdempotentUbusMethod(object, method),
+		func() (*json.RawMessage, error) {
+			return c.call(
+				ctx,
+				humanReadableMethod,
+				token,
+				object,
+				method,
+				arguments,
+			)
+		},
+	)
+}
+
+// checkSession makes sure the session is still valid,
+// logging in again if it expired.
+// This is for calls that should not be sent twice,
+// since [ubusJSONRPCClient.Call] only notices an expired session after sending the call.
+// If the client is not tied to a session, there is nothing to check.
+func (c ubusJSONRPCClient) checkSession(
+	ctx context.Context,
+) error {
+	if c.session == nil {
+		return nil
+	}
+
+	_, err := c.Call(
+		ctx,
+		humanReadableCheckSession,
+		ubusObjectSession,
+		ubusMethodAccess,
+		map[string]any{},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to %s: %w", humanReadableCheckSession, err)
+	}
+
+	return nil
+}
+
+// sessionValid checks if the session with the `token` is still valid,
+// by asking whether it can call the `method` on the ubus `object`.
+// A valid session gets an answer either way,
+// while an expired session is denied access again.
+func (c ubusJSONRPCClient) sessionValid(
+	ctx context.Context,
+	token string,
+	object string,
+	method string,
+) bool {
+	arguments := map[string]any{
+		"function": method,
+		"object":   object,
+		"scope":    "ubus",
+	}
+	_, err := c.callWithRetries(
+		ctx,
+		humanReadableCheckSession,
+		token,
+		ubusObjectSession,
+		ubusMethodAccess,
+		arguments,
+	)
+	return err == nil
+}
+
+func ubusJSONRPCNewClient(
+	httpClient http.Client,
+	address url.URL,
+	retryPolicy retryPolicy,
+	session *session,
+) ubusJSONRPCClient {
+	return ubusJSONRPCClient{
+		address:     address,
+		client:      httpClient,
+		retryPolicy: retryPolicy,
+		session:     session,
+	}
+}
+
+// isIdempotentUbusMethod checks if calling the `method` on the ubus `object` is safe to send again after the device might have seen it.
+// Only methods that read from the device are,
+// along with logging in,
+// which only hands out another session.
+// Anything that changes the device is sent again only when it never reached the device:
+// committing or reverting again acts on whatever was staged in between,
+// restarting a service again restarts it twice,
+// and deleting or adding a section again either fails or adds another one.
+func isIdempotentUbusMethod(
+	object string,
+	method string,
+) bool {
+	switch object {
+	case ubusObjectSession:
+		return method == ubusMethodAccess || method == ubusMethodLogin
+
+	case ubusObjectSystem:
+		return method == ubusMethodBoard
+
+	case ubusObjectUCI:
+		return method == ubusMethodChanges || method == ubusMethodConfigs || method == ubusMethodGet
+	}
+
+	return false
+}
+
+func ubusLogin(
+	ctx context.Context,
+	jsonRPCClientAuth ubusJSONRPCClient,
+	username string,
+	password string,
+) (string, error) {
+	arguments := map[string]any{
+		"password": password,
+		"username": username,
+	}
+	responseBody, err := jsonRPCClientAuth.Call(
+		ctx,
+		humanReadableLogin,
+		ubusObjectSession,
+		ubusMethodLogin,
+		arguments,
+	)
+	if err != nil {
+		return "", NewAuthenticationError(fmt.Errorf("unable to %s: %w", humanReadableLogin, err))
+	}
+
+	if responseBody == nil {
+		return "", NewAuthenticationError(NewProtocolError(fmt.Errorf("invalid %s response: expected a session, got nothing", humanReadableLogin)))
+	}
+
+	var result struct {
+		Session string `json:"ubus_rpc_session"`
+	}
+	err = json.Unmarshal(*responseBody, &result)
+	if err != nil {
+		return "", NewAuthenticationError(NewProtocolError(fmt.Errorf("unable to parse %s response: %w", humanReadableLogin, err)))
+	}
+
+	if result.Session == "" {
+		return "", NewAuthenticationError(NewProtocolError(fmt.Errorf("invalid %s response: expected a session, got nothing", humanReadableLogin)))
+	}
+
+	return result.Session, nil
+}
+
+type ubusRequestBody struct {
+	ID      int    `json:"id"`
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type ubusResponseBody struct {
+	Error  *ubusResponseError `json:"error"`
+	Result []json.RawMessage  `json:"result"`
+}
+
+type ubusResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ubusStatus is the status code ubus returns with every call.
+type ubusStatus int
+
+func (s ubusStatus) Error() string {
+	switch s {
+	case ubusStatusOK:
+		return "success"
+
+	case ubusStatusInvalidCommand:
+		return "invalid command"
+
+	case ubusStatusInvalidArgument:
+		return "invalid argument"
+
+	case ubusStatusMethodNotFound:
+		return "method not found"
+
+	case ubusStatusNotFound:
+		return "not found"
+
+	case ubusStatusNoData:
+		return "no response"
+
+	case ubusStatusPermissionDenied:
+		return "permission denied"
+
+	case ubusStatusTimeout:
+		return "request timed out"
+
+	case ubusStatusNotSupported:
+		return "operation not supported"
+
+	case ubusStatusUnknownError:
+		return "unknown error"
+
+	case ubusStatusConnectionFailed:
+		return "connection failed"
+
+	default:
+		return fmt.Sprintf("unknown ubus status: %d", int(s))
+	}
+}
@@ -0,0 +1,1390 @@
+package lucirpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewUbusClient(t *testing.T) {
+	t.Run("handles server not existing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		// When
+		_, err := lucirpc.NewUbusClient(
+			ctx,
+			"http",
+			"non.existent",
+			80,
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "problem sending request to login")
+	})
+
+	t.Run("logs in with the null session", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var got ubusCall
+		handle := func(call ubusCall) string {
+			got = call
+			return `[0, {"ubus_rpc_session": "abc123"}]`
+		}
+		address, port, close := newServer(t, ubusHandler(t, handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"hunter2",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, got.Session, "00000000000000000000000000000000")
+		assert.Equal(t, got.Object, "session")
+		assert.Equal(t, got.Method, "login")
+		assert.DeepEqual(t, got.Arguments, map[string]any{
+			"password": "hunter2",
+			"username": "root",
+		})
+	})
+
+	t.Run("expects a 200 response", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "expected login to respond with a 200")
+	})
+
+	t.Run("returns error when authentication fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[6]`
+		}
+		address, port, close := newServer(t, ubusHandler(t, handle))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to login: login error: permission denied")
+	})
+}
+
+func TestUbusClientAddSection(t *testing.T) {
+	t.Run("adds an anonymous section and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			if call.Method == "add" {
+				return `[0, {"section": "cfg0a1b2c"}]`
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.AddSection(
+			ctx,
+			"firewall",
+			"rule",
+			lucirpc.Options{
+				"name": lucirpc.String("testing"),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, got, "cfg0a1b2c")
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "add",
+				Arguments: map[string]any{
+					"config": "firewall",
+					"type":   "rule",
+					"values": map[string]any{
+						"name": "testing",
+					},
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "commit",
+				Arguments: map[string]any{
+					"config": "firewall",
+				},
+			},
+		})
+	})
+
+	t.Run("expects the name of the section", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.AddSection(
+			ctx,
+			"firewall",
+			"rule",
+			lucirpc.Options{},
+		)
+
+		// Then
+		var got lucirpc.ProtocolError
+		assert.Assert(t, errors.As(err, &got))
+	})
+}
+
+func TestUbusClientCreateSection(t *testing.T) {
+	t.Run("adds the section and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"testing",
+			lucirpc.Options{
+				"proto": lucirpc.String("static"),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "add",
+				Arguments: map[string]any{
+					"config": "network",
+					"name":   "testing",
+					"type":   "interface",
+					"values": map[string]any{
+						"proto": "static",
+					},
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "commit",
+				Arguments: map[string]any{
+					"config": "network",
+				},
+			},
+		})
+	})
+
+	t.Run("returns error when adding fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[2]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		_, err := client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"testing",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to create section: create section error: invalid argument")
+	})
+}
+
+func TestUbusClientDeleteOptions(t *testing.T) {
+	t.Run("deletes the options and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.DeleteOptions(
+			ctx,
+			"network",
+			"testing",
+			[]string{"dns", "ipaddr"},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "delete",
+				Arguments: map[string]any{
+					"config":  "network",
+					"options": []any{"dns", "ipaddr"},
+					"section": "testing",
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "commit",
+				Arguments: map[string]any{
+					"config": "network",
+				},
+			},
+		})
+	})
+}
+
+func TestUbusClientDeleteSection(t *testing.T) {
+	t.Run("deletes the section and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var methods []string
+		handle := func(call ubusCall) string {
+			methods = append(methods, call.Method)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.DeleteSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, methods, []string{"delete", "commit"})
+	})
+}
+
+func TestUbusClientGetSection(t *testing.T) {
+	t.Run("returns section data when successful", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[0, {
+				"values": {
+					".anonymous": false,
+					".name": "section-name",
+					"baz": "1",
+					"foo": "bar"
+				}
+			}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		want := lucirpc.Options{
+			".anonymous": lucirpc.Boolean(false),
+			".name":      lucirpc.String("section-name"),
+			"baz":        lucirpc.Boolean(true),
+			"foo":        lucirpc.String("bar"),
+		}
+		assert.DeepEqual(t, got, want)
+	})
+
+	t.Run("handles section not existing", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[4]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.GetSection(
+			ctx,
+			"network",
+			"testing",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "could not find section network.testing")
+	})
+
+	t.Run("logs in again when the session expires", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var logins int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			var session string
+			err = json.Unmarshal(body.Params[0], &session)
+			assert.NilError(t, err)
+			switch session {
+			case "00000000000000000000000000000000":
+				logins++
+				fmt.Fprintf(w, `{
+					"jsonrpc": "2.0",
+					"id": 1,
+					"result": [0, {"ubus_rpc_session": "token-%d"}]
+				}`, logins)
+
+			case "token-2":
+				fmt.Fprintf(w, `{
+					"jsonrpc": "2.0",
+					"id": 1,
+					"result": [0, {"values": {".name": "section-name"}}]
+				}`)
+
+			default:
+				fmt.Fprintf(w, `{
+					"jsonrpc": "2.0",
+					"id": 1,
+					"error": {"code": -32002, "message": "Access denied"}
+				}`)
+			}
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+		assert.NilError(t, err)
+
+		// When
+		got, err := client.GetSection(
+			ctx,
+			"",
+			"",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		want := lucirpc.Options{
+			".name": lucirpc.String("section-name"),
+		}
+		assert.DeepEqual(t, got, want)
+		assert.Equal(t, logins, 2)
+	})
+
+	t.Run("returns access denied without logging in again when the session is still valid", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var logins int
+		var checks []map[string]any
+		handle := func(call ubusCall) string {
+			switch {
+			case call.Object == "session" && call.Method == "login":
+				logins++
+				return `[0, {"ubus_rpc_session": "abc123"}]`
+
+			case call.Object == "session" && call.Method == "access":
+				checks = append(checks, call.Arguments)
+				return `[0, {"access": false}]`
+			}
+
+			return ""
+		}
+		address, port, close := newServer(t, ubusAccessDeniedHandler(t, handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.GetSection(ctx, "network", "lan")
+
+		// Then
+		var accessDenied lucirpc.AccessDeniedError
+		assert.Check(t, errors.As(err, &accessDenied))
+		assert.Equal(t, logins, 1)
+		assert.DeepEqual(t, checks, []map[string]any{
+			{
+				"function": "get",
+				"object":   "uci",
+				"scope":    "ubus",
+			},
+		})
+	})
+}
+
+func TestUbusClientListSections(t *testing.T) {
+	t.Run("asks for sections of the given type", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0, {
+				"values": {
+					"second": {
+						".anonymous": false,
+						".index": 2,
+						".name": "second",
+						".type": "host"
+					},
+					"first": {
+						".anonymous": false,
+						".index": 1,
+						".name": "first",
+						".type": "host"
+					}
+				}
+			}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.ListSections(
+			ctx,
+			"dhcp",
+			"host",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "get",
+				Arguments: map[string]any{
+					"config": "dhcp",
+					"type":   "host",
+				},
+			},
+		})
+		assert.DeepEqual(t, got, []lucirpc.Options{
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(1),
+				".name":      lucirpc.String("first"),
+				".type":      lucirpc.String("host"),
+			},
+			{
+				".anonymous": lucirpc.Boolean(false),
+				".index":     lucirpc.Integer(2),
+				".name":      lucirpc.String("second"),
+				".type":      lucirpc.String("host"),
+			},
+		})
+	})
+}
+
+func TestUbusClientReorderSection(t *testing.T) {
+	t.Run("orders every section of the config", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			if call.Method == "get" {
+				return `[0, {
+					"values": {
+						"defaults": {
+							".anonymous": true,
+							".index": 0,
+							".name": "defaults",
+							".type": "defaults"
+						},
+						"first": {
+							".anonymous": false,
+							".index": 1,
+							".name": "first",
+							".type": "rule"
+						},
+						"second": {
+							".anonymous": false,
+							".index": 2,
+							".name": "second",
+							".type": "rule"
+						}
+					}
+				}]`
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.ReorderSection(
+			ctx,
+			"firewall",
+			"second",
+			1,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "get",
+				Arguments: map[string]any{
+					"config": "firewall",
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "order",
+				Arguments: map[string]any{
+					"config":   "firewall",
+					"sections": []any{"defaults", "second", "first"},
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "commit",
+				Arguments: map[string]any{
+					"config": "firewall",
+				},
+			},
+		})
+	})
+
+	t.Run("returns a NotFoundError when the section does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[0, {
+				"values": {}
+			}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		_, err := client.ReorderSection(
+			ctx,
+			"firewall",
+			"testing",
+			0,
+		)
+
+		// Then
+		var got lucirpc.NotFoundError
+		assert.Assert(t, errors.As(err, &got))
+	})
+}
+
+func TestUbusClientRevertChanges(t *testing.T) {
+	t.Run("reverts changes to the config", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.RevertChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "revert",
+				Arguments: map[string]any{
+					"config": "network",
+				},
+			},
+		})
+	})
+}
+
+func TestUbusClientRollback(t *testing.T) {
+	t.Run("applies and confirms changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(
+			t,
+			ctx,
+			withoutPendingUbusChanges(handle),
+			lucirpc.WithRollback(2*time.Second),
+		)
+		defer close()
+
+		// When
+		got, err := client.UpdateSection(
+			ctx,
+			"network",
+			"lan",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "set",
+				Arguments: map[string]any{
+					"config":  "network",
+					"section": "lan",
+					"values":  map[string]any{},
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "apply",
+				Arguments: map[string]any{
+					"rollback": true,
+					"timeout":  float64(2),
+				},
+			},
+			{
+				Session:   "abc123",
+				Object:    "session",
+				Method:    "access",
+				Arguments: map[string]any{},
+			},
+			{
+				Session:   "abc123",
+				Object:    "uci",
+				Method:    "confirm",
+				Arguments: map[string]any{},
+			},
+		})
+	})
+
+	t.Run("rounds the timeout up to whole seconds", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var timeout any
+		handle := func(call ubusCall) string {
+			if call.Method == "apply" {
+				timeout = call.Arguments["timeout"]
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(
+			t,
+			ctx,
+			withoutPendingUbusChanges(handle),
+			lucirpc.WithRollback(1500*time.Millisecond),
+		)
+		defer close()
+
+		// When
+		_, err := client.UpdateSection(
+			ctx,
+			"network",
+			"lan",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Equal(t, timeout, float64(2))
+	})
+
+	t.Run("rejects a timeout under a second", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		address, port, close := newServer(t, ubusHandler(t, func(ubusCall) string {
+			return `[0, {"ubus_rpc_session": "abc123"}]`
+		}))
+		defer close()
+
+		// When
+		_, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithRollback(500*time.Millisecond),
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "invalid roll back changes timeout: the device counts it in whole seconds, so it must be at least 1s: got 500ms")
+	})
+
+	t.Run("logs in again before confirming when the session expired", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var confirmSessions []string
+		var logins int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			var session, method string
+			err = json.Unmarshal(body.Params[0], &session)
+			assert.NilError(t, err)
+			err = json.Unmarshal(body.Params[2], &method)
+			assert.NilError(t, err)
+			result := `[0]`
+			switch method {
+			case "login":
+				logins++
+				result = fmt.Sprintf(`[0, {"ubus_rpc_session": "session%d"}]`, logins)
+
+			case "access":
+				if session == "session1" {
+					fmt.Fprint(w, `{
+						"jsonrpc": "2.0",
+						"id": 1,
+						"error": {"code": -32002, "message": "Access denied"}
+					}`)
+					return
+				}
+
+			case "changes":
+				result = `[0, {}]`
+
+			case "confirm":
+				confirmSessions = append(confirmSessions, session)
+			}
+
+			fmt.Fprintf(w, `{
+				"jsonrpc": "2.0",
+				"id": 1,
+				"result": %s
+			}`, result)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithRollback(2*time.Second),
+		)
+		assert.NilError(t, err)
+
+		// When
+		got, err := client.UpdateSection(
+			ctx,
+			"network",
+			"lan",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.Equal(t, logins, 2)
+		assert.DeepEqual(t, confirmSessions, []string{"session2"})
+	})
+
+	t.Run("keeps trying to confirm until the device is reachable", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var confirms int
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			var method string
+			err = json.Unmarshal(body.Params[2], &method)
+			assert.NilError(t, err)
+			result := `[0]`
+			switch method {
+			case "login":
+				result = `[0, {"ubus_rpc_session": "abc123"}]`
+
+			case "confirm":
+				confirms++
+				if confirms == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+			}
+
+			fmt.Fprintf(w, `{
+				"jsonrpc": "2.0",
+				"id": 1,
+				"result": %s
+			}`, result)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithRollback(4*time.Second),
+		)
+		assert.NilError(t, err)
+
+		// When
+		got, err := client.DeleteSection(
+			ctx,
+			"network",
+			"lan",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.Equal(t, confirms, 2)
+	})
+
+	t.Run("returns error when the device cannot be reached", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Params []json.RawMessage `json:"params"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&body)
+			assert.NilError(t, err)
+			var method string
+			err = json.Unmarshal(body.Params[2], &method)
+			assert.NilError(t, err)
+			result := `[0]`
+			switch method {
+			case "login":
+				result = `[0, {"ubus_rpc_session": "abc123"}]`
+
+			case "confirm":
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+
+			fmt.Fprintf(w, `{
+				"jsonrpc": "2.0",
+				"id": 1,
+				"result": %s
+			}`, result)
+		}
+		address, port, close := newServer(t, http.HandlerFunc(handle))
+		defer close()
+		client, err := lucirpc.NewUbusClient(
+			ctx,
+			address.Scheme,
+			address.Hostname(),
+			uint16(port),
+			"root",
+			"",
+			lucirpc.WithRollback(2*time.Second),
+		)
+		assert.NilError(t, err)
+
+		// When
+		_, err = client.CreateSection(
+			ctx,
+			"network",
+			"interface",
+			"lan",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "could not reach the device to confirm changes, it will roll back the changes")
+	})
+
+	t.Run("returns error when the device already rolled back", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			if call.Method == "confirm" {
+				return `[5]`
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(
+			t,
+			ctx,
+			handle,
+			lucirpc.WithRollback(2*time.Second),
+		)
+		defer close()
+
+		// When
+		_, err := client.DeleteSection(
+			ctx,
+			"network",
+			"lan",
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "unable to confirm changes, the device will roll back the changes: confirm changes error: no response")
+	})
+
+	t.Run("reverts every staged config when a change fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var mutex sync.Mutex
+		var reverts []string
+		handle := func(call ubusCall) string {
+			switch call.Method {
+			case "set":
+				if call.Arguments["config"] == "firewall" {
+					return `[4]`
+				}
+
+			case "revert":
+				mutex.Lock()
+				reverts = append(reverts, call.Arguments["config"].(string))
+				mutex.Unlock()
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(
+			t,
+			ctx,
+			handle,
+			lucirpc.WithCommitBatchWindow(100*time.Millisecond),
+			lucirpc.WithRollback(2*time.Second),
+		)
+		defer close()
+
+		// When
+		var wg sync.WaitGroup
+		var errNetwork error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errNetwork = client.UpdateSection(ctx, "network", "lan", lucirpc.Options{})
+		}()
+		time.Sleep(20 * time.Millisecond)
+		_, errFirewall := client.UpdateSection(ctx, "firewall", "missing", lucirpc.Options{})
+		wg.Wait()
+
+		// Then
+		assert.Check(t, errFirewall != nil)
+		assert.ErrorContains(t, errNetwork, "was able to update section, but it was reverted")
+		assert.DeepEqual(t, reverts, []string{"firewall", "network"})
+	})
+}
+
+func TestUbusClientRunServiceAction(t *testing.T) {
+	t.Run("runs the init action through rc", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.RunServiceAction(
+			ctx,
+			"dnsmasq",
+			lucirpc.ServiceRestart,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "rc",
+				Method:  "init",
+				Arguments: map[string]any{
+					"action": "restart",
+					"name":   "dnsmasq",
+				},
+			},
+		})
+	})
+
+	t.Run("returns false when the service does not exist", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[4]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.RunServiceAction(
+			ctx,
+			"nonexistent",
+			lucirpc.ServiceReload,
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, !got)
+	})
+}
+
+func TestUbusClientShowChanges(t *testing.T) {
+	t.Run("returns changes when successful", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[0, {
+				"changes": [
+					["set", "testing", "proto", "static"]
+				]
+			}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		want := [][]string{
+			{"set", "testing", "proto", "static"},
+		}
+		assert.DeepEqual(t, got, want)
+	})
+
+	t.Run("handles no changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		handle := func(call ubusCall) string {
+			return `[0, {}]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, handle)
+		defer close()
+
+		// When
+		got, err := client.ShowChanges(
+			ctx,
+			"network",
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.DeepEqual(t, got, [][]string{})
+	})
+}
+
+func TestUbusClientUpdateSection(t *testing.T) {
+	t.Run("sets the options and commits changes", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var calls []ubusCall
+		handle := func(call ubusCall) string {
+			calls = append(calls, call)
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		got, err := client.UpdateSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.Options{
+				"dns": lucirpc.ListString([]string{"1.1.1.1"}),
+			},
+		)
+
+		// Then
+		assert.NilError(t, err)
+		assert.Check(t, got)
+		assert.DeepEqual(t, calls, []ubusCall{
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "set",
+				Arguments: map[string]any{
+					"config":  "network",
+					"section": "testing",
+					"values": map[string]any{
+						"dns": []any{"1.1.1.1"},
+					},
+				},
+			},
+			{
+				Session: "abc123",
+				Object:  "uci",
+				Method:  "commit",
+				Arguments: map[string]any{
+					"config": "network",
+				},
+			},
+		})
+	})
+
+	t.Run("reverts changes when committing fails", func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+		var methods []string
+		handle := func(call ubusCall) string {
+			methods = append(methods, call.Method)
+			if call.Method == "commit" {
+				return `[9]`
+			}
+
+			return `[0]`
+		}
+		client, close := authenticatedUbusClient(t, ctx, withoutPendingUbusChanges(handle))
+		defer close()
+
+		// When
+		_, err := client.UpdateSection(
+			ctx,
+			"network",
+			"testing",
+			lucirpc.Options{},
+		)
+
+		// Then
+		assert.ErrorContains(t, err, "was able to update section, but could not commit changes")
+		assert.DeepEqual(t, methods, []string{"set", "commit", "revert"})
+	})
+}
+
+type ubusCall struct {
+	Arguments map[string]any
+	Method    string
+	Object    string
+	Session   string
+}
+
+func authenticatedUbusClient(
+	t *testing.T,
+	ctx context.Context,
+	handle func(ubusCall) string,
+	options ...lucirpc.ClientOption,
+) (*lucirpc.Client, func()) {
+	t.Helper()
+	handleWithAuth := func(call ubusCall) string {
+		if call.Object == "session" && call.Method == "login" {
+			return `[0, {"ubus_rpc_session": "abc123"}]`
+		}
+
+		return handle(call)
+	}
+	address, port, close := newServer(
+		t,
+		ubusHandler(t, handleWithAuth),
+	)
+	client, err := lucirpc.NewUbusClient(
+		ctx,
+		address.Scheme,
+		address.Hostname(),
+		uint16(port),
+		"root",
+		"",
+		options...,
+	)
+	if err != nil {
+		close()
+		assert.NilError(t, err)
+	}
+
+	return client, close
+}
+
+func ubusHandler(
+	t *testing.T,
+	handle func(ubusCall) string,
+) http.Handler {
+	t.Helper()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ubus" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.NilError(t, err)
+		assert.Equal(t, body.Method, "call")
+		assert.Equal(t, len(body.Params), 4)
+		var call ubusCall
+		assert.NilError(t, json.Unmarshal(body.Params[0], &call.Session))
+		assert.NilError(t, json.Unmarshal(body.Params[1], &call.Object))
+		assert.NilError(t, json.Unmarshal(body.Params[2], &call.Method))
+		assert.NilError(t, json.Unmarshal(body.Params[3], &call.Arguments))
+		fmt.Fprintf(w, `{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"result": %s
+		}`, handle(call))
+	})
+}
+
+// withoutPendingUbusChanges answers the check for pending changes like a device with nothing staged,
+// and passes every other call to `handle`.
+func withoutPendingUbusChanges(
+	handle func(ubusCall) string,
+) func(ubusCall) string {
+	return func(call ubusCall) string {
+		if call.Object == "uci" && call.Method == "changes" {
+			return `[0, {}]`
+		}
+
+		return handle(call)
+	}
+}
+
+// ubusAccessDeniedHandler answers calls with what `handle` returns,
+// and denies access to any call it returns nothing for.
+func ubusAccessDeniedHandler(
+	t *testing.T,
+	handle func(ubusCall) string,
+) http.Handler {
+	t.Helper()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		assert.NilError(t, err)
+		var call ubusCall
+		assert.NilError(t, json.Unmarshal(body.Params[0], &call.Session))
+		assert.NilError(t, json.Unmarshal(body.Params[1], &call.Object))
+		assert.NilError(t, json.Unmarshal(body.Params[2], &call.Method))
+		assert.NilError(t, json.Unmarshal(body.Params[3], &call.Arguments))
+		result := handle(call)
+		if result == "" {
+			fmt.Fprintf(w, `{
+				"jsonrpc": "2.0",
+				"id": 1,
+				"error": {"code": -32002, "message": "Access denied"}
+			}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"result": %s
+		}`, result)
+	})
+}
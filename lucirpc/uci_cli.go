@@ -0,0 +1,348 @@
+package lucirpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	uciStatementConfig  = "config"
+	uciStatementList    = "list"
+	uciStatementOption  = "option"
+	uciStatementPackage = "package"
+)
+
+// uciExportSection is a section read from the output of `uci export`.
+type uciExportSection struct {
+	name        string
+	options     map[string]any
+	sectionType string
+}
+
+// parseUCIChanges parses the output of `uci changes` for the `config`.
+// Each change is converted to the same form LuCI returns,
+// the operation followed by the section, and then any option and value.
+func parseUCIChanges(
+	config string,
+	output string,
+) ([][]string, error) {
+	statements, err := parseUCIStatements(output)
+	if err != nil {
+		return nil, err
+	}
+
+	result := [][]string{}
+	for _, statement := range statements {
+		change := strings.Join(statement, " ")
+		operation := "set"
+		switch {
+		case strings.HasPrefix(change, "-"):
+			operation = "remove"
+			change = change[1:]
+
+		case strings.HasPrefix(change, "@"):
+			operation = "rename"
+			change = change[1:]
+		}
+
+		path, value, hasValue := change, "", false
+		index := strings.IndexAny(change, "=^")
+		if index >= 0 {
+			path, value, hasValue = change[:index], change[index+1:], true
+			if change[index] == '^' || strings.HasPrefix(value, "^") {
+				operation = "order"
+				value = strings.TrimPrefix(value, "^")
+			}
+		}
+
+		switch {
+		case strings.HasSuffix(path, "+"):
+			operation = "list-add"
+			path = path[:len(path)-1]
+
+		case strings.HasSuffix(path, "-"):
+			operation = "list-del"
+			path = path[:len(path)-1]
+		}
+
+		parts := strings.Split(path, ".")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] != config {
+			return nil, fmt.Errorf("unexpected change to %q: %q", config, strings.Join(statement, " "))
+		}
+
+		parsed := append([]string{operation}, parts[1:]...)
+		if hasValue {
+			parsed = append(parsed, value)
+		}
+
+		result = append(result, parsed)
+	}
+
+	return result, nil
+}
+
+// parseUCIExports parses the output of `uci -N export` followed by `uci -n export` for the same config.
+// The first leaves anonymous sections unnamed,
+// and the second names every section.
+// The sections are in the same order in both,
+// so together they give the name of every section and whether it is anonymous.
+//
+// Each section is returned with the same metadata LuCI returns:
+// `.anonymous`, `.index`, `.name`, and `.type`.
+func parseUCIExports(
+	output string,
+) (map[string]Options, error) {
+	statements, err := parseUCIStatements(output)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := [][][]string{}
+	for _, statement := range statements {
+		if statement[0] == uciStatementPackage {
+			packages = append(packages, [][]string{})
+		}
+
+		if len(packages) == 0 {
+			return nil, fmt.Errorf("expected a %q statement, got %q", uciStatementPackage, statement[0])
+		}
+
+		packages[len(packages)-1] = append(packages[len(packages)-1], statement)
+	}
+
+	if len(packages) != 2 {
+		return nil, fmt.Errorf("expected the config to be exported twice, got %d exports", len(packages))
+	}
+
+	unnamedSections, err := parseUCIExport(packages[0])
+	if err != nil {
+		return nil, err
+	}
+
+	namedSections, err := parseUCIExport(packages[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unnamedSections) != len(namedSections) {
+		return nil, fmt.Errorf("expected both exports to have the same sections, got %d and %d sections", len(unnamedSections), len(namedSections))
+	}
+
+	result := map[string]Options{}
+	for index, section := range namedSections {
+		values := map[string]any{
+			".anonymous": unnamedSections[index].name == "",
+			".index":     index,
+			".name":      section.name,
+			".type":      section.sectionType,
+		}
+		for name, value := range section.options {
+			values[name] = value
+		}
+
+		// Go through JSON so the options are parsed exactly the same way as LuCI's.
+		raw, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize section %q: %w", section.name, err)
+		}
+
+		var options Options
+		err = json.Unmarshal(raw, &options)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse section %q: %w", section.name, err)
+		}
+
+		result[section.name] = options
+	}
+
+	return result, nil
+}
+
+// parseUCIExport converts the `statements` of a single `uci export` into sections.
+func parseUCIExport(
+	statements [][]string,
+) ([]uciExportSection, error) {
+	result := []uciExportSection{}
+	for _, statement := range statements {
+		switch statement[0] {
+		case uciStatementPackage:
+
+		case uciStatementConfig:
+			if len(statement) < 2 || len(statement) > 3 {
+				return nil, fmt.Errorf("expected %q to have a type and an optional name, got %q", uciStatementConfig, strings.Join(statement, " "))
+			}
+
+			section := uciExportSection{
+				options:     map[string]any{},
+				sectionType: statement[1],
+			}
+			if len(statement) == 3 {
+				section.name = statement[2]
+			}
+
+			result = append(result, section)
+
+		case uciStatementList, uciStatementOption:
+			if len(statement) != 3 {
+				return nil, fmt.Errorf("expected %q to have a name and a value, got %q", statement[0], strings.Join(statement, " "))
+			}
+
+			if len(result) == 0 {
+				return nil, fmt.Errorf("expected %q to be in a section", strings.Join(statement, " "))
+			}
+
+			options := result[len(result)-1].options
+			name, value := statement[1], statement[2]
+			if statement[0] == uciStatementOption {
+				options[name] = value
+				continue
+			}
+
+			list, _ := options[name].([]string)
+			options[name] = append(list, value)
+
+		default:
+			return nil, fmt.Errorf("unexpected statement %q", strings.Join(statement, " "))
+		}
+	}
+
+	return result, nil
+}
+
+// parseUCIStatements splits the `output` of `uci` into statements, each a list of words.
+// Words are quoted the same way as in a UCI config file,
+// so a quoted value can contain spaces or span lines.
+func parseUCIStatements(
+	output string,
+) ([][]string, error) {
+	statements := [][]string{}
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+		}
+
+		word.Reset()
+		inWord = false
+	}
+	endStatement := func() {
+		endWord()
+		if len(words) > 0 {
+			statements = append(statements, words)
+		}
+
+		words = []string{}
+	}
+
+	for i := 0; i < len(output); i++ {
+		c := output[i]
+		switch c {
+		case '\'':
+			end := strings.IndexByte(output[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", output[i:])
+			}
+
+			word.WriteString(output[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+
+		case '"':
+			i++
+			for ; i < len(output) && output[i] != '"'; i++ {
+				if output[i] == '\\' && i+1 < len(output) {
+					i++
+				}
+
+				word.WriteByte(output[i])
+			}
+
+			if i >= len(output) {
+				return nil, fmt.Errorf("unterminated quote in %q", output)
+			}
+
+			inWord = true
+
+		case '\\':
+			if i+1 < len(output) {
+				i++
+				word.WriteByte(output[i])
+			}
+
+			inWord = true
+
+		case '\n':
+			endStatement()
+
+		case ' ', '\t', '\r':
+			endWord()
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	endStatement()
+	return statements, nil
+}
+
+// uciQuote quotes the `value` so `uci` (and the shell) treat it as a single word.
+func uciQuote(
+	value string,
+) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// uciValues converts an `option` to the values `uci` stores.
+// Lists have any number of values,
+// everything else has exactly one.
+func uciValues(
+	option Option,
+) ([]string, bool, error) {
+	raw, err := json.Marshal(option)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var value any
+	err = json.Unmarshal(raw, &value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch value := value.(type) {
+	case bool:
+		if value {
+			return []string{"1"}, false, nil
+		}
+
+		return []string{"0"}, false, nil
+
+	case float64:
+		return []string{strconv.FormatFloat(value, 'f', -1, 64)}, false, nil
+
+	case string:
+		return []string{value}, false, nil
+
+	case []any:
+		result := []string{}
+		for _, element := range value {
+			s, ok := element.(string)
+			if !ok {
+				return nil, false, fmt.Errorf("expected a list of strings, got %v", value)
+			}
+
+			result = append(result, s)
+		}
+
+		return result, true, nil
+
+	default:
+		return nil, false, fmt.Errorf("unexpected value %v", value)
+	}
+}
@@ -0,0 +1,353 @@
+package lucirpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// stager stages the operations of a change to a single config.
+//
+// If the [Client] could not confirm that nobody else had staged changes in the config,
+// reverting the whole config would throw those changes away too.
+// So, unless the config is known to be clean,
+// each operation first records how to undo it on the `lock`.
+// See [Client.revertLocked] for where they are undone.
+type stager struct {
+	config    string
+	lock      *configLock
+	transport transport
+}
+
+// undoFunc stages the opposite of an operation the [Client] staged.
+type undoFunc func(ctx context.Context) error
+
+// addSection stages an anonymous section,
+// which is undone by deleting it.
+func (s stager) addSection(
+	ctx context.Context,
+	sectionType string,
+	options Options,
+) (string, error) {
+	section, err := s.transport.addSection(ctx, s.config, sectionType, options)
+	if err != nil {
+		return "", err
+	}
+
+	s.record(func(ctx context.Context) error {
+		return undoResult(s.transport.deleteSection(ctx, s.config, section))
+	})
+	return section, nil
+}
+
+// createSection stages a named section.
+// A new section is undone by deleting it,
+// and an existing section by restoring the options it had.
+func (s stager) createSection(
+	ctx context.Context,
+	sectionType string,
+	section string,
+	options Options,
+) (bool, error) {
+	var previous Options
+	if s.recording() {
+		var err error
+		previous, err = s.transport.getSection(ctx, s.config, section)
+		if err != nil && !errors.As(err, &NotFoundError{}) {
+			return false, fmt.Errorf("unable to record how to undo the change: %w", err)
+		}
+	}
+
+	result, err := s.transport.createSection(ctx, s.config, sectionType, section, options)
+	if err != nil || !result {
+		return result, err
+	}
+
+	if previous == nil {
+		s.record(func(ctx context.Context) error {
+			return undoResult(s.transport.deleteSection(ctx, s.config, section))
+		})
+	} else {
+		s.recordRestoreOptions(section, previous, optionNames(options))
+	}
+
+	return true, nil
+}
+
+// deleteOptions stages removing `options` from a section,
+// which is undone by setting them to what they were.
+func (s stager) deleteOptions(
+	ctx context.Context,
+	section string,
+	options []string,
+) (bool, error) {
+	previous, found, err := s.previousSection(ctx, section)
+	if err != nil || !found {
+		return false, err
+	}
+
+	result, err := s.transport.deleteOptions(ctx, s.config, section, options)
+	if err != nil || !result {
+		return result, err
+	}
+
+	s.recordRestoreOptions(section, previous, options)
+	return true, nil
+}
+
+// deleteSection stages removing a section,
+// which is undone by creating it again where it was.
+// An anonymous section comes back with the name it had,
+// as UCI cannot add an anonymous section with a given name.
+func (s stager) deleteSection(
+	ctx context.Context,
+	section string,
+) (bool, error) {
+	previous, found, err := s.previousSection(ctx, section)
+	if err != nil || !found {
+		return false, err
+	}
+
+	var (
+		index       int
+		sectionType string
+	)
+	if s.recording() {
+		sectionType, err = previous.GetString(".type")
+		if err != nil {
+			return false, NewProtocolError(fmt.Errorf("unable to find the type of section %q: %w", section, err))
+		}
+
+		index, err = s.sectionIndex(ctx, section)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	result, err := s.transport.deleteSection(ctx, s.config, section)
+	if err != nil || !result {
+		return result, err
+	}
+
+	s.record(func(ctx context.Context) error {
+		err := undoResult(s.transport.createSection(ctx, s.config, sectionType, section, withoutMetadata(previous)))
+		if err != nil {
+			return err
+		}
+
+		return undoResult(s.transport.reorderSection(ctx, s.config, section, index))
+	})
+	return true, nil
+}
+
+// reorderSection stages moving a section to the `index`,
+// which is undone by moving it back to where it was.
+// If there is no `index`, there is nothing to do.
+func (s stager) reorderSection(
+	ctx context.Context,
+	section string,
+	index *int,
+) (bool, error) {
+	if index == nil {
+		return true, nil
+	}
+
+	var previous int
+	if s.recording() {
+		var err error
+		previous, err = s.sectionIndex(ctx, section)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	result, err := s.transport.reorderSection(ctx, s.config, section, *index)
+	if err != nil || !result {
+		return result, err
+	}
+
+	s.record(func(ctx context.Context) error {
+		return undoResult(s.transport.reorderSection(ctx, s.config, section, previous))
+	})
+	return true, nil
+}
+
+// updateSection stages setting `options` on a section,
+// which is undone by setting them to what they were,
+// and removing the ones it did not have.
+func (s stager) updateSection(
+	ctx context.Context,
+	section string,
+	options Options,
+) (bool, error) {
+	previous, found, err := s.previousSection(ctx, section)
+	if err != nil || !found {
+		return false, err
+	}
+
+	result, err := s.transport.updateSection(ctx, s.config, section, options)
+	if err != nil || !result {
+		return result, err
+	}
+
+	s.recordRestoreOptions(section, previous, optionNames(options))
+	return true, nil
+}
+
+// previousSection reads a section before it is changed,
+// so the change can be undone.
+// If the section does not exist, it is not found.
+// If nothing is being recorded, the section is not read.
+func (s stager) previousSection(
+	ctx context.Context,
+	section string,
+) (Options, bool, error) {
+	if !s.recording() {
+		return nil, true, nil
+	}
+
+	previous, err := s.transport.getSection(ctx, s.config, section)
+	if errors.As(err, &NotFoundError{}) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to record how to undo the change: %w", err)
+	}
+
+	return previous, true, nil
+}
+
+// record adds how to undo an operation that was staged,
+// unless the config is known to be clean.
+func (s stager) record(
+	undo undoFunc,
+) {
+	if !s.recording() {
+		return
+	}
+
+	s.lock.undo[s.config] = append(s.lock.undo[s.config], undo)
+}
+
+// recordRestoreOptions records how to put the `options` of a section back to the `previous` values.
+// Options it did not have before are removed.
+func (s stager) recordRestoreOptions(
+	section string,
+	previous Options,
+	options []string,
+) {
+	restore := Options{}
+	remove := []string{}
+	for _, option := range options {
+		value, ok := previous[option]
+		if ok {
+			restore[option] = value
+		} else {
+			remove = append(remove, option)
+		}
+	}
+
+	s.record(func(ctx context.Context) error {
+		if len(restore) > 0 {
+			err := undoResult(s.transport.updateSection(ctx, s.config, section, restore))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(remove) > 0 {
+			return undoResult(s.transport.deleteOptions(ctx, s.config, section, remove))
+		}
+
+		return nil
+	})
+}
+
+// recording checks if operations need to record how to undo them.
+func (s stager) recording() bool {
+	return !s.lock.clean[s.config]
+}
+
+// sectionIndex finds where a section is among every section of the config.
+func (s stager) sectionIndex(
+	ctx context.Context,
+	section string,
+) (int, error) {
+	sections, err := s.transport.listSections(ctx, s.config, "")
+	if err != nil {
+		return 0, fmt.Errorf("unable to record how to undo the change: %w", err)
+	}
+
+	for index, options := range sections {
+		name, err := options.GetString(".name")
+		if err == nil && name == section {
+			return index, nil
+		}
+	}
+
+	return 0, NewNotFoundError(s.config, section)
+}
+
+// undoStaged stages the opposite of every operation the [Client] staged in the `config`,
+// latest first.
+func (l *configLock) undoStaged(
+	ctx context.Context,
+	config string,
+) error {
+	undos := l.undo[config]
+	errs := []error{}
+	for index := len(undos) - 1; index >= 0; index-- {
+		err := undos[index](ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// optionNames are the names of the `options`.
+func optionNames(
+	options Options,
+) []string {
+	names := []string{}
+	for option := range options {
+		names = append(names, option)
+	}
+
+	return names
+}
+
+// undoResult turns an operation the device refused into an error,
+// as an undo has nothing else to report.
+func undoResult(
+	result bool,
+	err error,
+) error {
+	if err != nil {
+		return err
+	}
+
+	if !result {
+		return errors.New("the device refused to undo a change")
+	}
+
+	return nil
+}
+
+// withoutMetadata removes the metadata LuCI adds to a section (e.g. `.name`),
+// leaving only its options.
+func withoutMetadata(
+	options Options,
+) Options {
+	result := Options{}
+	for option, value := range options {
+		if !strings.HasPrefix(option, ".") {
+			result[option] = value
+		}
+	}
+
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/network/networkinterface"
 )
 
 const (
@@ -116,6 +117,7 @@ var (
 	interfaceSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       interfaceAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetInterface, interfaceAttribute, interfaceUCIOption),
+		References:        []lucirpcglue.SectionReference{networkinterface.Reference},
 		ResourceExistence: lucirpcglue.NoValidation,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetInterface, interfaceAttribute, interfaceUCIOption),
 		Validators: []validator.String{
@@ -182,6 +184,7 @@ var (
 		interfaceAttribute:                interfaceSchemaAttribute,
 		leaseTimeAttribute:                leaseTimeSchemaAttribute,
 		limitAttribute:                    limitSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
 		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
 		routerAdvertisementFlagsAttribute: routerAdvertisementFlagsSchemaAttribute,
 		routerAdvertisementModeAttribute:  routerAdvertisementModeSchemaAttribute,
@@ -209,6 +212,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -223,6 +235,7 @@ type model struct {
 	DHCPv4Mode               types.String `tfsdk:"dhcpv4"`
 	DHCPv6Mode               types.String `tfsdk:"dhcpv6"`
 	Force                    types.Bool   `tfsdk:"force"`
+	TargetDevice             types.String `tfsdk:"target_device"`
 	Id                       types.String `tfsdk:"id"`
 	Ignore                   types.Bool   `tfsdk:"ignore"`
 	Interface                types.String `tfsdk:"interface"`
@@ -236,6 +249,7 @@ type model struct {
 func modelGetDHCPv4Mode(m model) types.String              { return m.DHCPv4Mode }
 func modelGetDHCPv6Mode(m model) types.String              { return m.DHCPv6Mode }
 func modelGetForce(m model) types.Bool                     { return m.Force }
+func modelGetTargetDevice(m model) types.String            { return m.TargetDevice }
 func modelGetId(m model) types.String                      { return m.Id }
 func modelGetIgnore(m model) types.Bool                    { return m.Ignore }
 func modelGetInterface(m model) types.String               { return m.Interface }
@@ -248,6 +262,7 @@ func modelGetStart(m model) types.Int64                    { return m.Start }
 func modelSetDHCPv4Mode(m *model, value types.String)              { m.DHCPv4Mode = value }
 func modelSetDHCPv6Mode(m *model, value types.String)              { m.DHCPv6Mode = value }
 func modelSetForce(m *model, value types.Bool)                     { m.Force = value }
+func modelSetTargetDevice(m *model, value types.String)            { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)                      { m.Id = value }
 func modelSetIgnore(m *model, value types.Bool)                    { m.Ignore = value }
 func modelSetInterface(m *model, value types.String)               { m.Interface = value }
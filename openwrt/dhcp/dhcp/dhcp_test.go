@@ -0,0 +1,172 @@
+package dhcp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("dhcp", "dhcp", "testing", map[string]any{
+		"interface": "testing",
+		"leasetime": "12h",
+		"limit":     150,
+		"start":     100,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_dhcp" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dhcp.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dhcp.testing", "interface", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dhcp.testing", "leasetime", "12h"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dhcp.testing", "limit", "150"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dhcp.testing", "start", "100"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_dhcp" "testing" {
+	id = "testing"
+	ignore = true
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "ignore", "true"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv4"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv6"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "interface"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "leasetime"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "limit"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "ra_flags"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_dhcp.testing", "start"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_dhcp.testing",
+	}
+	ignoreWithOtherAttributes := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_dhcp" "testing" {
+	dhcpv4 = "server"
+	dhcpv6 = "disabled"
+	id = "testing"
+	ignore = true
+	interface = "testing"
+	leasetime = "12h"
+	limit = 150
+	ra_flags = [
+		"managed-config",
+		"other-config",
+	]
+	start = 100
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv4", "server"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv6", "disabled"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "ignore", "true"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "interface", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "leasetime", "12h"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "limit", "150"),
+			resource.TestCheckTypeSetElemAttr("openwrt_dhcp_dhcp.testing", "ra_flags.*", "managed-config"),
+			resource.TestCheckTypeSetElemAttr("openwrt_dhcp_dhcp.testing", "ra_flags.*", "other-config"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "start", "100"),
+		),
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_dhcp" "testing" {
+	dhcpv4 = "server"
+	dhcpv6 = "relay"
+	id = "testing"
+	interface = "testing"
+	leasetime = "12h"
+	limit = 150
+	ra_flags = [
+		"managed-config",
+		"other-config",
+	]
+	start = 100
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv4", "server"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "dhcpv6", "relay"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "interface", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "leasetime", "12h"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "limit", "150"),
+			resource.TestCheckTypeSetElemAttr("openwrt_dhcp_dhcp.testing", "ra_flags.*", "managed-config"),
+			resource.TestCheckTypeSetElemAttr("openwrt_dhcp_dhcp.testing", "ra_flags.*", "other-config"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dhcp.testing", "start", "100"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("dhcp"),
+					"dhcpv4":     lucirpc.String("server"),
+					"dhcpv6":     lucirpc.String("relay"),
+					"interface":  lucirpc.String("testing"),
+					"leasetime":  lucirpc.String("12h"),
+					"limit":      lucirpc.Integer(150),
+					"ra_flags":   lucirpc.ListString([]string{"managed-config", "other-config"}),
+					"start":      lucirpc.Integer(100),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		ignoreWithOtherAttributes,
+		updateAndReadResource,
+	)
+}
@@ -160,20 +160,21 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		authoritativeModeAttribute: authoritativeModeSchemaAttribute,
-		domainAttribute:            domainSchemaAttribute,
-		domainNeededAttribute:      domainNeededSchemaAttribute,
-		ednsPacketMaxAttribute:     ednsPacketMaxSchemaAttribute,
-		expandHostsAttribute:       expandHostsSchemaAttribute,
-		leaseFileAttribute:         leaseFileSchemaAttribute,
-		localizeQueriesAttribute:   localizeQueriesSchemaAttribute,
-		localLookupAttribute:       localLookupSchemaAttribute,
-		localServiceAttribute:      localServiceSchemaAttribute,
-		lucirpcglue.IdAttribute:    lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		readEthersAttribute:        readEthersSchemaAttribute,
-		rebindLocalhostAttribute:   rebindLocalhostSchemaAttribute,
-		rebindProtectionAttribute:  rebindProtectionSchemaAttribute,
-		resolvFileAttribute:        resolvFileSchemaAttribute,
+		authoritativeModeAttribute:        authoritativeModeSchemaAttribute,
+		domainAttribute:                   domainSchemaAttribute,
+		domainNeededAttribute:             domainNeededSchemaAttribute,
+		ednsPacketMaxAttribute:            ednsPacketMaxSchemaAttribute,
+		expandHostsAttribute:              expandHostsSchemaAttribute,
+		leaseFileAttribute:                leaseFileSchemaAttribute,
+		localizeQueriesAttribute:          localizeQueriesSchemaAttribute,
+		localLookupAttribute:              localLookupSchemaAttribute,
+		localServiceAttribute:             localServiceSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		readEthersAttribute:               readEthersSchemaAttribute,
+		rebindLocalhostAttribute:          rebindLocalhostSchemaAttribute,
+		rebindProtectionAttribute:         rebindProtectionSchemaAttribute,
+		resolvFileAttribute:               resolvFileSchemaAttribute,
 	}
 )
 
@@ -187,6 +188,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -203,6 +213,7 @@ type model struct {
 	DomainNeeded      types.Bool   `tfsdk:"domainneeded"`
 	EDNSPacketMax     types.Int64  `tfsdk:"ednspacket_max"`
 	ExpandHosts       types.Bool   `tfsdk:"expandhosts"`
+	TargetDevice      types.String `tfsdk:"target_device"`
 	Id                types.String `tfsdk:"id"`
 	LeaseFile         types.String `tfsdk:"leasefile"`
 	LocalizeQueries   types.Bool   `tfsdk:"localise_queries"`
@@ -219,6 +230,7 @@ func modelGetDomain(m model) types.String          { return m.Domain }
 func modelGetDomainNeeded(m model) types.Bool      { return m.DomainNeeded }
 func modelGetEDNSPacketMax(m model) types.Int64    { return m.EDNSPacketMax }
 func modelGetExpandHosts(m model) types.Bool       { return m.ExpandHosts }
+func modelGetTargetDevice(m model) types.String    { return m.TargetDevice }
 func modelGetId(m model) types.String              { return m.Id }
 func modelGetLeaseFile(m model) types.String       { return m.LeaseFile }
 func modelGetLocalizeQueries(m model) types.Bool   { return m.LocalizeQueries }
@@ -234,6 +246,7 @@ func modelSetDomain(m *model, value types.String)          { m.Domain = value }
 func modelSetDomainNeeded(m *model, value types.Bool)      { m.DomainNeeded = value }
 func modelSetEDNSPacketMax(m *model, value types.Int64)    { m.EDNSPacketMax = value }
 func modelSetExpandHosts(m *model, value types.Bool)       { m.ExpandHosts = value }
+func modelSetTargetDevice(m *model, value types.String)    { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)              { m.Id = value }
 func modelSetLeaseFile(m *model, value types.String)       { m.LeaseFile = value }
 func modelSetLocalizeQueries(m *model, value types.Bool)   { m.LocalizeQueries = value }
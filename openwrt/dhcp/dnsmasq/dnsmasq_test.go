@@ -0,0 +1,118 @@
+package dnsmasq_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("dhcp", "dnsmasq", "testing", map[string]any{
+		"domain":            "testing",
+		"rebind_protection": true,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_dnsmasq" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dnsmasq.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dnsmasq.testing", "domain", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_dnsmasq.testing", "rebind_protection", "true"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_dnsmasq" "testing" {
+	domain = "testing"
+	id = "testing"
+	rebind_protection = true
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "domain", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "rebind_protection", "true"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_dnsmasq.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_dnsmasq" "testing" {
+	domain = "testing"
+	expandhosts = true
+	id = "testing"
+	local = "/testing/"
+	rebind_localhost = true
+	rebind_protection = true
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "domain", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "expandhosts", "true"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "local", "/testing/"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "rebind_localhost", "true"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_dnsmasq.testing", "rebind_protection", "true"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous":        lucirpc.Boolean(false),
+					".name":             lucirpc.String("testing"),
+					".type":             lucirpc.String("dnsmasq"),
+					"domain":            lucirpc.String("testing"),
+					"expandhosts":       lucirpc.Boolean(true),
+					"local":             lucirpc.String("/testing/"),
+					"rebind_localhost":  lucirpc.Boolean(true),
+					"rebind_protection": lucirpc.Boolean(true),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
@@ -51,9 +51,10 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		hostnameAttribute:       hostnameSchemaAttribute,
-		ipAddressAttribute:      ipAddressSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		hostnameAttribute:                 hostnameSchemaAttribute,
+		ipAddressAttribute:                ipAddressSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
 	}
 )
 
@@ -67,6 +68,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -78,15 +88,18 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Hostname  types.String `tfsdk:"name"`
-	Id        types.String `tfsdk:"id"`
-	IPAddress types.String `tfsdk:"ip"`
+	Hostname     types.String `tfsdk:"name"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	IPAddress    types.String `tfsdk:"ip"`
 }
 
-func modelGetHostname(m model) types.String  { return m.Hostname }
-func modelGetId(m model) types.String        { return m.Id }
-func modelGetIPAddress(m model) types.String { return m.IPAddress }
+func modelGetHostname(m model) types.String     { return m.Hostname }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetIPAddress(m model) types.String    { return m.IPAddress }
 
-func modelSetHostname(m *model, value types.String)  { m.Hostname = value }
-func modelSetId(m *model, value types.String)        { m.Id = value }
-func modelSetIPAddress(m *model, value types.String) { m.IPAddress = value }
+func modelSetHostname(m *model, value types.String)     { m.Hostname = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetIPAddress(m *model, value types.String)    { m.IPAddress = value }
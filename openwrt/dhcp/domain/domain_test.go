@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("dhcp", "domain", "testing", map[string]any{
+		"ip":   "192.168.1.50",
+		"name": "testing",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_domain" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_domain.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_domain.testing", "ip", "192.168.1.50"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_domain.testing", "name", "testing"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_domain" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	name = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "ip", "192.168.1.50"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "name", "testing"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_domain.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_domain" "testing" {
+	id = "testing"
+	ip = "192.168.1.51"
+	name = "testing-1"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "ip", "192.168.1.51"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_domain.testing", "name", "testing-1"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("domain"),
+					"ip":         lucirpc.String("192.168.1.51"),
+					"name":       lucirpc.String("testing-1"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
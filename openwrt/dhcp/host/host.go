@@ -82,11 +82,12 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		addDNSEntriesAttribute:  addDNSEntriesSchemaAttribute,
-		hostnameAttribute:       hostnameSchemaAttribute,
-		ipAddressAttribute:      ipAddressSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		macAddressAttribute:     macAddressSchemaAttribute,
+		addDNSEntriesAttribute:            addDNSEntriesSchemaAttribute,
+		hostnameAttribute:                 hostnameSchemaAttribute,
+		ipAddressAttribute:                ipAddressSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		macAddressAttribute:               macAddressSchemaAttribute,
 	}
 )
 
@@ -100,6 +101,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -113,19 +123,22 @@ func NewResource() resource.Resource {
 type model struct {
 	AddDNSEntries types.Bool   `tfsdk:"dns"`
 	Hostname      types.String `tfsdk:"name"`
+	TargetDevice  types.String `tfsdk:"target_device"`
 	Id            types.String `tfsdk:"id"`
 	IPAddress     types.String `tfsdk:"ip"`
 	MACAddress    types.String `tfsdk:"mac"`
 }
 
-func modelGetAddDNSEntries(m model) types.Bool { return m.AddDNSEntries }
-func modelGetHostname(m model) types.String    { return m.Hostname }
-func modelGetId(m model) types.String          { return m.Id }
-func modelGetIPAddress(m model) types.String   { return m.IPAddress }
-func modelGetMACAddress(m model) types.String  { return m.MACAddress }
-
-func modelSetAddDNSEntries(m *model, value types.Bool) { m.AddDNSEntries = value }
-func modelSetHostname(m *model, value types.String)    { m.Hostname = value }
-func modelSetId(m *model, value types.String)          { m.Id = value }
-func modelSetIPAddress(m *model, value types.String)   { m.IPAddress = value }
-func modelSetMACAddress(m *model, value types.String)  { m.MACAddress = value }
+func modelGetAddDNSEntries(m model) types.Bool  { return m.AddDNSEntries }
+func modelGetHostname(m model) types.String     { return m.Hostname }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetIPAddress(m model) types.String    { return m.IPAddress }
+func modelGetMACAddress(m model) types.String   { return m.MACAddress }
+
+func modelSetAddDNSEntries(m *model, value types.Bool)  { m.AddDNSEntries = value }
+func modelSetHostname(m *model, value types.String)     { m.Hostname = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetIPAddress(m *model, value types.String)    { m.IPAddress = value }
+func modelSetMACAddress(m *model, value types.String)   { m.MACAddress = value }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/joneshf/terraform-provider-openwrt/internal/acceptancetest"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 	"gotest.tools/v3/assert"
@@ -58,6 +59,56 @@ data "openwrt_dhcp_host" "testing" {
 	)
 }
 
+func TestListDataSourceAcceptance(t *testing.T) {
+	ctx := context.Background()
+	openWrtServer := acceptancetest.RunOpenWrtServer(
+		ctx,
+		*dockerPool,
+		t,
+	)
+	client := openWrtServer.LuCIRPCClient(
+		ctx,
+		t,
+	)
+	providerBlock := openWrtServer.ProviderBlock()
+	ok, err := client.CreateSection(ctx, "dhcp", "host", "first", lucirpc.Options{
+		"ip":   lucirpc.String("192.168.1.50"),
+		"name": lucirpc.String("first"),
+	})
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+	ok, err = client.CreateSection(ctx, "dhcp", "host", "second", lucirpc.Options{
+		"ip":   lucirpc.String("192.168.1.51"),
+		"name": lucirpc.String("second"),
+	})
+	assert.NilError(t, err)
+	assert.Check(t, ok)
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_hosts" "testing" {
+	filter = {
+		name = "second"
+	}
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.#", "1"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.id", "second"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.ip", "192.168.1.51"),
+		),
+	}
+
+	acceptancetest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
 func TestResourceAcceptance(t *testing.T) {
 	ctx := context.Background()
 	openWrtServer := acceptancetest.RunOpenWrtServer(
@@ -65,6 +116,10 @@ func TestResourceAcceptance(t *testing.T) {
 		*dockerPool,
 		t,
 	)
+	client := openWrtServer.LuCIRPCClient(
+		ctx,
+		t,
+	)
 	providerBlock := openWrtServer.ProviderBlock()
 
 	createAndReadResource := resource.TestStep{
@@ -111,10 +166,37 @@ resource "openwrt_dhcp_host" "testing" {
 		),
 	}
 
+	recreateDeletedResource := resource.TestStep{
+		PreConfig: func() {
+			ok, err := client.DeleteSection(ctx, "dhcp", "testing")
+			assert.NilError(t, err)
+			assert.Check(t, ok)
+		},
+		Config: updateAndReadResource.Config,
+		Check:  updateAndReadResource.Check,
+	}
+
+	removeOptionFromResource := resource.TestStep{
+		Config: createAndReadResource.Config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "id", "testing"),
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_host.testing", "name"),
+			func(*terraform.State) error {
+				section, err := client.GetSection(ctx, "dhcp", "testing")
+				assert.NilError(t, err)
+				_, ok := section["name"]
+				assert.Check(t, !ok)
+				return nil
+			},
+		),
+	}
+
 	acceptancetest.TerraformSteps(
 		t,
 		createAndReadResource,
 		importValidation,
 		updateAndReadResource,
+		recreateDeletedResource,
+		removeOptionFromResource,
 	)
 }
@@ -0,0 +1,442 @@
+package host_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestListDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("dhcp", "host", "first", map[string]any{
+		"ip":   "10.0.5.10",
+		"name": "first",
+	})
+	server.SetSection("dhcp", "host", "second", map[string]any{
+		"ip":   "10.0.6.10",
+		"name": "second",
+	})
+	server.SetSection("dhcp", "host", "third", map[string]any{
+		"ip":   "10.0.5.11",
+		"name": "third",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readAllSections := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_hosts" "testing" {
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.#", "3"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.id", "first"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.1.id", "second"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.2.id", "third"),
+		),
+	}
+	readFilteredSections := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_hosts" "testing" {
+	filter = {
+		name = "second"
+	}
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.#", "1"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.id", "second"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.ip", "10.0.6.10"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readAllSections,
+		readFilteredSections,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	name = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "ip", "192.168.1.50"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "mac", "12:34:56:78:90:ab"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "name", "testing"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_host.testing",
+	}
+	removeOptionFromResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckNoResourceAttr("openwrt_dhcp_host.testing", "name"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("host"),
+					"ip":         lucirpc.String("192.168.1.50"),
+					"mac":        lucirpc.String("12:34:56:78:90:ab"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		removeOptionFromResource,
+	)
+}
+
+func TestResourceLifecycle(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+	config := fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	name = "testing"
+}
+`,
+		providerBlock,
+	)
+
+	createResource := resource.TestStep{
+		Config: config,
+		Check: func(*terraform.State) error {
+			_, ok := server.CommittedSection("dhcp", "testing")
+			assert.Check(t, ok)
+			return nil
+		},
+	}
+	recreateRemovedResource := resource.TestStep{
+		PreConfig: func() {
+			assert.Check(t, server.RemoveSection("dhcp", "testing"))
+		},
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "id", "testing"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got["name"], lucirpc.String("testing"))
+				return nil
+			},
+		),
+	}
+	deleteResource := resource.TestStep{
+		Config: providerBlock,
+		Check: func(*terraform.State) error {
+			_, ok := server.CommittedSection("dhcp", "testing")
+			assert.Check(t, !ok)
+			return nil
+		},
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createResource,
+		recreateRemovedResource,
+		deleteResource,
+	)
+}
+
+func TestResourceTargetDevice(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	office := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock(fmt.Sprintf("devices = {%s}", office.DeviceAttribute("office")))
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	target_device = "office"
+}
+
+data "openwrt_dhcp_hosts" "testing" {
+	target_device = openwrt_dhcp_host.testing.target_device
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "target_device", "office"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.#", "1"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_hosts.testing", "sections.0.target_device", "office"),
+			func(*terraform.State) error {
+				_, ok := office.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				_, ok = server.CommittedSection("dhcp", "testing")
+				assert.Check(t, !ok)
+				return nil
+			},
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateId:     "office/testing",
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_host.testing",
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+	)
+}
+
+func TestResourceMissingConfig(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetConfigs("network", "system")
+	office := lucirpctest.NewServer(t)
+	office.SetConfigs("dhcp", "network", "system")
+	providerBlock := server.ProviderBlock(fmt.Sprintf("devices = {%s}", office.DeviceAttribute("office")))
+
+	createResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+}
+`,
+			providerBlock,
+		),
+		ExpectError: regexp.MustCompile(`The device does not have the "dhcp" UCI config`),
+	}
+	readListDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_hosts" "testing" {}
+`,
+			providerBlock,
+		),
+		ExpectError: regexp.MustCompile(`Missing UCI config`),
+	}
+	createResourceOnOtherDevice := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	id = "testing"
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	target_device = "office"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			func(*terraform.State) error {
+				_, ok := office.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createResource,
+		readListDataSource,
+		createResourceOnOtherDevice,
+	)
+}
+
+func TestResourceSectionIds(t *testing.T) {
+	t.Run("names sections after an attribute", func(t *testing.T) {
+		server := lucirpctest.NewServer(t)
+		providerBlock := server.ProviderBlock(`section_id_strategy = "attribute"`)
+
+		createAndReadResource := resource.TestStep{
+			Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	name = "my-laptop"
+}
+`,
+				providerBlock,
+			),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "id", "my_laptop"),
+				func(*terraform.State) error {
+					_, ok := server.CommittedSection("dhcp", "my_laptop")
+					assert.Check(t, ok)
+					return nil
+				},
+			),
+		}
+
+		lucirpctest.TerraformSteps(
+			t,
+			createAndReadResource,
+		)
+	})
+
+	t.Run("creates anonymous sections", func(t *testing.T) {
+		server := lucirpctest.NewServer(t)
+		providerBlock := server.ProviderBlock(`section_id_strategy = "anonymous"`)
+
+		createAndReadResource := resource.TestStep{
+			Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "testing" {
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	name = "testing"
+}
+`,
+				providerBlock,
+			),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.testing", "id", "cfg01fe63"),
+				func(*terraform.State) error {
+					got, ok := server.CommittedSection("dhcp", "cfg01fe63")
+					assert.Check(t, ok)
+					assert.DeepEqual(t, got[".anonymous"], lucirpc.Boolean(true))
+					return nil
+				},
+			),
+		}
+		importValidation := resource.TestStep{
+			ImportState:       true,
+			ImportStateVerify: true,
+			ResourceName:      "openwrt_dhcp_host.testing",
+		}
+
+		lucirpctest.TerraformSteps(
+			t,
+			createAndReadResource,
+			importValidation,
+		)
+	})
+
+	t.Run("finds anonymous sections again after an earlier section is deleted", func(t *testing.T) {
+		server := lucirpctest.NewServer(t)
+		providerBlock := server.ProviderBlock(`section_id_strategy = "anonymous"`)
+
+		createAndReadResources := resource.TestStep{
+			Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "first" {
+	ip = "192.168.1.50"
+	mac = "12:34:56:78:90:ab"
+	name = "first"
+}
+
+resource "openwrt_dhcp_host" "second" {
+	depends_on = [openwrt_dhcp_host.first]
+
+	ip = "192.168.1.51"
+	mac = "12:34:56:78:90:cd"
+	name = "second"
+}
+`,
+				providerBlock,
+			),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.first", "id", "cfg01fe63"),
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.second", "id", "cfg02fe63"),
+			),
+		}
+		deleteFirstResource := resource.TestStep{
+			Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_host" "second" {
+	ip = "192.168.1.51"
+	mac = "12:34:56:78:90:cd"
+	name = "second"
+}
+`,
+				providerBlock,
+			),
+			Check: func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "@host[0]")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got["name"], lucirpc.String("second"))
+				_, ok = server.CommittedSection("dhcp", "@host[1]")
+				assert.Check(t, !ok)
+				return nil
+			},
+		}
+		readRenamedResource := resource.TestStep{
+			RefreshState: true,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.second", "id", "cfg01fe63"),
+				resource.TestCheckResourceAttr("openwrt_dhcp_host.second", "name", "second"),
+			),
+		}
+
+		lucirpctest.TerraformSteps(
+			t,
+			createAndReadResources,
+			deleteFirstResource,
+			readRenamedResource,
+		)
+	})
+}
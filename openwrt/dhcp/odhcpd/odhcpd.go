@@ -77,12 +77,13 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		leaseFileAttribute:      leaseFileSchemaAttribute,
-		leaseTriggerAttribute:   leaseTriggerSchemaAttribute,
-		legacyAttribute:         legacySchemaAttribute,
-		logLevelAttribute:       logLevelSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		mainDHCPAttribute:       mainDHCPSchemaAttribute,
+		leaseFileAttribute:                leaseFileSchemaAttribute,
+		leaseTriggerAttribute:             leaseTriggerSchemaAttribute,
+		legacyAttribute:                   legacySchemaAttribute,
+		logLevelAttribute:                 logLevelSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		mainDHCPAttribute:                 mainDHCPSchemaAttribute,
 	}
 )
 
@@ -107,6 +108,7 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
+	TargetDevice types.String `tfsdk:"target_device"`
 	Id           types.String `tfsdk:"id"`
 	LeaseFile    types.String `tfsdk:"leasefile"`
 	LeaseTrigger types.String `tfsdk:"leasetrigger"`
@@ -115,6 +117,7 @@ type model struct {
 	MainDHCP     types.Bool   `tfsdk:"maindhcp"`
 }
 
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
 func modelGetId(m model) types.String           { return m.Id }
 func modelGetLeaseFile(m model) types.String    { return m.LeaseFile }
 func modelGetLeaseTrigger(m model) types.String { return m.LeaseTrigger }
@@ -122,6 +125,7 @@ func modelGetLegacy(m model) types.Bool         { return m.Legacy }
 func modelGetLogLevel(m model) types.Int64      { return m.LogLevel }
 func modelGetMainDHCP(m model) types.Bool       { return m.MainDHCP }
 
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)           { m.Id = value }
 func modelSetLeaseFile(m *model, value types.String)    { m.LeaseFile = value }
 func modelSetLeaseTrigger(m *model, value types.String) { m.LeaseTrigger = value }
@@ -0,0 +1,118 @@
+package odhcpd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("dhcp", "odhcpd", "testing", map[string]any{
+		"leasefile":    "/tmp/leasefile",
+		"leasetrigger": "/tmp/leasetrigger",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_dhcp_odhcpd" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_odhcpd.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_odhcpd.testing", "leasefile", "/tmp/leasefile"),
+			resource.TestCheckResourceAttr("data.openwrt_dhcp_odhcpd.testing", "leasetrigger", "/tmp/leasetrigger"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_odhcpd" "testing" {
+	id = "testing"
+	leasefile = "/tmp/leasefile"
+	leasetrigger = "/tmp/leasetrigger"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "leasefile", "/tmp/leasefile"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "leasetrigger", "/tmp/leasetrigger"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_dhcp_odhcpd.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_dhcp_odhcpd" "testing" {
+	id = "testing"
+	leasefile = "/tmp/leasefile"
+	leasetrigger = "/tmp/leasetrigger"
+	legacy = true
+	loglevel = 6
+	maindhcp = true
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "leasefile", "/tmp/leasefile"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "leasetrigger", "/tmp/leasetrigger"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "legacy", "true"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "loglevel", "6"),
+			resource.TestCheckResourceAttr("openwrt_dhcp_odhcpd.testing", "maindhcp", "true"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("dhcp", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous":   lucirpc.Boolean(false),
+					".name":        lucirpc.String("testing"),
+					".type":        lucirpc.String("odhcpd"),
+					"leasefile":    lucirpc.String("/tmp/leasefile"),
+					"leasetrigger": lucirpc.String("/tmp/leasetrigger"),
+					"legacy":       lucirpc.Boolean(true),
+					"loglevel":     lucirpc.Integer(6),
+					"maindhcp":     lucirpc.Boolean(true),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/firewall/zone"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
 )
 
@@ -36,6 +37,7 @@ var (
 	destSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       destAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetDest, destAttribute, destUCIOption),
+		References:        []lucirpcglue.SectionReference{zone.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetDest, destAttribute, destUCIOption),
 	}
@@ -43,6 +45,7 @@ var (
 	srcSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       srcAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetSrc, srcAttribute, srcUCIOption),
+		References:        []lucirpcglue.SectionReference{zone.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetSrc, srcAttribute, srcUCIOption),
 	}
@@ -62,10 +65,11 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		srcAttribute:            srcSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		destAttribute:           destSchemaAttribute,
-		familyAttribute:         familySchemaAttribute,
+		srcAttribute:                      srcSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		destAttribute:                     destSchemaAttribute,
+		familyAttribute:                   familySchemaAttribute,
 	}
 )
 
@@ -79,6 +83,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -90,18 +103,21 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Id     types.String `tfsdk:"id"`
-	Src    types.String `tfsdk:"src"`
-	Dest   types.String `tfsdk:"dest"`
-	Family types.String `tfsdk:"family"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Src          types.String `tfsdk:"src"`
+	Dest         types.String `tfsdk:"dest"`
+	Family       types.String `tfsdk:"family"`
 }
 
-func modelGetSrc(m model) types.String    { return m.Src }
-func modelGetId(m model) types.String     { return m.Id }
-func modelGetDest(m model) types.String   { return m.Dest }
-func modelGetFamily(m model) types.String { return m.Family }
+func modelGetSrc(m model) types.String          { return m.Src }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetDest(m model) types.String         { return m.Dest }
+func modelGetFamily(m model) types.String       { return m.Family }
 
-func modelSetSrc(m *model, value types.String)    { m.Src = value }
-func modelSetDest(m *model, value types.String)   { m.Dest = value }
-func modelSetId(m *model, value types.String)     { m.Id = value }
-func modelSetFamily(m *model, value types.String) { m.Family = value }
+func modelSetSrc(m *model, value types.String)          { m.Src = value }
+func modelSetDest(m *model, value types.String)         { m.Dest = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetFamily(m *model, value types.String)       { m.Family = value }
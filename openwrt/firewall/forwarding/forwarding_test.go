@@ -0,0 +1,127 @@
+package forwarding_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "forwarding", "testing", map[string]any{
+		"src":  "wan",
+		"dest": "lan",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_firewall_forwarding" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_firewall_forwarding.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_forwarding.testing", "src", "wan"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_forwarding.testing", "dest", "lan"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "zone", "lan", map[string]any{
+		"name": "lan",
+	})
+	server.SetSection("firewall", "zone", "wan", map[string]any{
+		"name": "wan",
+	})
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_forwarding" "testing" {
+	dest = "lan"
+	id = "testing"
+	src = "wan"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "dest", "lan"),
+			resource.TestCheckNoResourceAttr("openwrt_firewall_forwarding.testing", "family"),
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "src", "wan"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_firewall_forwarding.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_forwarding" "testing" {
+	dest = "wan"
+	family = "ipv4"
+	id = "testing"
+	src = "lan"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "dest", "wan"),
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "family", "ipv4"),
+			resource.TestCheckResourceAttr("openwrt_firewall_forwarding.testing", "src", "lan"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("firewall", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("forwarding"),
+					"dest":       lucirpc.String("wan"),
+					"family":     lucirpc.String("ipv4"),
+					"src":        lucirpc.String("lan"),
+				})
+				return nil
+			},
+		),
+	}
+	deleteResource := resource.TestStep{
+		Config: providerBlock,
+		Check: func(*terraform.State) error {
+			_, ok := server.CommittedSection("firewall", "testing")
+			assert.Check(t, !ok)
+			return nil
+		},
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+		deleteResource,
+	)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/firewall/zone"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
 )
 
@@ -96,6 +97,7 @@ var (
 	destSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       destAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetDest, destAttribute, destUCIOption),
+		References:        []lucirpcglue.SectionReference{zone.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetDest, destAttribute, destUCIOption),
 	}
@@ -123,6 +125,7 @@ var (
 	srcSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       srcAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetSrc, srcAttribute, srcUCIOption),
+		References:        []lucirpcglue.SectionReference{zone.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetSrc, srcAttribute, srcUCIOption),
 	}
@@ -192,19 +195,23 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		srcAttribute:            srcSchemaAttribute,
-		srcPortAttribute:        srcPortSchemaAttribute,
-		srcDPortAttribute:       srcDPortSchemaAttribute,
-		srcIpAttribute:          srcIpSchemaAttribute,
-		srcDipAttribute:         srcDipSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		destAttribute:           destSchemaAttribute,
-		destPortAttribute:       destPortSchemaAttribute,
-		destIpAttribute:         destIpSchemaAttribute,
-		targetAttribute:         targetSchemaAttribute,
-		nameAttribute:           nameSchemaAttribute,
-		familyAttribute:         familySchemaAttribute,
-		protocolAttribute:       protocolSchemaAttribute,
+		srcAttribute:                      srcSchemaAttribute,
+		srcPortAttribute:                  srcPortSchemaAttribute,
+		srcDPortAttribute:                 srcDPortSchemaAttribute,
+		srcIpAttribute:                    srcIpSchemaAttribute,
+		srcDipAttribute:                   srcDipSchemaAttribute,
+		lucirpcglue.AfterAttribute:        lucirpcglue.AfterSchemaAttribute(modelGetAfter, modelSetAfter),
+		lucirpcglue.BeforeAttribute:       lucirpcglue.BeforeSchemaAttribute(modelGetBefore, modelSetBefore),
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		lucirpcglue.PositionAttribute:     lucirpcglue.PositionSchemaAttribute(modelGetPosition, modelSetPosition),
+		destAttribute:                     destSchemaAttribute,
+		destPortAttribute:                 destPortSchemaAttribute,
+		destIpAttribute:                   destIpSchemaAttribute,
+		targetAttribute:                   targetSchemaAttribute,
+		nameAttribute:                     nameSchemaAttribute,
+		familyAttribute:                   familySchemaAttribute,
+		protocolAttribute:                 protocolSchemaAttribute,
 	}
 )
 
@@ -218,6 +225,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -229,45 +245,57 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Id       types.String `tfsdk:"id"`
-	Src      types.String `tfsdk:"src"`
-	SrcPort  types.Int64  `tfsdk:"src_port"`
-	SrcDPort types.Int64  `tfsdk:"src_dport"`
-	SrcIp    types.List   `tfsdk:"src_ip"`
-	SrcDip   types.List   `tfsdk:"src_dip"`
-	Dest     types.String `tfsdk:"dest"`
-	DestPort types.Int64  `tfsdk:"dest_port"`
-	DestIp   types.List   `tfsdk:"dest_ip"`
-	Target   types.String `tfsdk:"target"`
-	Name     types.String `tfsdk:"name"`
-	Family   types.String `tfsdk:"family"`
-	Protocol types.List   `tfsdk:"proto"`
+	After        types.String `tfsdk:"after"`
+	Before       types.String `tfsdk:"before"`
+	Position     types.Int64  `tfsdk:"position"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Src          types.String `tfsdk:"src"`
+	SrcPort      types.Int64  `tfsdk:"src_port"`
+	SrcDPort     types.Int64  `tfsdk:"src_dport"`
+	SrcIp        types.List   `tfsdk:"src_ip"`
+	SrcDip       types.List   `tfsdk:"src_dip"`
+	Dest         types.String `tfsdk:"dest"`
+	DestPort     types.Int64  `tfsdk:"dest_port"`
+	DestIp       types.List   `tfsdk:"dest_ip"`
+	Target       types.String `tfsdk:"target"`
+	Name         types.String `tfsdk:"name"`
+	Family       types.String `tfsdk:"family"`
+	Protocol     types.List   `tfsdk:"proto"`
 }
 
-func modelGetTarget(m model) types.String  { return m.Target }
-func modelGetName(m model) types.String    { return m.Name }
-func modelGetSrc(m model) types.String     { return m.Src }
-func modelGetSrcPort(m model) types.Int64  { return m.SrcPort }
-func modelGetSrcDPort(m model) types.Int64 { return m.SrcDPort }
-func modelGetSrcIp(m model) types.List     { return m.SrcIp }
-func modelGetSrcDip(m model) types.List    { return m.SrcDip }
-func modelGetId(m model) types.String      { return m.Id }
-func modelGetDest(m model) types.String    { return m.Dest }
-func modelGetFamily(m model) types.String  { return m.Family }
-func modelGetDestPort(m model) types.Int64 { return m.DestPort }
-func modelGetDestIp(m model) types.List    { return m.DestIp }
-func modelGetProtocol(m model) types.List  { return m.Protocol }
-
-func modelSetSrc(m *model, value types.String)     { m.Src = value }
-func modelSetSrcPort(m *model, value types.Int64)  { m.SrcPort = value }
-func modelSetSrcDPort(m *model, value types.Int64) { m.SrcDPort = value }
-func modelSetSrcIp(m *model, value types.List)     { m.SrcIp = value }
-func modelSetSrcDip(m *model, value types.List)    { m.SrcDip = value }
-func modelSetDest(m *model, value types.String)    { m.Dest = value }
-func modelSetId(m *model, value types.String)      { m.Id = value }
-func modelSetTarget(m *model, value types.String)  { m.Target = value }
-func modelSetName(m *model, value types.String)    { m.Name = value }
-func modelSetFamily(m *model, value types.String)  { m.Family = value }
-func modelSetDestPort(m *model, value types.Int64) { m.DestPort = value }
-func modelSetDestIp(m *model, value types.List)    { m.DestIp = value }
-func modelSetProtocol(m *model, value types.List)  { m.Protocol = value }
+func modelGetTarget(m model) types.String       { return m.Target }
+func modelGetName(m model) types.String         { return m.Name }
+func modelGetSrc(m model) types.String          { return m.Src }
+func modelGetSrcPort(m model) types.Int64       { return m.SrcPort }
+func modelGetSrcDPort(m model) types.Int64      { return m.SrcDPort }
+func modelGetSrcIp(m model) types.List          { return m.SrcIp }
+func modelGetSrcDip(m model) types.List         { return m.SrcDip }
+func modelGetAfter(m model) types.String        { return m.After }
+func modelGetBefore(m model) types.String       { return m.Before }
+func modelGetPosition(m model) types.Int64      { return m.Position }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetDest(m model) types.String         { return m.Dest }
+func modelGetFamily(m model) types.String       { return m.Family }
+func modelGetDestPort(m model) types.Int64      { return m.DestPort }
+func modelGetDestIp(m model) types.List         { return m.DestIp }
+func modelGetProtocol(m model) types.List       { return m.Protocol }
+
+func modelSetSrc(m *model, value types.String)          { m.Src = value }
+func modelSetSrcPort(m *model, value types.Int64)       { m.SrcPort = value }
+func modelSetSrcDPort(m *model, value types.Int64)      { m.SrcDPort = value }
+func modelSetSrcIp(m *model, value types.List)          { m.SrcIp = value }
+func modelSetSrcDip(m *model, value types.List)         { m.SrcDip = value }
+func modelSetDest(m *model, value types.String)         { m.Dest = value }
+func modelSetAfter(m *model, value types.String)        { m.After = value }
+func modelSetBefore(m *model, value types.String)       { m.Before = value }
+func modelSetPosition(m *model, value types.Int64)      { m.Position = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetTarget(m *model, value types.String)       { m.Target = value }
+func modelSetName(m *model, value types.String)         { m.Name = value }
+func modelSetFamily(m *model, value types.String)       { m.Family = value }
+func modelSetDestPort(m *model, value types.Int64)      { m.DestPort = value }
+func modelSetDestIp(m *model, value types.List)         { m.DestIp = value }
+func modelSetProtocol(m *model, value types.List)       { m.Protocol = value }
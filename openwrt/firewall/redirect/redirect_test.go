@@ -0,0 +1,156 @@
+package redirect_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "redirect", "testing", map[string]any{
+		"dest":      "lan",
+		"dest_port": 22,
+		"name":      "testing",
+		"src":       "wan",
+		"src_dport": 22,
+		"target":    "DNAT",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_firewall_redirect" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "dest", "lan"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "dest_port", "22"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "name", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "src", "wan"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "src_dport", "22"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_redirect.testing", "target", "DNAT"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "zone", "lan", map[string]any{
+		"name": "lan",
+	})
+	server.SetSection("firewall", "zone", "wan", map[string]any{
+		"name": "wan",
+	})
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_redirect" "testing" {
+	dest = "lan"
+	dest_port = 8080
+	family = "ipv4"
+	id = "testing"
+	name = "example-redirect"
+	proto = [
+		"udp",
+		"tcp",
+	]
+	src = "wan"
+	src_dport = 8080
+	target = "DNAT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "dest", "lan"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "dest_port", "8080"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "family", "ipv4"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "name", "example-redirect"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "proto.#", "2"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "proto.0", "udp"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "proto.1", "tcp"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "src", "wan"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "src_dport", "8080"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "target", "DNAT"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_firewall_redirect.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_redirect" "testing" {
+	dest = "lan"
+	dest_port = 22
+	family = "any"
+	id = "testing"
+	name = "example-redirect"
+	proto = [
+		"tcp",
+	]
+	src = "wan"
+	src_dport = 2222
+	target = "DNAT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "dest_port", "22"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "family", "any"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "proto.#", "1"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "proto.0", "tcp"),
+			resource.TestCheckResourceAttr("openwrt_firewall_redirect.testing", "src_dport", "2222"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("firewall", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("redirect"),
+					"dest":       lucirpc.String("lan"),
+					"dest_port":  lucirpc.Integer(22),
+					"family":     lucirpc.String("any"),
+					"name":       lucirpc.String("example-redirect"),
+					"proto":      lucirpc.ListString([]string{"tcp"}),
+					"src":        lucirpc.String("wan"),
+					"src_dport":  lucirpc.Integer(2222),
+					"target":     lucirpc.String("DNAT"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
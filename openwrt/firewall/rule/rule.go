@@ -63,6 +63,16 @@ const (
 )
 
 var (
+	// A rule can also apply to traffic from (or to) any zone.
+	zoneReferences = []lucirpcglue.SectionReference{
+		{
+			Config:        zone.Reference.Config,
+			Option:        zone.Reference.Option,
+			SpecialValues: []string{"*"},
+			Type:          zone.Reference.Type,
+		},
+	}
+
 	nameSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       nameAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetName, nameAttribute, nameUCIOption),
@@ -81,6 +91,7 @@ var (
 	destSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       destAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetDest, destAttribute, destUCIOption),
+		References:        zoneReferences,
 		ResourceExistence: lucirpcglue.NoValidation,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetDest, destAttribute, destUCIOption),
 	}
@@ -108,6 +119,7 @@ var (
 	srcSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       srcAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetSrc, srcAttribute, srcUCIOption),
+		References:        zoneReferences,
 		ResourceExistence: lucirpcglue.NoValidation,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetSrc, srcAttribute, srcUCIOption),
 	}
@@ -156,17 +168,21 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		srcAttribute:            srcSchemaAttribute,
-		srcPortAttribute:        srcPortSchemaAttribute,
-		srcIpAttribute:          srcIpSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		destAttribute:           destSchemaAttribute,
-		destPortAttribute:       destPortSchemaAttribute,
-		destIpAttribute:         destIpSchemaAttribute,
-		targetAttribute:         targetSchemaAttribute,
-		nameAttribute:           nameSchemaAttribute,
-		familyAttribute:         familySchemaAttribute,
-		protocolAttribute:       protocolSchemaAttribute,
+		srcAttribute:                      srcSchemaAttribute,
+		srcPortAttribute:                  srcPortSchemaAttribute,
+		srcIpAttribute:                    srcIpSchemaAttribute,
+		lucirpcglue.AfterAttribute:        lucirpcglue.AfterSchemaAttribute(modelGetAfter, modelSetAfter),
+		lucirpcglue.BeforeAttribute:       lucirpcglue.BeforeSchemaAttribute(modelGetBefore, modelSetBefore),
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		lucirpcglue.PositionAttribute:     lucirpcglue.PositionSchemaAttribute(modelGetPosition, modelSetPosition),
+		destAttribute:                     destSchemaAttribute,
+		destPortAttribute:                 destPortSchemaAttribute,
+		destIpAttribute:                   destIpSchemaAttribute,
+		targetAttribute:                   targetSchemaAttribute,
+		nameAttribute:                     nameSchemaAttribute,
+		familyAttribute:                   familySchemaAttribute,
+		protocolAttribute:                 protocolSchemaAttribute,
 	}
 )
 
@@ -180,6 +196,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -191,39 +216,51 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Id       types.String `tfsdk:"id"`
-	Src      types.String `tfsdk:"src"`
-	SrcPort  types.Int64  `tfsdk:"src_port"`
-	SrcIp    types.List   `tfsdk:"src_ip"`
-	Dest     types.String `tfsdk:"dest"`
-	DestPort types.Int64  `tfsdk:"dest_port"`
-	DestIp   types.List   `tfsdk:"dest_ip"`
-	Target   types.String `tfsdk:"target"`
-	Name     types.String `tfsdk:"name"`
-	Family   types.String `tfsdk:"family"`
-	Protocol types.List   `tfsdk:"proto"`
+	After        types.String `tfsdk:"after"`
+	Before       types.String `tfsdk:"before"`
+	Position     types.Int64  `tfsdk:"position"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Src          types.String `tfsdk:"src"`
+	SrcPort      types.Int64  `tfsdk:"src_port"`
+	SrcIp        types.List   `tfsdk:"src_ip"`
+	Dest         types.String `tfsdk:"dest"`
+	DestPort     types.Int64  `tfsdk:"dest_port"`
+	DestIp       types.List   `tfsdk:"dest_ip"`
+	Target       types.String `tfsdk:"target"`
+	Name         types.String `tfsdk:"name"`
+	Family       types.String `tfsdk:"family"`
+	Protocol     types.List   `tfsdk:"proto"`
 }
 
-func modelGetTarget(m model) types.String  { return m.Target }
-func modelGetName(m model) types.String    { return m.Name }
-func modelGetSrc(m model) types.String     { return m.Src }
-func modelGetSrcPort(m model) types.Int64  { return m.SrcPort }
-func modelGetSrcIp(m model) types.List     { return m.SrcIp }
-func modelGetId(m model) types.String      { return m.Id }
-func modelGetDest(m model) types.String    { return m.Dest }
-func modelGetFamily(m model) types.String  { return m.Family }
-func modelGetDestPort(m model) types.Int64 { return m.DestPort }
-func modelGetDestIp(m model) types.List    { return m.DestIp }
-func modelGetProtocol(m model) types.List  { return m.Protocol }
-
-func modelSetSrc(m *model, value types.String)     { m.Src = value }
-func modelSetSrcPort(m *model, value types.Int64)  { m.SrcPort = value }
-func modelSetSrcIp(m *model, value types.List)     { m.SrcIp = value }
-func modelSetDest(m *model, value types.String)    { m.Dest = value }
-func modelSetId(m *model, value types.String)      { m.Id = value }
-func modelSetTarget(m *model, value types.String)  { m.Target = value }
-func modelSetName(m *model, value types.String)    { m.Name = value }
-func modelSetFamily(m *model, value types.String)  { m.Family = value }
-func modelSetDestPort(m *model, value types.Int64) { m.DestPort = value }
-func modelSetDestIp(m *model, value types.List)    { m.DestIp = value }
-func modelSetProtocol(m *model, value types.List)  { m.Protocol = value }
+func modelGetTarget(m model) types.String       { return m.Target }
+func modelGetName(m model) types.String         { return m.Name }
+func modelGetSrc(m model) types.String          { return m.Src }
+func modelGetSrcPort(m model) types.Int64       { return m.SrcPort }
+func modelGetSrcIp(m model) types.List          { return m.SrcIp }
+func modelGetAfter(m model) types.String        { return m.After }
+func modelGetBefore(m model) types.String       { return m.Before }
+func modelGetPosition(m model) types.Int64      { return m.Position }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetDest(m model) types.String         { return m.Dest }
+func modelGetFamily(m model) types.String       { return m.Family }
+func modelGetDestPort(m model) types.Int64      { return m.DestPort }
+func modelGetDestIp(m model) types.List         { return m.DestIp }
+func modelGetProtocol(m model) types.List       { return m.Protocol }
+
+func modelSetSrc(m *model, value types.String)          { m.Src = value }
+func modelSetSrcPort(m *model, value types.Int64)       { m.SrcPort = value }
+func modelSetSrcIp(m *model, value types.List)          { m.SrcIp = value }
+func modelSetDest(m *model, value types.String)         { m.Dest = value }
+func modelSetAfter(m *model, value types.String)        { m.After = value }
+func modelSetBefore(m *model, value types.String)       { m.Before = value }
+func modelSetPosition(m *model, value types.Int64)      { m.Position = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetTarget(m *model, value types.String)       { m.Target = value }
+func modelSetName(m *model, value types.String)         { m.Name = value }
+func modelSetFamily(m *model, value types.String)       { m.Family = value }
+func modelSetDestPort(m *model, value types.Int64)      { m.DestPort = value }
+func modelSetDestIp(m *model, value types.List)         { m.DestIp = value }
+func modelSetProtocol(m *model, value types.List)       { m.Protocol = value }
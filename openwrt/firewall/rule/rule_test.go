@@ -0,0 +1,179 @@
+package rule_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestResourceOrder(t *testing.T) {
+	ctx := context.Background()
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "defaults", "cfg01e63d", map[string]any{})
+	server.SetSection("firewall", "rule", "allow_ping", map[string]any{
+		"name":   "Allow-Ping",
+		"target": "ACCEPT",
+	})
+	server.SetSection("firewall", "rule", "reject_all", map[string]any{
+		"name":   "Reject-All",
+		"target": "REJECT",
+	})
+	providerBlock := server.ProviderBlock()
+	ruleNames := func(expected ...string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			client := server.LuCIRPCClient(ctx, t)
+			sections, err := client.ListSections(ctx, "firewall", "rule")
+			assert.NilError(t, err)
+			got := []string{}
+			for _, section := range sections {
+				name, err := section.GetString(".name")
+				assert.NilError(t, err)
+				got = append(got, name)
+			}
+
+			assert.DeepEqual(t, got, expected)
+			return nil
+		}
+	}
+
+	createBeforeAnotherRule := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_rule" "testing" {
+	before = "reject_all"
+	id = "testing"
+	name = "testing"
+	target = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckNoResourceAttr("openwrt_firewall_rule.testing", "after"),
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "before", "reject_all"),
+			resource.TestCheckNoResourceAttr("openwrt_firewall_rule.testing", "position"),
+			ruleNames("allow_ping", "testing", "reject_all"),
+		),
+	}
+	moveToPosition := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_rule" "testing" {
+	id = "testing"
+	name = "testing"
+	position = 0
+	target = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckNoResourceAttr("openwrt_firewall_rule.testing", "before"),
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "position", "0"),
+			ruleNames("testing", "allow_ping", "reject_all"),
+		),
+	}
+	moveAfterAnotherRule := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_rule" "testing" {
+	after = "reject_all"
+	id = "testing"
+	name = "testing"
+	target = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "after", "reject_all"),
+			resource.TestCheckNoResourceAttr("openwrt_firewall_rule.testing", "position"),
+			ruleNames("allow_ping", "reject_all", "testing"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createBeforeAnotherRule,
+		moveToPosition,
+		moveAfterAnotherRule,
+	)
+}
+
+func TestResourceZoneReferences(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "zone", "lan", map[string]any{
+		"name": "lan",
+	})
+	providerBlock := server.ProviderBlock()
+
+	createWithExistingZone := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_rule" "testing" {
+	dest = "*"
+	id = "testing"
+	name = "testing"
+	src = "lan"
+	target = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "dest", "*"),
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "src", "lan"),
+		),
+	}
+	// A zone created in the same plan does not exist yet,
+	// so the missing reference is only a warning.
+	updateWithZoneCreatedInPlan := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_zone" "guest" {
+	forward = "REJECT"
+	id = "guest"
+	input = "REJECT"
+	name = "guest"
+	network = ["guest"]
+	output = "ACCEPT"
+}
+
+resource "openwrt_firewall_rule" "testing" {
+	dest = "*"
+	id = "testing"
+	name = "testing"
+	src = "guest"
+	target = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_rule.testing", "src", "guest"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("firewall", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got["src"], lucirpc.String("guest"))
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createWithExistingZone,
+		updateWithZoneCreatedInPlan,
+	)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/network/networkinterface"
 )
 
 const (
@@ -51,6 +52,13 @@ const (
 )
 
 var (
+	// Reference is how other sections name a zone (e.g. a forwarding's `src`).
+	Reference = lucirpcglue.SectionReference{
+		Config: uciConfig,
+		Option: nameUCIOption,
+		Type:   uciType,
+	}
+
 	TypeValidators = []validator.String{
 		stringvalidator.OneOf(
 			typeAccept,
@@ -93,6 +101,7 @@ var (
 	networkSchemaAttribute = lucirpcglue.ListStringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       networkAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionListString(modelSetNetwork, networkAttribute, networkUCIOption),
+		References:        []lucirpcglue.SectionReference{networkinterface.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionListString(modelGetNetwork, networkAttribute, networkUCIOption),
 		Validators: []validator.List{
@@ -115,14 +124,18 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		forwardAttribute:        forwardSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		inputAttribute:          inputSchemaAttribute,
-		outputAttribute:         outputSchemaAttribute,
-		nameAttribute:           nameSchemaAttribute,
-		networkAttribute:        networkSchemaAttribute,
-		masqAttribute:           masqSchemaAttribute,
-		mtuFixAttribute:         mtuFixSchemaAttribute,
+		forwardAttribute:                  forwardSchemaAttribute,
+		lucirpcglue.AfterAttribute:        lucirpcglue.AfterSchemaAttribute(modelGetAfter, modelSetAfter),
+		lucirpcglue.BeforeAttribute:       lucirpcglue.BeforeSchemaAttribute(modelGetBefore, modelSetBefore),
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		lucirpcglue.PositionAttribute:     lucirpcglue.PositionSchemaAttribute(modelGetPosition, modelSetPosition),
+		inputAttribute:                    inputSchemaAttribute,
+		outputAttribute:                   outputSchemaAttribute,
+		nameAttribute:                     nameSchemaAttribute,
+		networkAttribute:                  networkSchemaAttribute,
+		masqAttribute:                     masqSchemaAttribute,
+		mtuFixAttribute:                   mtuFixSchemaAttribute,
 	}
 )
 
@@ -136,6 +149,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -147,30 +169,42 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Id         types.String `tfsdk:"id"`
-	Forward    types.String `tfsdk:"forward"`
-	Output     types.String `tfsdk:"output"`
-	Input      types.String `tfsdk:"input"`
-	Name       types.String `tfsdk:"name"`
-	Network    types.List   `tfsdk:"network"`
-	Masquerade types.Bool   `tfsdk:"masquerade"`
-	MssClamp   types.Bool   `tfsdk:"mssclamp"`
+	After        types.String `tfsdk:"after"`
+	Before       types.String `tfsdk:"before"`
+	Position     types.Int64  `tfsdk:"position"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Forward      types.String `tfsdk:"forward"`
+	Output       types.String `tfsdk:"output"`
+	Input        types.String `tfsdk:"input"`
+	Name         types.String `tfsdk:"name"`
+	Network      types.List   `tfsdk:"network"`
+	Masquerade   types.Bool   `tfsdk:"masquerade"`
+	MssClamp     types.Bool   `tfsdk:"mssclamp"`
 }
 
-func modelGetOutput(m model) types.String  { return m.Output }
-func modelGetId(m model) types.String      { return m.Id }
-func modelGetInput(m model) types.String   { return m.Input }
-func modelGetName(m model) types.String    { return m.Name }
-func modelGetForward(m model) types.String { return m.Forward }
-func modelGetNetwork(m model) types.List   { return m.Network }
-func modelGetMasq(m model) types.Bool      { return m.Masquerade }
-func modelGetMtuFix(m model) types.Bool    { return m.MssClamp }
-
-func modelSetOutput(m *model, value types.String)  { m.Output = value }
-func modelSetForward(m *model, value types.String) { m.Forward = value }
-func modelSetInput(m *model, value types.String)   { m.Input = value }
-func modelSetName(m *model, value types.String)    { m.Name = value }
-func modelSetId(m *model, value types.String)      { m.Id = value }
-func modelSetNetwork(m *model, value types.List)   { m.Network = value }
-func modelSetMasq(m *model, value types.Bool)      { m.Masquerade = value }
-func modelSetMtuFix(m *model, value types.Bool)    { m.MssClamp = value }
+func modelGetOutput(m model) types.String       { return m.Output }
+func modelGetAfter(m model) types.String        { return m.After }
+func modelGetBefore(m model) types.String       { return m.Before }
+func modelGetPosition(m model) types.Int64      { return m.Position }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetInput(m model) types.String        { return m.Input }
+func modelGetName(m model) types.String         { return m.Name }
+func modelGetForward(m model) types.String      { return m.Forward }
+func modelGetNetwork(m model) types.List        { return m.Network }
+func modelGetMasq(m model) types.Bool           { return m.Masquerade }
+func modelGetMtuFix(m model) types.Bool         { return m.MssClamp }
+
+func modelSetOutput(m *model, value types.String)       { m.Output = value }
+func modelSetForward(m *model, value types.String)      { m.Forward = value }
+func modelSetInput(m *model, value types.String)        { m.Input = value }
+func modelSetName(m *model, value types.String)         { m.Name = value }
+func modelSetAfter(m *model, value types.String)        { m.After = value }
+func modelSetBefore(m *model, value types.String)       { m.Before = value }
+func modelSetPosition(m *model, value types.Int64)      { m.Position = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetNetwork(m *model, value types.List)        { m.Network = value }
+func modelSetMasq(m *model, value types.Bool)           { m.Masquerade = value }
+func modelSetMtuFix(m *model, value types.Bool)         { m.MssClamp = value }
@@ -0,0 +1,136 @@
+package zone_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("firewall", "zone", "testing", map[string]any{
+		"name":    "testing",
+		"forward": "ACCEPT",
+		"input":   "ACCEPT",
+		"output":  "ACCEPT",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_firewall_zone" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_firewall_zone.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_zone.testing", "name", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_zone.testing", "forward", "ACCEPT"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_zone.testing", "input", "ACCEPT"),
+			resource.TestCheckResourceAttr("data.openwrt_firewall_zone.testing", "output", "ACCEPT"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_zone" "testing" {
+	forward = "ACCEPT"
+	id = "testing"
+	input = "ACCEPT"
+	name = "testing"
+	output = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "forward", "ACCEPT"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "input", "ACCEPT"),
+			resource.TestCheckNoResourceAttr("openwrt_firewall_zone.testing", "masquerade"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "name", "testing"),
+			resource.TestCheckNoResourceAttr("openwrt_firewall_zone.testing", "network"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "output", "ACCEPT"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_firewall_zone.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_firewall_zone" "testing" {
+	forward = "REJECT"
+	id = "testing"
+	input = "REJECT"
+	masquerade = true
+	mssclamp = true
+	name = "testing"
+	network = [
+		"vlan0",
+		"vlan1",
+	]
+	output = "ACCEPT"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "forward", "REJECT"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "input", "REJECT"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "masquerade", "true"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "mssclamp", "true"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "network.#", "2"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "network.0", "vlan0"),
+			resource.TestCheckResourceAttr("openwrt_firewall_zone.testing", "network.1", "vlan1"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("firewall", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("zone"),
+					"forward":    lucirpc.String("REJECT"),
+					"input":      lucirpc.String("REJECT"),
+					"masq":       lucirpc.Boolean(true),
+					"mtu_fix":    lucirpc.Boolean(true),
+					"name":       lucirpc.String("testing"),
+					"network":    lucirpc.ListString([]string{"vlan0", "vlan1"}),
+					"output":     lucirpc.String("ACCEPT"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -203,6 +204,7 @@ type Int64SchemaAttribute[Model any, Request any, Response any] struct {
 	DeprecationMessage  string
 	Description         string
 	MarkdownDescription string
+	PlanModifiers       []planmodifier.Int64
 	ReadResponse        func(context.Context, string, string, Response, Model) (context.Context, Model, diag.Diagnostics)
 	ResourceExistence   AttributeExistence
 	Sensitive           bool
@@ -244,6 +246,7 @@ func (a Int64SchemaAttribute[Model, Request, Response]) ToResource() resourcesch
 		Description:         a.Description,
 		MarkdownDescription: a.MarkdownDescription,
 		Optional:            a.ResourceExistence.ToOptional(),
+		PlanModifiers:       a.PlanModifiers,
 		Required:            a.ResourceExistence.ToRequired(),
 		Sensitive:           a.Sensitive,
 		Validators:          a.Validators,
@@ -269,6 +272,7 @@ type ListStringSchemaAttribute[Model any, Request any, Response any] struct {
 	Description         string
 	MarkdownDescription string
 	ReadResponse        func(context.Context, string, string, Response, Model) (context.Context, Model, diag.Diagnostics)
+	References          []SectionReference
 	ResourceExistence   AttributeExistence
 	Sensitive           bool
 	UpsertRequest       func(context.Context, string, Request, Model) (context.Context, Request, diag.Diagnostics)
@@ -330,6 +334,24 @@ func (a ListStringSchemaAttribute[Model, Request, Response]) Upsert(
 	return a.UpsertRequest(ctx, fullTypeName, request, model)
 }
 
+func (a ListStringSchemaAttribute[Model, Request, Response]) referencedValues(
+	ctx context.Context,
+	source attributeGetter,
+	attributePath path.Path,
+) ([]string, diag.Diagnostics) {
+	var value types.List
+	diagnostics := source.GetAttribute(ctx, attributePath, &value)
+	if diagnostics.HasError() || value.IsNull() || value.IsUnknown() {
+		return nil, diagnostics
+	}
+
+	return knownStrings(value.Elements()), diagnostics
+}
+
+func (a ListStringSchemaAttribute[Model, Request, Response]) sectionReferences() []SectionReference {
+	return a.References
+}
+
 func ReadResponseOptionBool[Model any](
 	set func(*Model, types.Bool),
 	attribute string,
@@ -466,6 +488,7 @@ type SetStringSchemaAttribute[Model any, Request any, Response any] struct {
 	Description         string
 	MarkdownDescription string
 	ReadResponse        func(context.Context, string, string, Response, Model) (context.Context, Model, diag.Diagnostics)
+	References          []SectionReference
 	ResourceExistence   AttributeExistence
 	Sensitive           bool
 	UpsertRequest       func(context.Context, string, Request, Model) (context.Context, Request, diag.Diagnostics)
@@ -527,12 +550,32 @@ func (a SetStringSchemaAttribute[Model, Request, Response]) Upsert(
 	return a.UpsertRequest(ctx, fullTypeName, request, model)
 }
 
+func (a SetStringSchemaAttribute[Model, Request, Response]) referencedValues(
+	ctx context.Context,
+	source attributeGetter,
+	attributePath path.Path,
+) ([]string, diag.Diagnostics) {
+	var value types.Set
+	diagnostics := source.GetAttribute(ctx, attributePath, &value)
+	if diagnostics.HasError() || value.IsNull() || value.IsUnknown() {
+		return nil, diagnostics
+	}
+
+	return knownStrings(value.Elements()), diagnostics
+}
+
+func (a SetStringSchemaAttribute[Model, Request, Response]) sectionReferences() []SectionReference {
+	return a.References
+}
+
 type StringSchemaAttribute[Model any, Request any, Response any] struct {
 	DataSourceExistence AttributeExistence
 	DeprecationMessage  string
 	Description         string
 	MarkdownDescription string
+	PlanModifiers       []planmodifier.String
 	ReadResponse        func(context.Context, string, string, Response, Model) (context.Context, Model, diag.Diagnostics)
+	References          []SectionReference
 	ResourceExistence   AttributeExistence
 	Sensitive           bool
 	UpsertRequest       func(context.Context, string, Request, Model) (context.Context, Request, diag.Diagnostics)
@@ -573,6 +616,7 @@ func (a StringSchemaAttribute[Model, Request, Response]) ToResource() resourcesc
 		Description:         a.Description,
 		MarkdownDescription: a.MarkdownDescription,
 		Optional:            a.ResourceExistence.ToOptional(),
+		PlanModifiers:       a.PlanModifiers,
 		Required:            a.ResourceExistence.ToRequired(),
 		Sensitive:           a.Sensitive,
 		Validators:          a.Validators,
@@ -592,6 +636,24 @@ func (a StringSchemaAttribute[Model, Request, Response]) Upsert(
 	return a.UpsertRequest(ctx, fullTypeName, request, model)
 }
 
+func (a StringSchemaAttribute[Model, Request, Response]) referencedValues(
+	ctx context.Context,
+	source attributeGetter,
+	attributePath path.Path,
+) ([]string, diag.Diagnostics) {
+	var value types.String
+	diagnostics := source.GetAttribute(ctx, attributePath, &value)
+	if diagnostics.HasError() || value.IsNull() || value.IsUnknown() {
+		return nil, diagnostics
+	}
+
+	return []string{value.ValueString()}, diagnostics
+}
+
+func (a StringSchemaAttribute[Model, Request, Response]) sectionReferences() []SectionReference {
+	return a.References
+}
+
 func UpsertRequestOptionBool[Model any](
 	get func(Model) types.Bool,
 	attribute string,
@@ -0,0 +1,94 @@
+package lucirpcglue
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+)
+
+// NewClientErrorDiagnostic constructs an error diagnostic for an error from a [lucirpc.Client].
+// The `summary` should describe what was being done.
+// It is extended with what went wrong,
+// and the detail explains what can be done about it.
+func NewClientErrorDiagnostic(
+	summary string,
+	err error,
+) diag.Diagnostic {
+	var (
+		accessDeniedError   lucirpc.AccessDeniedError
+		authenticationError lucirpc.AuthenticationError
+		fileNotFoundError   lucirpc.FileNotFoundError
+		notFoundError       lucirpc.NotFoundError
+		notSupportedError   lucirpc.NotSupportedError
+		pendingChangesError lucirpc.PendingChangesError
+		protocolError       lucirpc.ProtocolError
+		rpcError            lucirpc.RPCError
+		transportError      lucirpc.TransportError
+	)
+
+	// A login can fail because the device cannot be reached,
+	// so check for transport problems before authentication problems.
+	switch {
+	case errors.As(err, &transportError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: could not reach the device", summary),
+			fmt.Sprintf("%s\n\nCheck that the device is reachable at the configured hostname, port, and scheme, and that the configured transport is installed on the device.", err),
+		)
+
+	case errors.As(err, &authenticationError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: could not login", summary),
+			fmt.Sprintf("%s\n\nCheck that the configured username and password are correct.", err),
+		)
+
+	case errors.As(err, &accessDeniedError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: access denied", summary),
+			fmt.Sprintf("%s\n\nThe configured user is not allowed to do this. Check the rpcd ACLs for the user on the device.", err),
+		)
+
+	case errors.As(err, &notFoundError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: section not found", summary),
+			fmt.Sprintf("%s\n\nThe section does not exist on the device. It might have been removed outside of Terraform.", err),
+		)
+
+	case errors.As(err, &fileNotFoundError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: file not found", summary),
+			fmt.Sprintf("%s\n\nThe file does not exist on the device. It might have been removed outside of Terraform.", err),
+		)
+
+	case errors.As(err, &notSupportedError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: not supported", summary),
+			fmt.Sprintf("%s\n\nThe configured transport cannot do this.", err),
+		)
+
+	case errors.As(err, &pendingChangesError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: pending changes", summary),
+			fmt.Sprintf("%s\n\nSomeone staged these changes on the device without committing them, so changing the %q config would commit them too. Save or revert them in LuCI (or with `uci commit %s` or `uci revert %s`), or change the provider's pending_changes setting.", err, pendingChangesError.Config(), pendingChangesError.Config(), pendingChangesError.Config()),
+		)
+
+	case errors.As(err, &protocolError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: unexpected response", summary),
+			fmt.Sprintf("%s\n\nThe device responded in a way the provider does not understand. This might be a problem with the provider. Please report this to https://github.com/joneshf/terraform-provider-openwrt", err),
+		)
+
+	case errors.As(err, &rpcError):
+		return diag.NewErrorDiagnostic(
+			fmt.Sprintf("%s: the device returned an error", summary),
+			fmt.Sprintf("%s\n\nThe device could not do what was asked. Check that the configs, sections, and options exist on the device, and that the values are valid for it.", err),
+		)
+
+	default:
+		return diag.NewErrorDiagnostic(
+			summary,
+			err.Error(),
+		)
+	}
+}
@@ -35,7 +35,7 @@ func NewDataSource[Model any](
 }
 
 type dataSource[Model any] struct {
-	client            lucirpc.Client
+	devices           *Devices
 	fullTypeName      string
 	getId             func(Model) types.String
 	schemaAttributes  map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options]
@@ -63,7 +63,7 @@ func (d *dataSource[Model]) Configure(
 		return
 	}
 
-	d.client = providerData.Client
+	d.devices = providerData.Devices
 	d.fullTypeName = d.getFullTypeName(providerData.TypeName)
 }
 
@@ -92,11 +92,24 @@ func (d *dataSource[Model]) Read(
 		return
 	}
 
+	ctx, client, device, diagnostics := deviceClient(ctx, d.devices, d.schemaAttributes, req.Config)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	diagnostics = checkDeviceConfig(ctx, d.devices, device, d.uciConfig)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx, model, diagnostics = ReadModel(
 		ctx,
 		d.fullTypeName,
 		d.terraformType,
-		d.client,
+		client,
+		device,
 		d.schemaAttributes,
 		d.uciConfig,
 		d.getId(model).ValueString(),
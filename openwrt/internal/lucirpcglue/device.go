@@ -0,0 +1,328 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/internal/detachedcontext"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/logger"
+)
+
+const (
+	TargetDeviceAttribute            = "target_device"
+	targetDeviceAttributeDescription = "The name of the device to use, from the provider's `devices`. Defaults to the device the provider itself connects to."
+
+	// The device a section is on is not stored in UCI.
+	// It is added to the section as metadata so it can be read like any other option.
+	targetDeviceMetadata = ".target_device"
+
+	// deviceImportSeparator separates the device from the id when importing (e.g. `office/lan`).
+	// UCI names cannot contain it, so it is never part of the id.
+	deviceImportSeparator = "/"
+)
+
+// DeviceConnector creates the client for a device.
+type DeviceConnector func(context.Context) (*lucirpc.Client, diag.Diagnostics)
+
+// Devices are the devices the provider manages.
+// The client for each device is only created the first time it is used,
+// and that same client is used after that.
+// What the device can do is probed once, right after connecting.
+// If connecting or probing fails,
+// the next use of the device tries again.
+// The device with the empty name is the one the provider itself connects to.
+type Devices struct {
+	connections map[string]*deviceConnection
+}
+
+type deviceConnection struct {
+	capabilities lucirpc.Capabilities
+	client       *lucirpc.Client
+	connect      DeviceConnector
+	connected    bool
+	mutex        sync.Mutex
+}
+
+// attributeGetter is anything an attribute can be read from (e.g. a [tfsdk.Plan]).
+type attributeGetter interface {
+	GetAttribute(context.Context, path.Path, any) diag.Diagnostics
+}
+
+// TargetDeviceDataSourceAttribute is the [TargetDeviceAttribute] of a data source that is not made of [SchemaAttribute]s.
+func TargetDeviceDataSourceAttribute() datasourceschema.Attribute {
+	return datasourceschema.StringAttribute{
+		Description: targetDeviceAttributeDescription,
+		Optional:    true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// TargetDeviceResourceAttribute is the [TargetDeviceAttribute] of a resource that is not made of [SchemaAttribute]s.
+func TargetDeviceResourceAttribute() resourceschema.Attribute {
+	return resourceschema.StringAttribute{
+		Description: targetDeviceAttributeDescription,
+		Optional:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// TargetDeviceSchemaAttribute is the [TargetDeviceAttribute] of a section.
+func TargetDeviceSchemaAttribute[Model any](
+	get func(Model) types.String,
+	set func(*Model, types.String),
+) SchemaAttribute[Model, lucirpc.Options, lucirpc.Options] {
+	return StringSchemaAttribute[Model, lucirpc.Options, lucirpc.Options]{
+		DataSourceExistence: NoValidation,
+		Description:         targetDeviceAttributeDescription,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+		ReadResponse:      ReadResponseOptionString(set, TargetDeviceAttribute, targetDeviceMetadata),
+		ResourceExistence: NoValidation,
+		UpsertRequest: func(
+			ctx context.Context,
+			fullTypeName string,
+			options lucirpc.Options,
+			model Model,
+		) (context.Context, lucirpc.Options, diag.Diagnostics) {
+			ctx = logger.SetFieldString(ctx, fullTypeName, ResourceTerraformType, TargetDeviceAttribute, get(model))
+			return ctx, options, diag.Diagnostics{}
+		},
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// NewDevices constructs [Devices] from how to connect to each of them.
+func NewDevices(
+	connectors map[string]DeviceConnector,
+) *Devices {
+	connections := map[string]*deviceConnection{}
+	for name, connect := range connectors {
+		connections[name] = &deviceConnection{
+			connect: connect,
+		}
+	}
+
+	return &Devices{
+		connections: connections,
+	}
+}
+
+// Capabilities returns what the `device` can do,
+// connecting to it if this is the first time it is used.
+func (d *Devices) Capabilities(
+	ctx context.Context,
+	device string,
+) (lucirpc.Capabilities, diag.Diagnostics) {
+	connection, diagnostics := d.connection(ctx, device)
+	if diagnostics.HasError() {
+		return lucirpc.Capabilities{}, diagnostics
+	}
+
+	return connection.capabilities, diagnostics
+}
+
+// Client returns the client for the `device`,
+// creating it if this is the first time it is used.
+// If the client could not be created,
+// the problem is reported and the next use tries again.
+func (d *Devices) Client(
+	ctx context.Context,
+	device string,
+) (lucirpc.Client, diag.Diagnostics) {
+	connection, diagnostics := d.connection(ctx, device)
+	if diagnostics.HasError() {
+		return lucirpc.Client{}, diagnostics
+	}
+
+	return *connection.client, diagnostics
+}
+
+// ParseDeviceImportId splits an import id of the form `device/id` into the device and the id.
+// An id without a device is for the device the provider itself connects to.
+func ParseDeviceImportId(
+	importId string,
+) (string, string) {
+	device, id, ok := strings.Cut(importId, deviceImportSeparator)
+	if !ok {
+		return "", importId
+	}
+
+	return device, id
+}
+
+// ReadDeviceClient returns the client for the device named by the [TargetDeviceAttribute] in the `source`,
+// along with the name of the device.
+func ReadDeviceClient(
+	ctx context.Context,
+	devices *Devices,
+	source attributeGetter,
+) (context.Context, lucirpc.Client, string, diag.Diagnostics) {
+	device, diagnostics := readDevice(ctx, source)
+	if diagnostics.HasError() {
+		return ctx, lucirpc.Client{}, "", diagnostics
+	}
+
+	if device != "" {
+		ctx = tflog.SetField(ctx, "target_device", device)
+	}
+
+	client, diagnostics := devices.Client(ctx, device)
+	return ctx, client, device, diagnostics
+}
+
+// addDeviceMetadata adds the `device` to the `section`.
+func addDeviceMetadata(
+	section lucirpc.Options,
+	device string,
+) lucirpc.Options {
+	if device != "" {
+		section[targetDeviceMetadata] = lucirpc.String(device)
+	}
+
+	return section
+}
+
+// deviceClient returns the client for the device of a section, along with the name of the device.
+// Sections without a [TargetDeviceAttribute] use the device the provider itself connects to.
+func deviceClient[Model any](
+	ctx context.Context,
+	devices *Devices,
+	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+	source attributeGetter,
+) (context.Context, lucirpc.Client, string, diag.Diagnostics) {
+	if _, ok := attributes[TargetDeviceAttribute]; !ok {
+		client, diagnostics := devices.Client(ctx, "")
+		return ctx, client, "", diagnostics
+	}
+
+	return ReadDeviceClient(ctx, devices, source)
+}
+
+// checkDeviceConfig reports an error if the `device` does not have the UCI `config`.
+// Devices only have the configs of the packages installed on them
+// (e.g. a device without Wi-Fi has no `wireless` config).
+func checkDeviceConfig(
+	ctx context.Context,
+	devices *Devices,
+	device string,
+	config string,
+) diag.Diagnostics {
+	capabilities, diagnostics := devices.Capabilities(ctx, device)
+	if diagnostics.HasError() {
+		return diagnostics
+	}
+
+	if capabilities.HasConfig(config) {
+		return diagnostics
+	}
+
+	deviceName := "The device"
+	if device != "" {
+		deviceName = fmt.Sprintf("The device %q", device)
+	}
+
+	diagnostics.AddError(
+		"Missing UCI config",
+		fmt.Sprintf("%s does not have the %q UCI config. Check that the package providing it is installed on the device. The configs on the device are: %s", deviceName, config, strings.Join(capabilities.Configs, ", ")),
+	)
+	return diagnostics
+}
+
+// connection connects to the `device` and probes what it can do,
+// unless that already worked.
+//
+// Every resource on the device shares the connection,
+// so it is made with a context that is not cancelled along with the request that happened to use the device first.
+func (d *Devices) connection(
+	ctx context.Context,
+	device string,
+) (*deviceConnection, diag.Diagnostics) {
+	connection, ok := d.connections[device]
+	if !ok {
+		diagnostics := diag.Diagnostics{}
+		diagnostics.AddAttributeError(
+			path.Root(TargetDeviceAttribute),
+			"Unknown device",
+			fmt.Sprintf("The provider does not have a device named %q. The devices are: %s", device, strings.Join(d.names(), ", ")),
+		)
+		return nil, diagnostics
+	}
+
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+	if connection.connected {
+		return connection, diag.Diagnostics{}
+	}
+
+	ctx = detachedcontext.New(ctx)
+	tflog.Debug(ctx, fmt.Sprintf("Connecting to device %q", device))
+	client, diagnostics := connection.connect(ctx)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	capabilities, err := client.Capabilities(ctx)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			"problem probing OpenWrt device",
+			err,
+		))
+		return nil, diagnostics
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Probed device %q", device), map[string]any{
+		"configs":     capabilities.Configs,
+		"release":     capabilities.Release,
+		"rpc_modules": capabilities.RPCModules,
+	})
+	connection.capabilities = capabilities
+	connection.client = client
+	connection.connected = true
+	return connection, diagnostics
+}
+
+// names are the names of every device other than the one the provider itself connects to.
+func (d *Devices) names() []string {
+	names := []string{}
+	for name := range d.connections {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func readDevice(
+	ctx context.Context,
+	source attributeGetter,
+) (string, diag.Diagnostics) {
+	var device types.String
+	diagnostics := source.GetAttribute(ctx, path.Root(TargetDeviceAttribute), &device)
+	return device.ValueString(), diagnostics
+}
@@ -0,0 +1,392 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+)
+
+const (
+	listFilterAttribute            = "filter"
+	listFilterAttributeDescription = "Only include sections where each attribute equals the given value. List and set attributes match if any of their elements equals the given value."
+
+	listIdAttributeDescription = "The UCI config and section type that were listed (e.g. `dhcp.host`)."
+
+	listSectionsAttribute            = "sections"
+	listSectionsAttributeDescription = "The matching sections, in the order they appear in the config."
+)
+
+var (
+	_ datasource.DataSource              = &listDataSource[any]{}
+	_ datasource.DataSourceWithConfigure = &listDataSource[any]{}
+)
+
+// NewListDataSource constructs a data source that lists every section of the `uciType`.
+// It is named after the plural of the singular data source (e.g. `openwrt_dhcp_hosts`),
+// and reuses the same `schemaAttributes` for each of the sections.
+func NewListDataSource[Model any](
+	schemaAttributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+	schemaDescription string,
+	uciConfig string,
+	uciType string,
+) datasource.DataSource {
+	return &listDataSource[Model]{
+		schemaAttributes:  schemaAttributes,
+		schemaDescription: schemaDescription,
+		terraformType:     DataSourceTerraformType,
+		uciConfig:         uciConfig,
+		uciType:           uciType,
+	}
+}
+
+type listDataSource[Model any] struct {
+	devices           *Devices
+	fullTypeName      string
+	schemaAttributes  map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options]
+	schemaDescription string
+	terraformType     string
+	uciConfig         string
+	uciType           string
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *listDataSource[Model]) Configure(
+	ctx context.Context,
+	req datasource.ConfigureRequest,
+	res *datasource.ConfigureResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Configuring %s.%s list data source", d.uciConfig, d.uciType))
+	if req.ProviderData == nil {
+		tflog.Debug(ctx, "No provider data")
+		return
+	}
+
+	providerData, diagnostics := ParseProviderData(ConfigureRequest(req))
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	d.devices = providerData.Devices
+	d.fullTypeName = d.getFullTypeName(providerData.TypeName)
+}
+
+// Metadata sets the data source name.
+func (d *listDataSource[Model]) Metadata(
+	ctx context.Context,
+	req datasource.MetadataRequest,
+	res *datasource.MetadataResponse,
+) {
+	res.TypeName = d.getFullTypeName(req.ProviderTypeName)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *listDataSource[Model]) Read(
+	ctx context.Context,
+	req datasource.ReadRequest,
+	res *datasource.ReadResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Reading %s data source", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from config")
+	var model listDataSourceModel
+	diagnostics := req.Config.Get(ctx, &model)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	filter := map[string]string{}
+	if !model.Filter.IsNull() {
+		diagnostics = model.Filter.ElementsAs(ctx, &filter, false)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	for attribute := range filter {
+		if _, ok := d.schemaAttributes[attribute]; !ok {
+			res.Diagnostics.AddAttributeError(
+				path.Root(listFilterAttribute).AtMapKey(attribute),
+				"Unknown attribute",
+				fmt.Sprintf("Can only filter on the attributes of %s sections: %s", d.uciType, strings.Join(d.attributeNames(), ", ")),
+			)
+		}
+	}
+
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, device, diagnostics := ReadDeviceClient(ctx, d.devices, req.Config)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	diagnostics = checkDeviceConfig(ctx, d.devices, device, d.uciConfig)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	sections, diagnostics := ListSections(
+		ctx,
+		client,
+		d.uciConfig,
+		d.uciType,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if hasSectionOrder(d.schemaAttributes) {
+		var err error
+		sections, err = addSectionOrder(sections)
+		if err != nil {
+			res.Diagnostics.Append(NewClientErrorDiagnostic(
+				fmt.Sprintf("problem reading the order of %s.%s sections", d.uciConfig, d.uciType),
+				lucirpc.NewProtocolError(err),
+			))
+			return
+		}
+	}
+
+	attributeTypes := d.attributeTypes()
+	items := []Model{}
+	for _, section := range sections {
+		var item Model
+		ctx, item, diagnostics = ReadModelFromSection(
+			ctx,
+			d.fullTypeName,
+			d.terraformType,
+			d.schemaAttributes,
+			addDeviceMetadata(section, device),
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+
+		object, diagnostics := types.ObjectValueFrom(ctx, attributeTypes, item)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+
+		if matchesFilter(object, filter) {
+			items = append(items, item)
+		}
+	}
+
+	model.Id = types.StringValue(fmt.Sprintf("%s.%s", d.uciConfig, d.uciType))
+	model.Sections, diagnostics = types.ListValueFrom(
+		ctx,
+		types.ObjectType{
+			AttrTypes: attributeTypes,
+		},
+		items,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Setting the %s data source state", d.fullTypeName))
+	diagnostics = res.State.Set(ctx, model)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Schema defines the schema for the data source.
+func (d *listDataSource[Model]) Schema(
+	ctx context.Context,
+	req datasource.SchemaRequest,
+	res *datasource.SchemaResponse,
+) {
+	attributes := map[string]schema.Attribute{
+		TargetDeviceAttribute: TargetDeviceDataSourceAttribute(),
+		listFilterAttribute: schema.MapAttribute{
+			Description: listFilterAttributeDescription,
+			ElementType: types.StringType,
+			Optional:    true,
+		},
+		IdAttribute: schema.StringAttribute{
+			Computed:    true,
+			Description: listIdAttributeDescription,
+		},
+		listSectionsAttribute: schema.ListNestedAttribute{
+			Computed:    true,
+			Description: listSectionsAttributeDescription,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: d.sectionAttributes(),
+			},
+		},
+	}
+	res.Schema = schema.Schema{
+		Attributes:  attributes,
+		Description: fmt.Sprintf("Lists every section of the `%s` type. %s", d.uciType, d.schemaDescription),
+	}
+}
+
+func (d listDataSource[Model]) attributeNames() []string {
+	names := []string{}
+	for name := range d.schemaAttributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (d listDataSource[Model]) attributeTypes() map[string]attr.Type {
+	attributeTypes := map[string]attr.Type{}
+	for name, attribute := range d.sectionAttributes() {
+		attributeTypes[name] = attribute.GetType()
+	}
+
+	return attributeTypes
+}
+
+func (d listDataSource[Model]) getFullTypeName(
+	providerTypeName string,
+) string {
+	uciConfig := strings.ReplaceAll(d.uciConfig, "-", "_")
+	uciType := strings.ReplaceAll(d.uciType, "-", "_")
+	return fmt.Sprintf("%s_%s_%s", providerTypeName, uciConfig, pluralize(uciType))
+}
+
+// sectionAttributes are the singular data source attributes,
+// but every one of them is read from the device.
+func (d listDataSource[Model]) sectionAttributes() map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{}
+	for name, attribute := range d.schemaAttributes {
+		attributes[name] = toComputedDataSourceAttribute(attribute.ToDataSource())
+	}
+
+	return attributes
+}
+
+type listDataSourceModel struct {
+	TargetDevice types.String `tfsdk:"target_device"`
+	Filter       types.Map    `tfsdk:"filter"`
+	Id           types.String `tfsdk:"id"`
+	Sections     types.List   `tfsdk:"sections"`
+}
+
+// matchesFilter checks that each attribute in the `filter` equals the attribute in the `object`.
+func matchesFilter(
+	object types.Object,
+	filter map[string]string,
+) bool {
+	attributes := object.Attributes()
+	for name, expected := range filter {
+		if !matchesValue(attributes[name], expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesValue(
+	value attr.Value,
+	expected string,
+) bool {
+	if value == nil || value.IsNull() || value.IsUnknown() {
+		return false
+	}
+
+	switch v := value.(type) {
+	case types.Bool:
+		return strconv.FormatBool(v.ValueBool()) == expected
+
+	case types.Int64:
+		return strconv.FormatInt(v.ValueInt64(), 10) == expected
+
+	case types.List:
+		return matchesAnyElement(v.Elements(), expected)
+
+	case types.Set:
+		return matchesAnyElement(v.Elements(), expected)
+
+	case types.String:
+		return v.ValueString() == expected
+
+	default:
+		return false
+	}
+}
+
+func matchesAnyElement(
+	elements []attr.Value,
+	expected string,
+) bool {
+	for _, element := range elements {
+		if matchesValue(element, expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pluralize makes the plural of a UCI type,
+// so `host` becomes `hosts` and `switch` becomes `switches`.
+func pluralize(
+	uciType string,
+) string {
+	for _, suffix := range []string{"ch", "sh", "s", "x"} {
+		if strings.HasSuffix(uciType, suffix) {
+			return uciType + "es"
+		}
+	}
+
+	return uciType + "s"
+}
+
+// toComputedDataSourceAttribute makes the `attribute` read-only.
+// Validators only apply to configuration,
+// so they are removed as well.
+func toComputedDataSourceAttribute(
+	attribute schema.Attribute,
+) schema.Attribute {
+	switch a := attribute.(type) {
+	case schema.BoolAttribute:
+		a.Computed, a.Optional, a.Required, a.Validators = true, false, false, nil
+		return a
+
+	case schema.Int64Attribute:
+		a.Computed, a.Optional, a.Required, a.Validators = true, false, false, nil
+		return a
+
+	case schema.ListAttribute:
+		a.Computed, a.Optional, a.Required, a.Validators = true, false, false, nil
+		return a
+
+	case schema.SetAttribute:
+		a.Computed, a.Optional, a.Required, a.Validators = true, false, false, nil
+		return a
+
+	case schema.StringAttribute:
+		a.Computed, a.Optional, a.Required, a.Validators = true, false, false, nil
+		return a
+
+	default:
+		return attribute
+	}
+}
@@ -3,12 +3,62 @@ package lucirpcglue
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 )
 
+// GenerateRemovedOptions finds the options that were managed through the `state`,
+// but whose attributes are now null in the `plan`.
+// Attributes that are unknown in the plan are left alone,
+// as their value is up to the device.
+func GenerateRemovedOptions[Model any](
+	ctx context.Context,
+	fullTypeName string,
+	plan tfsdk.Plan,
+	state Model,
+	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+) (context.Context, []string, diag.Diagnostics) {
+	tflog.Info(ctx, "Generating options to remove")
+	allDiagnostics := diag.Diagnostics{}
+	removed := []string{}
+
+	var planValues map[string]tftypes.Value
+	err := plan.Raw.As(&planValues)
+	if err != nil {
+		allDiagnostics.AddError(
+			"Could not read plan",
+			fmt.Sprintf("Unable to read the planned values: %s", err),
+		)
+		return ctx, removed, allDiagnostics
+	}
+
+	tflog.Debug(ctx, "Handling attributes")
+	for name, attribute := range attributes {
+		planValue, ok := planValues[name]
+		if !ok || !planValue.IsNull() {
+			continue
+		}
+
+		var (
+			diagnostics diag.Diagnostics
+			options     lucirpc.Options
+		)
+		ctx, options, diagnostics = attribute.Upsert(ctx, fullTypeName, lucirpc.Options{}, state)
+		allDiagnostics.Append(diagnostics...)
+		for option := range options {
+			removed = append(removed, option)
+		}
+	}
+
+	sort.Strings(removed)
+	return ctx, removed, allDiagnostics
+}
+
 func GenerateUpsertBody[Model any](
 	ctx context.Context,
 	fullTypeName string,
@@ -34,6 +84,7 @@ func ReadModel[Model any](
 	fullTypeName string,
 	terraformType string,
 	client lucirpc.Client,
+	device string,
 	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
 	uciConfig string,
 	uciSection string,
@@ -50,10 +101,44 @@ func ReadModel[Model any](
 		return ctx, model, allDiagnostics
 	}
 
+	section = addDeviceMetadata(section, device)
+	if hasSectionOrder(attributes) {
+		section, diagnostics = readSectionOrder(ctx, client, uciConfig, section)
+		allDiagnostics.Append(diagnostics...)
+		if allDiagnostics.HasError() {
+			return ctx, model, allDiagnostics
+		}
+	}
+
+	ctx, model, diagnostics = ReadModelFromSection(
+		ctx,
+		fullTypeName,
+		terraformType,
+		attributes,
+		section,
+	)
+	allDiagnostics.Append(diagnostics...)
+	return ctx, model, allDiagnostics
+}
+
+// ReadModelFromSection reads each of the `attributes` from an already retrieved `section`.
+func ReadModelFromSection[Model any](
+	ctx context.Context,
+	fullTypeName string,
+	terraformType string,
+	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+	section lucirpc.Options,
+) (context.Context, Model, diag.Diagnostics) {
+	var (
+		allDiagnostics diag.Diagnostics
+		diagnostics    diag.Diagnostics
+		model          Model
+	)
+
 	for _, attribute := range attributes {
 		ctx, model, diagnostics = attribute.Read(ctx, fullTypeName, terraformType, section, model)
 		allDiagnostics.Append(diagnostics...)
 	}
 
-	return ctx, model, diagnostics
+	return ctx, model, allDiagnostics
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -212,3 +213,30 @@ func GetOptionString(
 	ctx = logger.SetFieldString(ctx, fullTypeName, terraformType, option, result)
 	return ctx, result, diagnostics
 }
+
+// SplitOptions separates the options of the `section` into lists of strings and everything else.
+// Every option that is not a list is converted to a string,
+// as that is how UCI stores it.
+// Metadata (e.g. `.name`) is left out.
+func SplitOptions(
+	section lucirpc.Options,
+) (map[string]string, map[string][]string) {
+	stringOptions := map[string]string{}
+	listOptions := map[string][]string{}
+	for option := range section {
+		if strings.HasPrefix(option, ".") {
+			continue
+		}
+
+		if value, err := section.GetListString(option); err == nil {
+			listOptions[option] = value
+			continue
+		}
+
+		if value, err := section.GetString(option); err == nil {
+			stringOptions[option] = value
+		}
+	}
+
+	return stringOptions, listOptions
+}
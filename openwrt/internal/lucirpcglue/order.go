@@ -0,0 +1,456 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/logger"
+)
+
+const (
+	AfterAttribute            = "after"
+	afterAttributeDescription = "The section directly before this one, among the sections of the same type. When set, this section is moved directly after the given section. " + orderReadBackDescription
+
+	BeforeAttribute            = "before"
+	beforeAttributeDescription = "The section directly after this one, among the sections of the same type. When set, this section is moved directly before the given section. " + orderReadBackDescription
+
+	PositionAttribute            = "position"
+	positionAttributeDescription = "Where this section is among the sections of the same type, starting from 0. When set, this section is moved to that position. If there are fewer sections, this section is moved to the end. " + orderReadBackDescription
+
+	// The order changes whenever another section is added or moved,
+	// so a resource only keeps what was set in its state.
+	orderReadBackDescription = "Resources only read this back from the device when it is set, as it changes whenever other sections are added or moved."
+
+	// The order of a section is not stored in UCI.
+	// It is worked out from the other sections,
+	// and added to the section as metadata so it can be read like any other option.
+	orderAfterMetadata    = ".after"
+	orderBeforeMetadata   = ".before"
+	orderPositionMetadata = ".position"
+
+	typeUCISection = ".type"
+)
+
+// AfterSchemaAttribute is the [AfterAttribute] of an order-sensitive section.
+// It conflicts with the [BeforeAttribute] and the [PositionAttribute].
+func AfterSchemaAttribute[Model any](
+	get func(Model) types.String,
+	set func(*Model, types.String),
+) SchemaAttribute[Model, lucirpc.Options, lucirpc.Options] {
+	return StringSchemaAttribute[Model, lucirpc.Options, lucirpc.Options]{
+		DataSourceExistence: ReadOnly,
+		Description:         afterAttributeDescription,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+		ReadResponse:      readResponseOrderString(get, set, AfterAttribute, orderAfterMetadata),
+		ResourceExistence: NoValidation,
+		UpsertRequest: func(
+			ctx context.Context,
+			fullTypeName string,
+			options lucirpc.Options,
+			model Model,
+		) (context.Context, lucirpc.Options, diag.Diagnostics) {
+			ctx = logger.SetFieldString(ctx, fullTypeName, ResourceTerraformType, AfterAttribute, get(model))
+			return ctx, options, diag.Diagnostics{}
+		},
+		Validators: []validator.String{
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(BeforeAttribute),
+				path.MatchRoot(PositionAttribute),
+			),
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// BeforeSchemaAttribute is the [BeforeAttribute] of an order-sensitive section.
+// It conflicts with the [AfterAttribute] and the [PositionAttribute].
+func BeforeSchemaAttribute[Model any](
+	get func(Model) types.String,
+	set func(*Model, types.String),
+) SchemaAttribute[Model, lucirpc.Options, lucirpc.Options] {
+	return StringSchemaAttribute[Model, lucirpc.Options, lucirpc.Options]{
+		DataSourceExistence: ReadOnly,
+		Description:         beforeAttributeDescription,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+		ReadResponse:      readResponseOrderString(get, set, BeforeAttribute, orderBeforeMetadata),
+		ResourceExistence: NoValidation,
+		UpsertRequest: func(
+			ctx context.Context,
+			fullTypeName string,
+			options lucirpc.Options,
+			model Model,
+		) (context.Context, lucirpc.Options, diag.Diagnostics) {
+			ctx = logger.SetFieldString(ctx, fullTypeName, ResourceTerraformType, BeforeAttribute, get(model))
+			return ctx, options, diag.Diagnostics{}
+		},
+		Validators: []validator.String{
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(AfterAttribute),
+				path.MatchRoot(PositionAttribute),
+			),
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+}
+
+// PositionSchemaAttribute is the [PositionAttribute] of an order-sensitive section.
+// It conflicts with the [AfterAttribute] and the [BeforeAttribute].
+func PositionSchemaAttribute[Model any](
+	get func(Model) types.Int64,
+	set func(*Model, types.Int64),
+) SchemaAttribute[Model, lucirpc.Options, lucirpc.Options] {
+	return Int64SchemaAttribute[Model, lucirpc.Options, lucirpc.Options]{
+		DataSourceExistence: ReadOnly,
+		Description:         positionAttributeDescription,
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.UseStateForUnknown(),
+		},
+		ReadResponse:      readResponseOrderInt64(get, set, PositionAttribute, orderPositionMetadata),
+		ResourceExistence: NoValidation,
+		UpsertRequest: func(
+			ctx context.Context,
+			fullTypeName string,
+			options lucirpc.Options,
+			model Model,
+		) (context.Context, lucirpc.Options, diag.Diagnostics) {
+			ctx = logger.SetFieldInt64(ctx, fullTypeName, ResourceTerraformType, PositionAttribute, get(model))
+			return ctx, options, diag.Diagnostics{}
+		},
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+			int64validator.ConflictsWith(
+				path.MatchRoot(AfterAttribute),
+				path.MatchRoot(BeforeAttribute),
+			),
+		},
+	}
+}
+
+// addSectionOrder adds the order metadata to each of the `sections`.
+// The `sections` must all be of the same type,
+// and in the order they appear in the config.
+func addSectionOrder(
+	sections []lucirpc.Options,
+) ([]lucirpc.Options, error) {
+	names := []string{}
+	for _, section := range sections {
+		name, err := section.GetString(idUCISection)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	result := []lucirpc.Options{}
+	for index, section := range sections {
+		ordered := lucirpc.Options{}
+		for option, value := range section {
+			ordered[option] = value
+		}
+
+		ordered[orderPositionMetadata] = lucirpc.Integer(index)
+		if index > 0 {
+			ordered[orderAfterMetadata] = lucirpc.String(names[index-1])
+		}
+
+		if index < len(names)-1 {
+			ordered[orderBeforeMetadata] = lucirpc.String(names[index+1])
+		}
+
+		result = append(result, ordered)
+	}
+
+	return result, nil
+}
+
+// hasSectionOrder checks if the `attributes` include the order of the section.
+// Only order-sensitive sections have the [PositionAttribute].
+func hasSectionOrder[Model any](
+	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+) bool {
+	_, ok := attributes[PositionAttribute]
+	return ok
+}
+
+// sectionIndex finds the index among every section of the `config` where the `plan` says the `section` should be.
+// The `section` is empty (or does not exist yet) for a section that is about to be created,
+// which the device adds after every other section.
+// If the `plan` does not say where the section should be,
+// or it is already there,
+// the index is nil.
+// Any diagnostic information found in the process (including errors) is returned.
+func sectionIndex(
+	ctx context.Context,
+	client lucirpc.Client,
+	plan tfsdk.Plan,
+	config string,
+	sectionType string,
+	section string,
+) (*int, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	var (
+		after    types.String
+		before   types.String
+		position types.Int64
+	)
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root(AfterAttribute), &after)...)
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root(BeforeAttribute), &before)...)
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root(PositionAttribute), &position)...)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	if !isKnown(after) && !isKnown(before) && !isKnown(position) {
+		return nil, diagnostics
+	}
+
+	sections, listDiagnostics := ListSections(ctx, client, config, "")
+	diagnostics.Append(listDiagnostics...)
+	if diagnostics.HasError() {
+		return nil, diagnostics
+	}
+
+	// UCI counts the index without the section being moved.
+	current := len(sections)
+	others := []lucirpc.Options{}
+	for index, options := range sections {
+		name, err := options.GetString(idUCISection)
+		if err != nil {
+			diagnostics.Append(NewClientErrorDiagnostic(
+				fmt.Sprintf("problem reading the order of %s.%s sections", config, sectionType),
+				lucirpc.NewProtocolError(err),
+			))
+			return nil, diagnostics
+		}
+
+		if section != "" && name == section {
+			current = index
+			continue
+		}
+
+		others = append(others, options)
+	}
+
+	var (
+		index int
+		ok    bool
+	)
+	switch {
+	case isKnown(after):
+		index, ok = indexOfSection(others, after.ValueString())
+		if !ok {
+			diagnostics.AddAttributeError(
+				path.Root(AfterAttribute),
+				fmt.Sprintf("Section %s.%s does not exist", config, after.ValueString()),
+				"Can only move a section after a section that exists.",
+			)
+			return nil, diagnostics
+		}
+
+		index++
+
+	case isKnown(before):
+		index, ok = indexOfSection(others, before.ValueString())
+		if !ok {
+			diagnostics.AddAttributeError(
+				path.Root(BeforeAttribute),
+				fmt.Sprintf("Section %s.%s does not exist", config, before.ValueString()),
+				"Can only move a section before a section that exists.",
+			)
+			return nil, diagnostics
+		}
+
+	default:
+		index = indexOfPosition(others, sectionType, int(position.ValueInt64()))
+	}
+
+	if index == current {
+		return nil, diagnostics
+	}
+
+	return &index, diagnostics
+}
+
+// readResponseOrderInt64 reads an order attribute like [ReadResponseOptionInt64].
+// A resource only reads it if it is already set,
+// so sections added or moved elsewhere in the config do not change it.
+func readResponseOrderInt64[Model any](
+	get func(Model) types.Int64,
+	set func(*Model, types.Int64),
+	attribute string,
+	option string,
+) func(context.Context, string, string, lucirpc.Options, Model) (context.Context, Model, diag.Diagnostics) {
+	readResponse := ReadResponseOptionInt64(set, attribute, option)
+	return func(
+		ctx context.Context,
+		fullTypeName string,
+		terraformType string,
+		section lucirpc.Options,
+		model Model,
+	) (context.Context, Model, diag.Diagnostics) {
+		if terraformType == ResourceTerraformType && !isKnown(get(model)) {
+			set(&model, types.Int64Null())
+			return ctx, model, diag.Diagnostics{}
+		}
+
+		return readResponse(ctx, fullTypeName, terraformType, section, model)
+	}
+}
+
+// readResponseOrderString reads an order attribute like [ReadResponseOptionString].
+// A resource only reads it if it is already set,
+// so sections added or moved elsewhere in the config do not change it.
+func readResponseOrderString[Model any](
+	get func(Model) types.String,
+	set func(*Model, types.String),
+	attribute string,
+	option string,
+) func(context.Context, string, string, lucirpc.Options, Model) (context.Context, Model, diag.Diagnostics) {
+	readResponse := ReadResponseOptionString(set, attribute, option)
+	return func(
+		ctx context.Context,
+		fullTypeName string,
+		terraformType string,
+		section lucirpc.Options,
+		model Model,
+	) (context.Context, Model, diag.Diagnostics) {
+		if terraformType == ResourceTerraformType && !isKnown(get(model)) {
+			set(&model, types.StringNull())
+			return ctx, model, diag.Diagnostics{}
+		}
+
+		return readResponse(ctx, fullTypeName, terraformType, section, model)
+	}
+}
+
+// readSectionOrder adds the order metadata to a `section` that was already retrieved.
+// Any diagnostic information found in the process (including errors) is returned.
+func readSectionOrder(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
+	section lucirpc.Options,
+) (lucirpc.Options, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	name, err := section.GetString(idUCISection)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem reading the order of a %s section", config),
+			lucirpc.NewProtocolError(err),
+		))
+		return section, diagnostics
+	}
+
+	sectionType, err := section.GetString(typeUCISection)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem reading the order of %s.%s section", config, name),
+			lucirpc.NewProtocolError(err),
+		))
+		return section, diagnostics
+	}
+
+	sections, listDiagnostics := ListSections(ctx, client, config, sectionType)
+	diagnostics.Append(listDiagnostics...)
+	if diagnostics.HasError() {
+		return section, diagnostics
+	}
+
+	sections, err = addSectionOrder(sections)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem reading the order of %s.%s section", config, name),
+			lucirpc.NewProtocolError(err),
+		))
+		return section, diagnostics
+	}
+
+	index, ok := indexOfSection(sections, name)
+	if !ok {
+		return section, diagnostics
+	}
+
+	ordered := lucirpc.Options{}
+	for option, value := range section {
+		ordered[option] = value
+	}
+
+	for _, metadata := range []string{orderAfterMetadata, orderBeforeMetadata, orderPositionMetadata} {
+		if value, ok := sections[index][metadata]; ok {
+			ordered[metadata] = value
+		}
+	}
+
+	return ordered, diagnostics
+}
+
+// indexOfPosition finds the index among every section that puts a section at the `position` among the sections of the `sectionType`.
+// If there are not enough sections of the `sectionType`,
+// the index is directly after the last one.
+func indexOfPosition(
+	sections []lucirpc.Options,
+	sectionType string,
+	position int,
+) int {
+	last := -1
+	count := 0
+	for index, section := range sections {
+		otherType, err := section.GetString(typeUCISection)
+		if err != nil || otherType != sectionType {
+			continue
+		}
+
+		if count == position {
+			return index
+		}
+
+		count++
+		last = index
+	}
+
+	if last < 0 {
+		return len(sections)
+	}
+
+	return last + 1
+}
+
+// indexOfSection finds the index of the section with the `name`.
+func indexOfSection(
+	sections []lucirpc.Options,
+	name string,
+) (int, bool) {
+	for index, section := range sections {
+		otherName, err := section.GetString(idUCISection)
+		if err == nil && otherName == name {
+			return index, true
+		}
+	}
+
+	return -1, false
+}
+
+func isKnown(
+	value interface {
+		IsNull() bool
+		IsUnknown() bool
+	},
+) bool {
+	return !value.IsNull() && !value.IsUnknown()
+}
@@ -0,0 +1,36 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+)
+
+// reportPendingChanges returns a copy of `ctx` that warns about any pending changes found while changing a config.
+// The warnings are added to `diagnostics`.
+func reportPendingChanges(
+	ctx context.Context,
+	diagnostics *diag.Diagnostics,
+) context.Context {
+	return lucirpc.ContextWithPendingChangesReporter(
+		ctx,
+		func(pending lucirpc.PendingChangesError, policy lucirpc.PendingChangesPolicy) {
+			changes := strings.Join(pending.Changes(), "\n")
+			if policy == lucirpc.PendingChangesRevert {
+				diagnostics.AddWarning(
+					fmt.Sprintf("Reverted pending changes to %s config", pending.Config()),
+					fmt.Sprintf("These changes were staged on the device, but not committed. They were thrown away before applying this change:\n\n%s", changes),
+				)
+				return
+			}
+
+			diagnostics.AddWarning(
+				fmt.Sprintf("Committed pending changes to %s config", pending.Config()),
+				fmt.Sprintf("These changes were staged on the device, but not committed. They were committed along with this change:\n\n%s", changes),
+			)
+		},
+	)
+}
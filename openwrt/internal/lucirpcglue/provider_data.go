@@ -2,7 +2,6 @@ package lucirpcglue
 
 import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 )
 
 type ConfigureRequest struct {
@@ -10,12 +9,14 @@ type ConfigureRequest struct {
 }
 
 func NewProviderData(
-	client lucirpc.Client,
+	devices *Devices,
 	typeName string,
+	sectionIds SectionIds,
 ) ProviderData {
 	return ProviderData{
-		Client:   client,
-		TypeName: typeName,
+		Devices:    devices,
+		SectionIds: sectionIds,
+		TypeName:   typeName,
 	}
 }
 
@@ -38,6 +39,7 @@ func ParseProviderData(
 }
 
 type ProviderData struct {
-	Client   lucirpc.Client
-	TypeName string
+	Devices    *Devices
+	SectionIds SectionIds
+	TypeName   string
 }
@@ -0,0 +1,196 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"golang.org/x/exp/slices"
+)
+
+// SectionReference declares that an attribute names a section on the device
+// (e.g. a firewall rule's `src` must name an existing `firewall.zone`).
+// References are checked when planning.
+//
+// A section created in the same plan does not exist on the device yet,
+// so a value that does not name an existing section is only a warning.
+type SectionReference struct {
+	// Config is the UCI config of the referenced sections (e.g. `firewall`).
+	Config string
+
+	// Option is the option of the referenced sections that the value has to match (e.g. `name` for zones).
+	// If empty, the value has to match the name of the section.
+	Option string
+
+	// SpecialValues are values that do not name a section (e.g. `*` for any zone).
+	SpecialValues []string
+
+	// Type is the UCI type of the referenced sections (e.g. `zone`).
+	Type string
+}
+
+// description describes the referenced sections (e.g. "`firewall.zone` (by its `name`)").
+func (r SectionReference) description() string {
+	if r.Option == "" {
+		return fmt.Sprintf("`%s.%s`", r.Config, r.Type)
+	}
+
+	return fmt.Sprintf("`%s.%s` (by its `%s`)", r.Config, r.Type, r.Option)
+}
+
+// referencingSchemaAttribute is a [SchemaAttribute] whose values can name sections on the device.
+type referencingSchemaAttribute interface {
+	referencedValues(context.Context, attributeGetter, path.Path) ([]string, diag.Diagnostics)
+	sectionReferences() []SectionReference
+}
+
+// referencedSections finds the sections that can be referenced, and caches them for the rest of the plan.
+type referencedSections struct {
+	client lucirpc.Client
+	values map[string][]string
+}
+
+// checkReferences warns about attribute values that do not name a section on the device.
+//
+// Only values that changed from the `state` are checked,
+// so a resource that is not being changed does not look up its references on every plan.
+// The `state` is nil when the resource is being created.
+func checkReferences[Model any](
+	ctx context.Context,
+	client lucirpc.Client,
+	attributes map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options],
+	plan attributeGetter,
+	state attributeGetter,
+) diag.Diagnostics {
+	allDiagnostics := diag.Diagnostics{}
+	sections := referencedSections{
+		client: client,
+		values: map[string][]string{},
+	}
+	names := []string{}
+	for name := range attributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		attribute, ok := attributes[name].(referencingSchemaAttribute)
+		if !ok || len(attribute.sectionReferences()) == 0 {
+			continue
+		}
+
+		attributePath := path.Root(name)
+		values, diagnostics := attribute.referencedValues(ctx, plan, attributePath)
+		allDiagnostics.Append(diagnostics...)
+		if diagnostics.HasError() {
+			continue
+		}
+
+		if state != nil {
+			priorValues, diagnostics := attribute.referencedValues(ctx, state, attributePath)
+			if !diagnostics.HasError() && slices.Equal(values, priorValues) {
+				continue
+			}
+		}
+
+		for _, value := range values {
+			if sections.has(ctx, attribute.sectionReferences(), value) {
+				continue
+			}
+
+			descriptions := []string{}
+			for _, reference := range attribute.sectionReferences() {
+				descriptions = append(descriptions, reference.description())
+			}
+
+			allDiagnostics.AddAttributeWarning(
+				attributePath,
+				"Missing reference",
+				fmt.Sprintf("%q must name an existing %s, but the device does not have one named %q. This can be ignored if it is created by this plan.", name, strings.Join(descriptions, " or "), value),
+			)
+		}
+	}
+
+	return allDiagnostics
+}
+
+// has checks if the `value` names a section for any of the `references`.
+// If the sections cannot be listed, the value is assumed to name one.
+func (s referencedSections) has(
+	ctx context.Context,
+	references []SectionReference,
+	value string,
+) bool {
+	for _, reference := range references {
+		if slices.Contains(reference.SpecialValues, value) {
+			return true
+		}
+
+		values, ok := s.list(ctx, reference)
+		if !ok || slices.Contains(values, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// list returns the values that name a section for the `reference`.
+func (s referencedSections) list(
+	ctx context.Context,
+	reference SectionReference,
+) ([]string, bool) {
+	key := fmt.Sprintf("%s.%s.%s", reference.Config, reference.Type, reference.Option)
+	values, ok := s.values[key]
+	if ok {
+		return values, values != nil
+	}
+
+	option := reference.Option
+	if option == "" {
+		option = idUCISection
+	}
+
+	sections, err := s.client.ListSections(ctx, reference.Config, reference.Type)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Unable to list %s.%s sections, not checking references to them", reference.Config, reference.Type), map[string]any{
+			"error": err.Error(),
+		})
+		s.values[key] = nil
+		return nil, false
+	}
+
+	values = []string{}
+	for _, section := range sections {
+		value, err := section.GetString(option)
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+
+	s.values[key] = values
+	return values, true
+}
+
+// knownStrings returns the known string `elements` of a list or set.
+// Unknown elements are not known until they are applied, so they cannot be checked.
+func knownStrings(
+	elements []attr.Value,
+) []string {
+	result := []string{}
+	for _, element := range elements {
+		value, ok := element.(types.String)
+		if ok && !value.IsNull() && !value.IsUnknown() {
+			result = append(result, value.ValueString())
+		}
+	}
+
+	return result
+}
@@ -1,11 +1,13 @@
 package lucirpcglue
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"math/rand"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -19,6 +21,7 @@ var (
 	_ frameworkresource.Resource                = &resource[any]{}
 	_ frameworkresource.ResourceWithConfigure   = &resource[any]{}
 	_ frameworkresource.ResourceWithImportState = &resource[any]{}
+	_ frameworkresource.ResourceWithModifyPlan  = &resource[any]{}
 )
 
 func NewResource[Model any](
@@ -39,11 +42,12 @@ func NewResource[Model any](
 }
 
 type resource[Model any] struct {
-	client            lucirpc.Client
+	devices           *Devices
 	fullTypeName      string
 	getId             func(Model) types.String
 	schemaAttributes  map[string]SchemaAttribute[Model, lucirpc.Options, lucirpc.Options]
 	schemaDescription string
+	sectionIds        SectionIds
 	terraformType     string
 	uciConfig         string
 	uciType           string
@@ -67,8 +71,9 @@ func (d *resource[Model]) Configure(
 		return
 	}
 
-	d.client = providerData.Client
+	d.devices = providerData.Devices
 	d.fullTypeName = d.getFullTypeName(providerData.TypeName)
+	d.sectionIds = providerData.SectionIds
 }
 
 // Create constructs a new resource and sets the initial Terraform state.
@@ -87,6 +92,12 @@ func (d *resource[Model]) Create(
 		return
 	}
 
+	ctx, client, device, diagnostics := deviceClient(ctx, d.devices, d.schemaAttributes, req.Plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx, options, diagnostics := GenerateUpsertBody(
 		ctx,
 		d.fullTypeName,
@@ -98,25 +109,57 @@ func (d *resource[Model]) Create(
 		return
 	}
 
-	var id string
-	// if the model does not have an Id set, then auto generate one
-	// otherwise, use the one defined
-	// this id is used to identify the section in lucirpc and does not need to be set explicitly
-	if d.getId(model).IsNull() || len(d.getId(model).ValueString()) == 0 {
-		i := rand.Int()
-		id = fmt.Sprintf("tfcfg%d", i)
+	// The id is used to identify the section in lucirpc and does not need to be set explicitly.
+	// If it is not set, the section is named according to the provider's section id strategy.
+	id := d.getId(model).ValueString()
+	var index *int
+	if hasSectionOrder(d.schemaAttributes) {
+		tflog.Debug(ctx, "Finding where to move section")
+		index, diagnostics = sectionIndex(
+			ctx,
+			client,
+			req.Plan,
+			d.uciConfig,
+			d.uciType,
+			id,
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if d.getId(model).IsNull() || len(id) == 0 {
+		id, diagnostics = createSectionWithoutId(
+			ctx,
+			client,
+			d.sectionIds,
+			req.Plan,
+			d.uciConfig,
+			d.uciType,
+			options,
+			index,
+		)
 	} else {
-		id = d.getId(model).ValueString()
+		diagnostics = CreateSection(
+			ctx,
+			client,
+			d.uciConfig,
+			d.uciType,
+			id,
+			options,
+			index,
+		)
 	}
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", d.uciConfig, id))
-	diagnostics = CreateSection(
-		ctx,
-		d.client,
-		d.uciConfig,
-		d.uciType,
-		id,
-		options,
-	)
+
+	// Moving an anonymous section renames it.
+	id, diagnostics = d.currentId(ctx, client, id, options)
 	res.Diagnostics.Append(diagnostics...)
 	if res.Diagnostics.HasError() {
 		return
@@ -127,7 +170,8 @@ func (d *resource[Model]) Create(
 		ctx,
 		d.fullTypeName,
 		d.terraformType,
-		d.client,
+		client,
+		device,
 		d.schemaAttributes,
 		d.uciConfig,
 		id,
@@ -161,13 +205,35 @@ func (d *resource[Model]) Delete(
 		return
 	}
 
+	ctx, client, _, diagnostics := deviceClient(ctx, d.devices, d.schemaAttributes, req.State)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx = logger.SetFieldString(ctx, d.fullTypeName, d.terraformType, IdAttribute, d.getId(model))
 	id := d.getId(model).ValueString()
+	_, options, diagnostics := GenerateUpsertBody(ctx, d.fullTypeName, model, d.schemaAttributes)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	section, found, diagnostics := d.findSection(ctx, client, id, options)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if found {
+		id, _ = section.GetString(idUCISection)
+	}
+
 	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", d.uciConfig, id))
 	tflog.Debug(ctx, "Deleting existing section")
 	diagnostics = DeleteSection(
 		ctx,
-		d.client,
+		client,
 		d.uciConfig,
 		id,
 	)
@@ -184,7 +250,23 @@ func (d *resource[Model]) ImportState(
 	res *frameworkresource.ImportStateResponse,
 ) {
 	tflog.Debug(ctx, "Retrieving import id and saving to id attribute")
-	frameworkresource.ImportStatePassthroughID(ctx, path.Root(IdAttribute), req, res)
+	if _, ok := d.schemaAttributes[TargetDeviceAttribute]; !ok {
+		frameworkresource.ImportStatePassthroughID(ctx, path.Root(IdAttribute), req, res)
+		return
+	}
+
+	// Sections on other devices are imported with the device before the id (e.g. `office/lan`).
+	device, id := ParseDeviceImportId(req.ID)
+	if device != "" {
+		diagnostics := res.State.SetAttribute(ctx, path.Root(TargetDeviceAttribute), device)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	diagnostics := res.State.SetAttribute(ctx, path.Root(IdAttribute), id)
+	res.Diagnostics.Append(diagnostics...)
 }
 
 // Metadata sets the resource type name.
@@ -196,6 +278,55 @@ func (d *resource[Model]) Metadata(
 	res.TypeName = d.getFullTypeName(req.ProviderTypeName)
 }
 
+// ModifyPlan checks that the device has what the resource needs,
+// so a missing package is reported before anything is changed.
+// It also checks that attributes with a [SectionReference] name sections the device has.
+func (d *resource[Model]) ModifyPlan(
+	ctx context.Context,
+	req frameworkresource.ModifyPlanRequest,
+	res *frameworkresource.ModifyPlanResponse,
+) {
+	// Nothing is needed to destroy a resource,
+	// and nothing can be checked before the provider is configured.
+	if req.Plan.Raw.IsNull() || d.devices == nil {
+		return
+	}
+
+	device := ""
+	if _, ok := d.schemaAttributes[TargetDeviceAttribute]; ok {
+		var targetDevice types.String
+		diagnostics := req.Plan.GetAttribute(ctx, path.Root(TargetDeviceAttribute), &targetDevice)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() || targetDevice.IsUnknown() {
+			return
+		}
+
+		device = targetDevice.ValueString()
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Checking the device has the %s config", d.uciConfig))
+	diagnostics := checkDeviceConfig(ctx, d.devices, device, d.uciConfig)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	client, diagnostics := d.devices.Client(ctx, device)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	var state attributeGetter
+	if !req.State.Raw.IsNull() {
+		state = req.State
+	}
+
+	tflog.Debug(ctx, "Checking references to other sections")
+	diagnostics = checkReferences(ctx, client, d.schemaAttributes, req.Plan, state)
+	res.Diagnostics.Append(diagnostics...)
+}
+
 // Read refreshes the Terraform state with the latest data.
 func (d *resource[Model]) Read(
 	ctx context.Context,
@@ -212,14 +343,49 @@ func (d *resource[Model]) Read(
 		return
 	}
 
-	ctx, model, diagnostics = ReadModel(
+	ctx, client, device, diagnostics := deviceClient(ctx, d.devices, d.schemaAttributes, req.State)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	id := d.getId(model).ValueString()
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", d.uciConfig, id))
+	_, options, diagnostics := GenerateUpsertBody(ctx, d.fullTypeName, model, d.schemaAttributes)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	section, found, diagnostics := d.findSection(ctx, client, id, options)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if !found {
+		// The section was removed outside of Terraform.
+		// Removing the resource from state lets Terraform plan to create it again.
+		tflog.Info(ctx, "Section no longer exists, removing the resource from state")
+		res.State.RemoveResource(ctx)
+		return
+	}
+
+	section = addDeviceMetadata(section, device)
+	if hasSectionOrder(d.schemaAttributes) {
+		section, diagnostics = readSectionOrder(ctx, client, d.uciConfig, section)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	ctx, model, diagnostics = ReadModelFromSection(
 		ctx,
 		d.fullTypeName,
 		d.terraformType,
-		d.client,
 		d.schemaAttributes,
-		d.uciConfig,
-		d.getId(model).ValueString(),
+		section,
 	)
 	res.Diagnostics.Append(diagnostics...)
 	if res.Diagnostics.HasError() {
@@ -267,6 +433,14 @@ func (d *resource[Model]) Update(
 		return
 	}
 
+	tflog.Debug(ctx, "Retrieving values from state")
+	var state Model
+	diagnostics = req.State.Get(ctx, &state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx, options, diagnostics := GenerateUpsertBody(
 		ctx,
 		d.fullTypeName,
@@ -278,26 +452,89 @@ func (d *resource[Model]) Update(
 		return
 	}
 
+	ctx, client, device, diagnostics := deviceClient(ctx, d.devices, d.schemaAttributes, req.Plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, removedOptions, diagnostics := GenerateRemovedOptions(
+		ctx,
+		d.fullTypeName,
+		req.Plan,
+		state,
+		d.schemaAttributes,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	// The id is not known when it is not set explicitly,
+	// as the device can rename anonymous sections.
 	id := d.getId(model).ValueString()
+	if d.getId(model).IsUnknown() {
+		id = d.getId(state).ValueString()
+	}
+
+	_, stateOptions, diagnostics := GenerateUpsertBody(ctx, d.fullTypeName, state, d.schemaAttributes)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	id, diagnostics = d.currentId(ctx, client, id, stateOptions)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", d.uciConfig, id))
-	diagnostics = UpdateSection(
+	var index *int
+	if hasSectionOrder(d.schemaAttributes) {
+		tflog.Debug(ctx, "Finding where to move section")
+		index, diagnostics = sectionIndex(
+			ctx,
+			client,
+			req.Plan,
+			d.uciConfig,
+			d.uciType,
+			id,
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	diagnostics = ChangeSection(
 		ctx,
-		d.client,
+		client,
 		d.uciConfig,
 		id,
 		options,
+		removedOptions,
+		index,
 	)
 	res.Diagnostics.Append(diagnostics...)
 	if res.Diagnostics.HasError() {
 		return
 	}
 
+	// Moving an anonymous section renames it.
+	id, diagnostics = d.currentId(ctx, client, id, options)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
 	tflog.Debug(ctx, "Reading updated section")
 	ctx, model, diagnostics = ReadModel(
 		ctx,
 		d.fullTypeName,
 		d.terraformType,
-		d.client,
+		client,
+		device,
 		d.schemaAttributes,
 		d.uciConfig,
 		id,
@@ -315,6 +552,78 @@ func (d *resource[Model]) Update(
 	}
 }
 
+// currentId finds the current name of the section with the `id`,
+// which can change for anonymous sections.
+// Any diagnostic information found in the process (including errors) is returned.
+func (d *resource[Model]) currentId(
+	ctx context.Context,
+	client lucirpc.Client,
+	id string,
+	options lucirpc.Options,
+) (string, diag.Diagnostics) {
+	section, found, diagnostics := d.findSection(ctx, client, id, options)
+	if diagnostics.HasError() {
+		return id, diagnostics
+	}
+
+	if !found {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem getting %s.%s section", d.uciConfig, id),
+			lucirpc.NewNotFoundError(d.uciConfig, id),
+		))
+		return id, diagnostics
+	}
+
+	current, err := section.GetString(idUCISection)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem getting %s.%s section", d.uciConfig, id),
+			lucirpc.NewProtocolError(err),
+		))
+		return id, diagnostics
+	}
+
+	return current, diagnostics
+}
+
+// findSection gets the section with the `id`, if it exists.
+// If an anonymous section was renamed,
+// it is found again by having the same values as the `options`.
+// Any diagnostic information found in the process (including errors) is returned.
+func (d *resource[Model]) findSection(
+	ctx context.Context,
+	client lucirpc.Client,
+	id string,
+	options lucirpc.Options,
+) (lucirpc.Options, bool, diag.Diagnostics) {
+	want, err := json.Marshal(options)
+	if err != nil {
+		diagnostics := diag.Diagnostics{}
+		diagnostics.AddError(
+			fmt.Sprintf("Could not look for %s.%s section", d.uciConfig, id),
+			err.Error(),
+		)
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	matches := func(section lucirpc.Options) bool {
+		_, model, diagnostics := ReadModelFromSection(ctx, d.fullTypeName, d.terraformType, d.schemaAttributes, section)
+		if diagnostics.HasError() {
+			return false
+		}
+
+		_, sectionOptions, diagnostics := GenerateUpsertBody(ctx, d.fullTypeName, model, d.schemaAttributes)
+		if diagnostics.HasError() {
+			return false
+		}
+
+		got, err := json.Marshal(sectionOptions)
+		return err == nil && bytes.Equal(got, want)
+	}
+
+	return FindSection(ctx, client, d.uciConfig, d.uciType, id, matches)
+}
+
 func (d resource[Model]) getFullTypeName(
 	providerTypeName string,
 ) string {
@@ -2,42 +2,170 @@ package lucirpcglue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 )
 
-// CreateSection attempts to create a new section.
+// AddSection attempts to create a new anonymous section.
+// If there is an `index`,
+// the section is moved there among every section of the `config` in the same commit.
+// The name the device generated for the section is returned.
 // Any diagnostic information found in the process (including errors) is returned.
-func CreateSection(
+func AddSection(
 	ctx context.Context,
 	client lucirpc.Client,
 	config string,
 	sectionType string,
+	options lucirpc.Options,
+	index *int,
+) (string, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	ctx = reportPendingChanges(ctx, &diagnostics)
+	var (
+		section string
+		err     error
+	)
+	if index == nil {
+		section, err = client.AddSection(
+			ctx,
+			config,
+			sectionType,
+			options,
+		)
+	} else {
+		section, err = client.AddSectionAt(
+			ctx,
+			config,
+			sectionType,
+			options,
+			*index,
+		)
+	}
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem adding %s.%s section", config, sectionType),
+			err,
+		))
+		return "", diagnostics
+	}
+
+	return section, diagnostics
+}
+
+// ChangeSection attempts to set the `options` of an existing section,
+// remove any of the `removedOptions` that are still set on it,
+// and move it to the `index` among every section of the `config` if there is one.
+// These are all committed together.
+// Options that are already gone are skipped,
+// as the device refuses to delete them.
+// Any diagnostic information found in the process (including errors) is returned.
+func ChangeSection(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
 	section string,
 	options lucirpc.Options,
+	removedOptions []string,
+	index *int,
 ) diag.Diagnostics {
 	diagnostics := diag.Diagnostics{}
-	result, err := client.CreateSection(
+	existing := []string{}
+	if len(removedOptions) > 0 {
+		current, getDiagnostics := GetSection(ctx, client, config, section)
+		diagnostics.Append(getDiagnostics...)
+		if diagnostics.HasError() {
+			return diagnostics
+		}
+
+		for _, option := range removedOptions {
+			if _, ok := current[option]; ok {
+				existing = append(existing, option)
+			}
+		}
+	}
+
+	ctx = reportPendingChanges(ctx, &diagnostics)
+	result, err := client.ChangeSection(
 		ctx,
 		config,
-		sectionType,
 		section,
-		options,
+		lucirpc.SectionChanges{
+			DeleteOptions: existing,
+			Index:         index,
+			Options:       options,
+		},
 	)
 	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem updating %s.%s section", config, section),
+			err,
+		))
+		return diagnostics
+	}
+
+	if !result {
 		diagnostics.AddError(
-			fmt.Sprintf("problem creating %s.%s section", config, section),
-			err.Error(),
+			fmt.Sprintf("Could not update %s.%s section", config, section),
+			fmt.Sprintf("The device refused to update the section without saying why. Check that the %q config exists on the device, and that the values provided are acceptable.", config),
+		)
+		return diagnostics
+	}
+
+	return diagnostics
+}
+
+// CreateSection attempts to create a new section.
+// If there is an `index`,
+// the section is moved there among every section of the `config` in the same commit.
+// Any diagnostic information found in the process (including errors) is returned.
+func CreateSection(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
+	sectionType string,
+	section string,
+	options lucirpc.Options,
+	index *int,
+) diag.Diagnostics {
+	diagnostics := diag.Diagnostics{}
+	ctx = reportPendingChanges(ctx, &diagnostics)
+	var (
+		result bool
+		err    error
+	)
+	if index == nil {
+		result, err = client.CreateSection(
+			ctx,
+			config,
+			sectionType,
+			section,
+			options,
+		)
+	} else {
+		result, err = client.CreateSectionAt(
+			ctx,
+			config,
+			sectionType,
+			section,
+			options,
+			*index,
 		)
+	}
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem creating %s.%s section", config, section),
+			err,
+		))
 		return diagnostics
 	}
 
 	if !result {
 		diagnostics.AddError(
 			fmt.Sprintf("Could not create %s.%s section", config, section),
-			"It is not currently known why this happens. It is unclear if this is a problem with the provider. Please double check the values provided are acceptable.",
+			fmt.Sprintf("The device refused to create the section without saying why. Check that the %q config exists on the device, that %q is a valid section name, and that the values provided are acceptable.", config, section),
 		)
 		return diagnostics
 	}
@@ -54,23 +182,24 @@ func DeleteSection(
 	section string,
 ) diag.Diagnostics {
 	diagnostics := diag.Diagnostics{}
+	ctx = reportPendingChanges(ctx, &diagnostics)
 	result, err := client.DeleteSection(
 		ctx,
 		config,
 		section,
 	)
 	if err != nil {
-		diagnostics.AddError(
+		diagnostics.Append(NewClientErrorDiagnostic(
 			fmt.Sprintf("problem deleting %s.%s section", config, section),
-			err.Error(),
-		)
+			err,
+		))
 		return diagnostics
 	}
 
 	if !result {
 		diagnostics.AddError(
 			fmt.Sprintf("Could not delete %s.%s section", config, section),
-			"It is not currently known why this happens. It is unclear if this is a problem with the provider. Please double check the values provided are acceptable.",
+			fmt.Sprintf("The device refused to delete the section without saying why. Check that the %q config exists on the device.", config),
 		)
 		return diagnostics
 	}
@@ -89,16 +218,69 @@ func GetSection(
 	diagnostics := diag.Diagnostics{}
 	result, err := client.GetSection(ctx, config, section)
 	if err != nil {
-		diagnostics.AddError(
+		diagnostics.Append(NewClientErrorDiagnostic(
 			fmt.Sprintf("problem getting %s.%s section", config, section),
-			err.Error(),
-		)
+			err,
+		))
 		return lucirpc.Options{}, diagnostics
 	}
 
 	return result, diagnostics
 }
 
+// GetSectionIfExists attempts to get an existing section.
+// If the section does not exist, the returned bool is false and there are no errors.
+// Any other diagnostic information found in the process (including errors) is returned.
+func GetSectionIfExists(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
+	section string,
+) (lucirpc.Options, bool, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	result, err := client.GetSection(ctx, config, section)
+	var notFoundError lucirpc.NotFoundError
+	if errors.As(err, &notFoundError) {
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem getting %s.%s section", config, section),
+			err,
+		))
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	return result, true, diagnostics
+}
+
+// ListSections gets every section of the `sectionType` in the `config`, in order.
+// Any diagnostic information found in the process (including errors) is returned.
+func ListSections(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
+	sectionType string,
+) ([]lucirpc.Options, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	result, err := client.ListSections(ctx, config, sectionType)
+	if err != nil {
+		summary := fmt.Sprintf("problem listing %s.%s sections", config, sectionType)
+		if sectionType == "" {
+			summary = fmt.Sprintf("problem listing %s sections", config)
+		}
+
+		diagnostics.Append(NewClientErrorDiagnostic(
+			summary,
+			err,
+		))
+		return []lucirpc.Options{}, diagnostics
+	}
+
+	return result, diagnostics
+}
+
 // UpdateSection attempts to update an existing section.
 // Any diagnostic information found in the process (including errors) is returned.
 func UpdateSection(
@@ -109,6 +291,7 @@ func UpdateSection(
 	options lucirpc.Options,
 ) diag.Diagnostics {
 	diagnostics := diag.Diagnostics{}
+	ctx = reportPendingChanges(ctx, &diagnostics)
 	result, err := client.UpdateSection(
 		ctx,
 		config,
@@ -116,17 +299,17 @@ func UpdateSection(
 		options,
 	)
 	if err != nil {
-		diagnostics.AddError(
+		diagnostics.Append(NewClientErrorDiagnostic(
 			fmt.Sprintf("problem updating %s.%s section", config, section),
-			err.Error(),
-		)
+			err,
+		))
 		return diagnostics
 	}
 
 	if !result {
 		diagnostics.AddError(
 			fmt.Sprintf("Could not update %s.%s section", config, section),
-			"It is not currently known why this happens. It is unclear if this is a problem with the provider. Please double check the values provided are acceptable.",
+			fmt.Sprintf("The device refused to update the section without saying why. Check that the %q config exists on the device, and that the values provided are acceptable.", config),
 		)
 		return diagnostics
 	}
@@ -0,0 +1,227 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+)
+
+const (
+	// SectionIdStrategyAnonymous creates an anonymous section,
+	// and uses the name the device generates for it (e.g. `cfg0392bd`).
+	// The device names it again whenever an earlier section is removed or moved,
+	// so the section is found again by its options when that happens.
+	SectionIdStrategyAnonymous = "anonymous"
+
+	// SectionIdStrategyAttribute names the section after the value of another attribute (e.g. `name`).
+	SectionIdStrategyAttribute = "attribute"
+
+	// SectionIdStrategyRandom names the section `tfcfg` followed by a random number.
+	SectionIdStrategyRandom = "random"
+)
+
+var (
+	// anonymousSectionName matches the names UCI gives anonymous sections:
+	// a counter of the sections in the config, followed by a hash of the section's type.
+	anonymousSectionName = regexp.MustCompile(`^cfg([0-9a-f]{2})([0-9a-f]{4})$`)
+
+	invalidSectionNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// SectionIds decides how a section is named when a resource does not set its `id`.
+type SectionIds struct {
+	// Attribute is the attribute used by [SectionIdStrategyAttribute].
+	Attribute string
+
+	// Strategy is one of [SectionIdStrategyAnonymous], [SectionIdStrategyAttribute], or [SectionIdStrategyRandom].
+	Strategy string
+}
+
+// IsSectionIdStrategy checks if the `strategy` is one that [SectionIds] understands.
+func IsSectionIdStrategy(
+	strategy string,
+) bool {
+	switch strategy {
+	case SectionIdStrategyAnonymous, SectionIdStrategyAttribute, SectionIdStrategyRandom:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// NewSectionIds constructs a [SectionIds].
+func NewSectionIds(
+	strategy string,
+	attribute string,
+) SectionIds {
+	return SectionIds{
+		Attribute: attribute,
+		Strategy:  strategy,
+	}
+}
+
+// FindSection gets the `section` if it exists.
+//
+// UCI names anonymous sections (e.g. `cfg0392bd`) every time it loads a config,
+// after their position in the config.
+// So an anonymous section is named again whenever an earlier section is removed or moved.
+// If an anonymous `section` no longer exists,
+// the anonymous section of the `sectionType` that `matches` is used instead.
+// If more than one matches,
+// the one closest to where the `section` was is used.
+//
+// If there is no such section, the returned bool is false and there are no errors.
+// Any other diagnostic information found in the process (including errors) is returned.
+func FindSection(
+	ctx context.Context,
+	client lucirpc.Client,
+	config string,
+	sectionType string,
+	section string,
+	matches func(lucirpc.Options) bool,
+) (lucirpc.Options, bool, diag.Diagnostics) {
+	result, found, diagnostics := GetSectionIfExists(ctx, client, config, section)
+	if diagnostics.HasError() || found {
+		return result, found, diagnostics
+	}
+
+	name := anonymousSectionName.FindStringSubmatch(section)
+	if name == nil {
+		return result, false, diagnostics
+	}
+
+	sections, diagnostics := ListSections(ctx, client, config, sectionType)
+	if diagnostics.HasError() {
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	position, _ := strconv.ParseInt(name[1], 16, 64)
+	closest := int64(-1)
+	for _, candidate := range sections {
+		candidateSection, err := candidate.GetString(idUCISection)
+		if err != nil {
+			continue
+		}
+
+		candidateName := anonymousSectionName.FindStringSubmatch(candidateSection)
+		if candidateName == nil || candidateName[2] != name[2] || !matches(candidate) {
+			continue
+		}
+
+		candidatePosition, _ := strconv.ParseInt(candidateName[1], 16, 64)
+		distance := candidatePosition - position
+		if distance < 0 {
+			distance = -distance
+		}
+
+		if closest < 0 || distance < closest {
+			closest = distance
+			result = candidate
+			found = true
+		}
+	}
+
+	if found {
+		renamed, _ := result.GetString(idUCISection)
+		tflog.Info(ctx, fmt.Sprintf("Anonymous section %s.%s is now named %s.%s", config, section, config, renamed))
+	}
+
+	return result, found, diagnostics
+}
+
+// sectionIdFromAttribute derives a section name from the value of the `attribute` in the `plan`.
+// Characters UCI does not allow in section names are replaced with underscores.
+// If the `attribute` is not a string, or has no value,
+// the returned bool is false and there are no errors.
+func sectionIdFromAttribute(
+	ctx context.Context,
+	plan tfsdk.Plan,
+	attribute string,
+) (string, bool, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	attributeType, typeDiagnostics := plan.Schema.TypeAtPath(ctx, path.Root(attribute))
+	if typeDiagnostics.HasError() || !attributeType.Equal(types.StringType) {
+		return "", false, diagnostics
+	}
+
+	var value types.String
+	diagnostics.Append(plan.GetAttribute(ctx, path.Root(attribute), &value)...)
+	if diagnostics.HasError() {
+		return "", false, diagnostics
+	}
+
+	if value.IsNull() || value.IsUnknown() || value.ValueString() == "" {
+		return "", false, diagnostics
+	}
+
+	return invalidSectionNameCharacters.ReplaceAllString(value.ValueString(), "_"), true, diagnostics
+}
+
+// createSectionWithoutId creates a section for a resource that does not set its `id`.
+// The name of the section depends on the strategy in `sectionIds`.
+// If there is an `index`, the section is moved there when it is created.
+// Any diagnostic information found in the process (including errors) is returned.
+func createSectionWithoutId(
+	ctx context.Context,
+	client lucirpc.Client,
+	sectionIds SectionIds,
+	plan tfsdk.Plan,
+	config string,
+	sectionType string,
+	options lucirpc.Options,
+	index *int,
+) (string, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	switch sectionIds.Strategy {
+	case SectionIdStrategyAnonymous:
+		return AddSection(ctx, client, config, sectionType, options, index)
+
+	case SectionIdStrategyAttribute:
+		section, ok, attributeDiagnostics := sectionIdFromAttribute(ctx, plan, sectionIds.Attribute)
+		diagnostics.Append(attributeDiagnostics...)
+		if diagnostics.HasError() {
+			return "", diagnostics
+		}
+
+		// Without a value to name the section after,
+		// the device is left to name it.
+		if !ok {
+			section, addDiagnostics := AddSection(ctx, client, config, sectionType, options, index)
+			diagnostics.Append(addDiagnostics...)
+			return section, diagnostics
+		}
+
+		_, exists, getDiagnostics := GetSectionIfExists(ctx, client, config, section)
+		diagnostics.Append(getDiagnostics...)
+		if diagnostics.HasError() {
+			return "", diagnostics
+		}
+
+		if exists {
+			diagnostics.AddAttributeError(
+				path.Root(sectionIds.Attribute),
+				fmt.Sprintf("Section %s.%s already exists", config, section),
+				fmt.Sprintf("The section name is derived from the %q attribute, but another section already has that name. Either change the %q attribute, or set the %q attribute explicitly.", sectionIds.Attribute, sectionIds.Attribute, IdAttribute),
+			)
+			return "", diagnostics
+		}
+
+		diagnostics.Append(CreateSection(ctx, client, config, sectionType, section, options, index)...)
+		return section, diagnostics
+
+	default:
+		section := fmt.Sprintf("tfcfg%d", rand.Int())
+		diagnostics.Append(CreateSection(ctx, client, config, sectionType, section, options, index)...)
+		return section, diagnostics
+	}
+}
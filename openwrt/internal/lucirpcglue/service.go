@@ -0,0 +1,42 @@
+package lucirpcglue
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+)
+
+// RunServiceAction attempts to run the `action` of the init script for the `service`.
+// Any diagnostic information found in the process (including errors) is returned.
+func RunServiceAction(
+	ctx context.Context,
+	client lucirpc.Client,
+	service string,
+	action lucirpc.ServiceAction,
+) diag.Diagnostics {
+	diagnostics := diag.Diagnostics{}
+	result, err := client.RunServiceAction(
+		ctx,
+		service,
+		action,
+	)
+	if err != nil {
+		diagnostics.Append(NewClientErrorDiagnostic(
+			fmt.Sprintf("problem running %s of %s service", action, service),
+			err,
+		))
+		return diagnostics
+	}
+
+	if !result {
+		diagnostics.AddError(
+			fmt.Sprintf("Could not %s %s service", action, service),
+			fmt.Sprintf("The device could not run `/etc/init.d/%s %s`. Check that the service is installed on the device, and that its init script supports %q.", service, action, action),
+		)
+		return diagnostics
+	}
+
+	return diagnostics
+}
@@ -45,10 +45,11 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		deviceAttribute:         deviceSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		portsAttribute:          portsSchemaAttribute,
-		vLanAttribute:           vLanSchemaAttribute,
+		deviceAttribute:                   deviceSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		portsAttribute:                    portsSchemaAttribute,
+		vLanAttribute:                     vLanSchemaAttribute,
 	}
 
 	vLanSchemaAttribute = lucirpcglue.Int64SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -72,6 +73,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -83,18 +93,21 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Device types.String `tfsdk:"device"`
-	Id     types.String `tfsdk:"id"`
-	Ports  types.List   `tfsdk:"ports"`
-	VLan   types.Int64  `tfsdk:"vlan"`
+	Device       types.String `tfsdk:"device"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Ports        types.List   `tfsdk:"ports"`
+	VLan         types.Int64  `tfsdk:"vlan"`
 }
 
-func modelGetDevice(m model) types.String { return m.Device }
-func modelGetId(m model) types.String     { return m.Id }
-func modelGetPorts(m model) types.List    { return m.Ports }
-func modelGetVLan(m model) types.Int64    { return m.VLan }
+func modelGetDevice(m model) types.String       { return m.Device }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetPorts(m model) types.List          { return m.Ports }
+func modelGetVLan(m model) types.Int64          { return m.VLan }
 
-func modelSetDevice(m *model, value types.String) { m.Device = value }
-func modelSetId(m *model, value types.String)     { m.Id = value }
-func modelSetPorts(m *model, value types.List)    { m.Ports = value }
-func modelSetVLan(m *model, value types.Int64)    { m.VLan = value }
+func modelSetDevice(m *model, value types.String)       { m.Device = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetPorts(m *model, value types.List)          { m.Ports = value }
+func modelSetVLan(m *model, value types.Int64)          { m.VLan = value }
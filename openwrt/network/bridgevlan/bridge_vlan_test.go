@@ -0,0 +1,125 @@
+package bridgevlan_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "bridge-vlan", "br_testing", map[string]any{
+		"device": "br-testing",
+		"ports":  []string{"eth0:t", "eth1"},
+		"vlan":   4,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_bridge_vlan" "this" {
+	id = "br_testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_bridge_vlan.this", "id", "br_testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_bridge_vlan.this", "device", "br-testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_bridge_vlan.this", "ports.0", "eth0:t"),
+			resource.TestCheckResourceAttr("data.openwrt_network_bridge_vlan.this", "ports.1", "eth1"),
+			resource.TestCheckResourceAttr("data.openwrt_network_bridge_vlan.this", "vlan", "4"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_bridge_vlan" "br_testing" {
+	id = "br_testing"
+	device = "br-testing"
+	ports = [
+		"eth0:t",
+		"eth1",
+	]
+	vlan = 4
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "id", "br_testing"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "ports.0", "eth0:t"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "ports.1", "eth1"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "vlan", "4"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_bridge_vlan.br_testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_bridge_vlan" "br_testing" {
+	id = "br_testing"
+	device = "br-testing"
+	ports = [
+		"eth0",
+		"eth1:t",
+	]
+	vlan = 6
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "id", "br_testing"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "ports.0", "eth0"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "ports.1", "eth1:t"),
+			resource.TestCheckResourceAttr("openwrt_network_bridge_vlan.br_testing", "vlan", "6"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "br_testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("br_testing"),
+					".type":      lucirpc.String("bridge-vlan"),
+					"device":     lucirpc.String("br-testing"),
+					"ports":      lucirpc.ListString([]string{"eth0", "eth1:t"}),
+					"vlan":       lucirpc.Integer(6),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
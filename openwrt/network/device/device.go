@@ -192,19 +192,20 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		bridgePortsAttribute:        bridgePortsSchemaAttribute,
-		bringUpEmptyBridgeAttribute: bringUpEmptyBridgeSchemaAttribute,
-		dadTransmitsAttribute:       dadTransmitsSchemaAttribute,
-		enableIPv6Attribute:         enableIPv6SchemaAttribute,
-		macAddressAttribute:         macAddressSchemaAttribute,
-		mtuAttribute:                mtuSchemaAttribute,
-		mtu6Attribute:               mtu6SchemaAttribute,
-		nameAttribute:               nameSchemaAttribute,
-		txQueueLengthAttribute:      txQueueLengthSchemaAttribute,
-		typeAttribute:               typeSchemaAttribute,
-		modeAttribute:               modeSchemaAttribute,
-		ifnameAttribute:             ifnameSchemaAttribute,
-		lucirpcglue.IdAttribute:     lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		bridgePortsAttribute:              bridgePortsSchemaAttribute,
+		bringUpEmptyBridgeAttribute:       bringUpEmptyBridgeSchemaAttribute,
+		dadTransmitsAttribute:             dadTransmitsSchemaAttribute,
+		enableIPv6Attribute:               enableIPv6SchemaAttribute,
+		macAddressAttribute:               macAddressSchemaAttribute,
+		mtuAttribute:                      mtuSchemaAttribute,
+		mtu6Attribute:                     mtu6SchemaAttribute,
+		nameAttribute:                     nameSchemaAttribute,
+		txQueueLengthAttribute:            txQueueLengthSchemaAttribute,
+		typeAttribute:                     typeSchemaAttribute,
+		modeAttribute:                     modeSchemaAttribute,
+		ifnameAttribute:                   ifnameSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
 	}
 
 	txQueueLengthSchemaAttribute = lucirpcglue.Int64SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -241,6 +242,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -256,6 +266,7 @@ type model struct {
 	BringUpEmptyBridge types.Bool   `tfsdk:"bridge_empty"`
 	DADTransmits       types.Int64  `tfsdk:"dadtransmits"`
 	EnableIPv6         types.Bool   `tfsdk:"ipv6"`
+	TargetDevice       types.String `tfsdk:"target_device"`
 	Id                 types.String `tfsdk:"id"`
 	MacAddress         types.String `tfsdk:"macaddr"`
 	MTU                types.Int64  `tfsdk:"mtu"`
@@ -273,6 +284,7 @@ func modelGetBridgePorts(m model) types.Set         { return m.BridgePorts }
 func modelGetBringUpEmptyBridge(m model) types.Bool { return m.BringUpEmptyBridge }
 func modelGetDADTransmits(m model) types.Int64      { return m.DADTransmits }
 func modelGetEnableIPv6(m model) types.Bool         { return m.EnableIPv6 }
+func modelGetTargetDevice(m model) types.String     { return m.TargetDevice }
 func modelGetId(m model) types.String               { return m.Id }
 func modelGetMacAddress(m model) types.String       { return m.MacAddress }
 func modelGetMTU(m model) types.Int64               { return m.MTU }
@@ -287,6 +299,7 @@ func modelSetBridgePorts(m *model, value types.Set)         { m.BridgePorts = va
 func modelSetBringUpEmptyBridge(m *model, value types.Bool) { m.BringUpEmptyBridge = value }
 func modelSetDADTransmits(m *model, value types.Int64)      { m.DADTransmits = value }
 func modelSetEnableIPv6(m *model, value types.Bool)         { m.EnableIPv6 = value }
+func modelSetTargetDevice(m *model, value types.String)     { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)               { m.Id = value }
 func modelSetMacAddress(m *model, value types.String)       { m.MacAddress = value }
 func modelSetMTU(m *model, value types.Int64)               { m.MTU = value }
@@ -0,0 +1,137 @@
+package device_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "device", "br_testing", map[string]any{
+		"name":  "br-testing",
+		"ports": []string{"eth0", "eth1"},
+		"type":  "bridge",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_device" "this" {
+	id = "br_testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_device.this", "id", "br_testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_device.this", "name", "br-testing"),
+			resource.TestCheckTypeSetElemAttr("data.openwrt_network_device.this", "ports.*", "eth0"),
+			resource.TestCheckTypeSetElemAttr("data.openwrt_network_device.this", "ports.*", "eth1"),
+			resource.TestCheckResourceAttr("data.openwrt_network_device.this", "type", "bridge"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_device" "br_testing" {
+	id = "br_testing"
+	name = "br-testing"
+	ports = [
+		"eth0",
+		"eth1",
+	]
+	type = "bridge"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "id", "br_testing"),
+			resource.TestCheckNoResourceAttr("openwrt_network_device.br_testing", "macaddr"),
+			resource.TestCheckNoResourceAttr("openwrt_network_device.br_testing", "mtu"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "name", "br-testing"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth0"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth1"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "type", "bridge"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_device.br_testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_device" "br_testing" {
+	id = "br_testing"
+	macaddr = "12:34:56:78:90:ab"
+	mtu = 1505
+	name = "br-testing"
+	ports = [
+		"eth0",
+		"eth1",
+		"eth2.10",
+		"eth2.20",
+	]
+	type = "bridge"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "id", "br_testing"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "macaddr", "12:34:56:78:90:ab"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "mtu", "1505"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "name", "br-testing"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth0"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth1"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth2.10"),
+			resource.TestCheckTypeSetElemAttr("openwrt_network_device.br_testing", "ports.*", "eth2.20"),
+			resource.TestCheckResourceAttr("openwrt_network_device.br_testing", "type", "bridge"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "br_testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("br_testing"),
+					".type":      lucirpc.String("device"),
+					"macaddr":    lucirpc.String("12:34:56:78:90:ab"),
+					"mtu":        lucirpc.Integer(1505),
+					"name":       lucirpc.String("br-testing"),
+					"ports":      lucirpc.ListString([]string{"eth0", "eth1", "eth2.10", "eth2.20"}),
+					"type":       lucirpc.String("bridge"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
@@ -30,9 +30,10 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		ulaPrefixAttribute:      ulaPrefixSchemaAttribute,
-		packetSteeringAttribute: packetSteeringSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		ulaPrefixAttribute:                ulaPrefixSchemaAttribute,
+		packetSteeringAttribute:           packetSteeringSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
 	}
 
 	ulaPrefixSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -64,15 +65,18 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
+	TargetDevice   types.String `tfsdk:"target_device"`
 	Id             types.String `tfsdk:"id"`
 	PacketSteering types.Bool   `tfsdk:"packet_steering"`
 	ULAPrefix      types.String `tfsdk:"ula_prefix"`
 }
 
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
 func modelGetId(m model) types.String           { return m.Id }
 func modelGetPacketSteering(m model) types.Bool { return m.PacketSteering }
 func modelGetULAPrefix(m model) types.String    { return m.ULAPrefix }
 
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)           { m.Id = value }
 func modelSetPacketSteering(m *model, value types.Bool) { m.PacketSteering = value }
 func modelSetULAPrefix(m *model, value types.String)    { m.ULAPrefix = value }
@@ -0,0 +1,107 @@
+package globals_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "globals", "globals", map[string]any{
+		"packet_steering": false,
+		"ula_prefix":      "fd12:3456:789a::/48",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_globals" "this" {
+	id = "globals"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_globals.this", "id", "globals"),
+			resource.TestCheckResourceAttr("data.openwrt_network_globals.this", "packet_steering", "false"),
+			resource.TestCheckResourceAttr("data.openwrt_network_globals.this", "ula_prefix", "fd12:3456:789a::/48"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_globals" "this" {
+	id = "globals"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_globals.this", "id", "globals"),
+			resource.TestCheckNoResourceAttr("openwrt_network_globals.this", "packet_steering"),
+			resource.TestCheckNoResourceAttr("openwrt_network_globals.this", "ula_prefix"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_globals.this",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_globals" "this" {
+	id = "globals"
+	packet_steering = false
+	ula_prefix = "fd12:3456:789a::/48"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_globals.this", "id", "globals"),
+			resource.TestCheckResourceAttr("openwrt_network_globals.this", "packet_steering", "false"),
+			resource.TestCheckResourceAttr("openwrt_network_globals.this", "ula_prefix", "fd12:3456:789a::/48"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "globals")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous":      lucirpc.Boolean(false),
+					".name":           lucirpc.String("globals"),
+					".type":           lucirpc.String("globals"),
+					"packet_steering": lucirpc.Boolean(false),
+					"ula_prefix":      lucirpc.String("fd12:3456:789a::/48"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
@@ -181,6 +181,12 @@ const (
 )
 
 var (
+	// Reference is how other sections name an interface (e.g. a zone's `network`).
+	Reference = lucirpcglue.SectionReference{
+		Config: uciConfig,
+		Type:   uciType,
+	}
+
 	bringUpOnBootSchemaAttribute = lucirpcglue.BoolSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       bringUpOnBootAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionBool(modelSetBringUpOnBoot, bringUpOnBootAttribute, bringUpOnBootUCIOption),
@@ -542,44 +548,45 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		bringUpOnBootAttribute:        bringUpOnBootSchemaAttribute,
-		deviceAttribute:               deviceSchemaAttribute,
-		disabledAttribute:             disabledSchemaAttribute,
-		dnsAttribute:                  dnsSchemaAttribute,
-		gatewayAttribute:              gatewaySchemaAttribute,
-		ip6AssignAttribute:            ip6AssignSchemaAttribute,
-		ipAddressAttribute:            ipAddressSchemaAttribute,
-		macAddressAttribute:           macAddressSchemaAttribute,
-		mtuAttribute:                  mtuSchemaAttribute,
-		metricAttribute:               metricSchemaAttribute,
-		netmaskAttribute:              netmaskSchemaAttribute,
-		peerDNSAttribute:              peerDNSSchemaAttribute,
-		protocolAttribute:             protocolSchemaAttribute,
-		requestingAddressAttribute:    requestingAddressSchemaAttribute,
-		requestingPrefixAttribute:     requestingPrefixSchemaAttribute,
-		ipv4AddressesAttribute:        ipv4AddressesSchemaAttribute,
-		upAttribute:                   upSchemaAttribute,
-		pendingAttribute:              pendingSchemaAttribute,
-		availableAttribute:            availableSchemaAttribute,
-		autostartAttribute:            autostartSchemaAttribute,
-		dynamicAttribute:              dynamicSchemaAttribute,
-		uptimeAttribute:               uptimeSchemaAttribute,
-		l3DeviceAttribute:             l3DeviceSchemaAttribute,
-		ifnameAttribute:               ifnameSchemaAttribute,
-		updatedAttribute:              updatedSchemaAttribute,
-		dnsMetricAttribute:            dnsMetricSchemaAttribute,
-		dnsServerAttribute:            dnsServerSchemaAttribute,
-		dnsSearchAttribute:            dnsSearchSchemaAttribute,
-		ipv6PrefixAttribute:           ipv6PrefixSchemaAttribute,
-		ipv6PrefixAssignmentAttribute: ipv6PrefixAssignmentSchemaAttribute,
-		routeAttribute:                routeSchemaAttribute,
-		errorsAttribute:               errorsSchemaAttribute,
-		rxBytesAttribute:              rxBytesSchemaAttribute,
-		txBytesAttribute:              txBytesSchemaAttribute,
-		rxPacketsAttribute:            rxPacketsSchemaAttribute,
-		txPacketsAttribute:            txPacketsSchemaAttribute,
-		interfaceAttribute:            interfaceSchemaAttribute,
-		lucirpcglue.IdAttribute:       lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		bringUpOnBootAttribute:            bringUpOnBootSchemaAttribute,
+		deviceAttribute:                   deviceSchemaAttribute,
+		disabledAttribute:                 disabledSchemaAttribute,
+		dnsAttribute:                      dnsSchemaAttribute,
+		gatewayAttribute:                  gatewaySchemaAttribute,
+		ip6AssignAttribute:                ip6AssignSchemaAttribute,
+		ipAddressAttribute:                ipAddressSchemaAttribute,
+		macAddressAttribute:               macAddressSchemaAttribute,
+		mtuAttribute:                      mtuSchemaAttribute,
+		metricAttribute:                   metricSchemaAttribute,
+		netmaskAttribute:                  netmaskSchemaAttribute,
+		peerDNSAttribute:                  peerDNSSchemaAttribute,
+		protocolAttribute:                 protocolSchemaAttribute,
+		requestingAddressAttribute:        requestingAddressSchemaAttribute,
+		requestingPrefixAttribute:         requestingPrefixSchemaAttribute,
+		ipv4AddressesAttribute:            ipv4AddressesSchemaAttribute,
+		upAttribute:                       upSchemaAttribute,
+		pendingAttribute:                  pendingSchemaAttribute,
+		availableAttribute:                availableSchemaAttribute,
+		autostartAttribute:                autostartSchemaAttribute,
+		dynamicAttribute:                  dynamicSchemaAttribute,
+		uptimeAttribute:                   uptimeSchemaAttribute,
+		l3DeviceAttribute:                 l3DeviceSchemaAttribute,
+		ifnameAttribute:                   ifnameSchemaAttribute,
+		updatedAttribute:                  updatedSchemaAttribute,
+		dnsMetricAttribute:                dnsMetricSchemaAttribute,
+		dnsServerAttribute:                dnsServerSchemaAttribute,
+		dnsSearchAttribute:                dnsSearchSchemaAttribute,
+		ipv6PrefixAttribute:               ipv6PrefixSchemaAttribute,
+		ipv6PrefixAssignmentAttribute:     ipv6PrefixAssignmentSchemaAttribute,
+		routeAttribute:                    routeSchemaAttribute,
+		errorsAttribute:                   errorsSchemaAttribute,
+		rxBytesAttribute:                  rxBytesSchemaAttribute,
+		txBytesAttribute:                  txBytesSchemaAttribute,
+		rxPacketsAttribute:                rxPacketsSchemaAttribute,
+		txPacketsAttribute:                txPacketsSchemaAttribute,
+		interfaceAttribute:                interfaceSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
 	}
 )
 
@@ -593,6 +600,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -609,6 +625,7 @@ type model struct {
 	Disabled             types.Bool   `tfsdk:"disabled"`
 	DNS                  types.List   `tfsdk:"dns"`
 	Gateway              types.String `tfsdk:"gateway"`
+	TargetDevice         types.String `tfsdk:"target_device"`
 	Id                   types.String `tfsdk:"id"`
 	IP6Assign            types.Int64  `tfsdk:"ip6assign"`
 	IPAddress            types.String `tfsdk:"ipaddr"`
@@ -650,6 +667,7 @@ func modelGetDevice(m model) types.String             { return m.Device }
 func modelGetDisabled(m model) types.Bool             { return m.Disabled }
 func modelGetDNS(m model) types.List                  { return m.DNS }
 func modelGetGateway(m model) types.String            { return m.Gateway }
+func modelGetTargetDevice(m model) types.String       { return m.TargetDevice }
 func modelGetId(m model) types.String                 { return m.Id }
 func modelGetIP6Assign(m model) types.Int64           { return m.IP6Assign }
 func modelGetIPAddress(m model) types.String          { return m.IPAddress }
@@ -689,6 +707,7 @@ func modelSetDevice(m *model, value types.String)             { m.Device = value
 func modelSetDisabled(m *model, value types.Bool)             { m.Disabled = value }
 func modelSetDNS(m *model, value types.List)                  { m.DNS = value }
 func modelSetGateway(m *model, value types.String)            { m.Gateway = value }
+func modelSetTargetDevice(m *model, value types.String)       { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)                 { m.Id = value }
 func modelSetIP6Assign(m *model, value types.Int64)           { m.IP6Assign = value }
 func modelSetIPAddress(m *model, value types.String)          { m.IPAddress = value }
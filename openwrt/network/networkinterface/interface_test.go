@@ -0,0 +1,210 @@
+package networkinterface_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "interface", "testing", map[string]any{
+		"device":  "br-testing",
+		"ipaddr":  "192.168.3.1",
+		"netmask": "255.255.255.0",
+		"proto":   "static",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_interface" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_interface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_interface.testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_interface.testing", "ipaddr", "192.168.3.1"),
+			resource.TestCheckResourceAttr("data.openwrt_network_interface.testing", "netmask", "255.255.255.0"),
+			resource.TestCheckResourceAttr("data.openwrt_network_interface.testing", "proto", "static"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_interface" "testing" {
+	device = "br-testing"
+	id = "testing"
+	ipaddr = "192.168.3.1"
+	netmask = "255.255.255.0"
+	proto = "static"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "ipaddr", "192.168.3.1"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "netmask", "255.255.255.0"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "proto", "static"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_interface.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_interface" "testing" {
+	device = "br-testing"
+	dns = [
+		"9.9.9.9",
+		"1.1.1.1",
+	]
+	id = "testing"
+	ipaddr = "192.168.3.1"
+	macaddr = "12:34:56:78:90:ab"
+	mtu = 1505
+	netmask = "255.255.255.0"
+	proto = "static"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.1", "1.1.1.1"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.0", "9.9.9.9"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "ipaddr", "192.168.3.1"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "macaddr", "12:34:56:78:90:ab"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "mtu", "1505"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "netmask", "255.255.255.0"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "proto", "static"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("interface"),
+					"device":     lucirpc.String("br-testing"),
+					"dns":        lucirpc.ListString([]string{"9.9.9.9", "1.1.1.1"}),
+					"ipaddr":     lucirpc.String("192.168.3.1"),
+					"macaddr":    lucirpc.String("12:34:56:78:90:ab"),
+					"mtu":        lucirpc.Integer(1505),
+					"netmask":    lucirpc.String("255.255.255.0"),
+					"proto":      lucirpc.String("static"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
+
+func TestResourcePeerDNSWithDHCP(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_interface" "testing" {
+	device = "br-testing"
+	dns = [
+		"9.9.9.9",
+		"1.1.1.1",
+	]
+	id = "testing"
+	peerdns = false
+	proto = "dhcp"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.0", "9.9.9.9"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.1", "1.1.1.1"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "peerdns", "false"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "proto", "dhcp"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
+
+func TestResourcePeerDNSWithDHCPV6(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_interface" "testing" {
+	device = "br-testing"
+	dns = [
+		"9.9.9.9",
+		"1.1.1.1",
+	]
+	id = "testing"
+	peerdns = false
+	proto = "dhcpv6"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "device", "br-testing"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.0", "9.9.9.9"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "dns.1", "1.1.1.1"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "peerdns", "false"),
+			resource.TestCheckResourceAttr("openwrt_network_interface.testing", "proto", "dhcpv6"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
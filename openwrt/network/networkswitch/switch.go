@@ -121,14 +121,15 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		enableMirrorReceivedAttribute:    enableMirrorReceivedSchemaAttribute,
-		enableMirrorTransmittedAttribute: enableMirrorTransmittedSchemaAttribute,
-		enableVLANAttribute:              enableVLANSchemaAttribute,
-		lucirpcglue.IdAttribute:          lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		mirrorMonitorPortAttribute:       mirrorMonitorPortSchemaAttribute,
-		mirrorSourcePortAttribute:        mirrorSourcePortSchemaAttribute,
-		nameAttribute:                    nameSchemaAttribute,
-		resetAttribute:                   resetSchemaAttribute,
+		enableMirrorReceivedAttribute:     enableMirrorReceivedSchemaAttribute,
+		enableMirrorTransmittedAttribute:  enableMirrorTransmittedSchemaAttribute,
+		enableVLANAttribute:               enableVLANSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		mirrorMonitorPortAttribute:        mirrorMonitorPortSchemaAttribute,
+		mirrorSourcePortAttribute:         mirrorSourcePortSchemaAttribute,
+		nameAttribute:                     nameSchemaAttribute,
+		resetAttribute:                    resetSchemaAttribute,
 	}
 )
 
@@ -142,6 +143,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -156,6 +166,7 @@ type model struct {
 	EnableMirrorReceived    types.Bool   `tfsdk:"enable_mirror_rx"`
 	EnableMirrorTransmitted types.Bool   `tfsdk:"enable_mirror_tx"`
 	EnableVLAN              types.Bool   `tfsdk:"enable_vlan"`
+	TargetDevice            types.String `tfsdk:"target_device"`
 	Id                      types.String `tfsdk:"id"`
 	MirrorMonitorPort       types.Int64  `tfsdk:"mirror_monitor_port"`
 	MirrorSourcePort        types.Int64  `tfsdk:"mirror_source_port"`
@@ -166,6 +177,7 @@ type model struct {
 func modelGetEnableMirrorReceived(m model) types.Bool    { return m.EnableMirrorReceived }
 func modelGetEnableMirrorTransmitted(m model) types.Bool { return m.EnableMirrorTransmitted }
 func modelGetEnableVLAN(m model) types.Bool              { return m.EnableVLAN }
+func modelGetTargetDevice(m model) types.String          { return m.TargetDevice }
 func modelGetId(m model) types.String                    { return m.Id }
 func modelGetMirrorMonitorPort(m model) types.Int64      { return m.MirrorMonitorPort }
 func modelGetMirrorSourcePort(m model) types.Int64       { return m.MirrorSourcePort }
@@ -175,6 +187,7 @@ func modelGetReset(m model) types.Bool                   { return m.Reset }
 func modelSetEnableMirrorReceived(m *model, value types.Bool)    { m.EnableMirrorReceived = value }
 func modelSetEnableMirrorTransmitted(m *model, value types.Bool) { m.EnableMirrorTransmitted = value }
 func modelSetEnableVLAN(m *model, value types.Bool)              { m.EnableVLAN = value }
+func modelSetTargetDevice(m *model, value types.String)          { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)                    { m.Id = value }
 func modelSetMirrorMonitorPort(m *model, value types.Int64)      { m.MirrorMonitorPort = value }
 func modelSetMirrorSourcePort(m *model, value types.Int64)       { m.MirrorSourcePort = value }
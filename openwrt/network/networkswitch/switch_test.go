@@ -0,0 +1,232 @@
+package networkswitch_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "switch", "testing", map[string]any{
+		"name":        "switch0",
+		"enable_vlan": true,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_switch" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch.testing", "enable_vlan", "true"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch.testing", "name", "switch0"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	id = "testing"
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+			resource.TestCheckNoResourceAttr("openwrt_network_switch.testing", "enable_vlan"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_switch.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	enable_vlan = true
+	id = "testing"
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "enable_vlan", "true"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous":  lucirpc.Boolean(false),
+					".name":       lucirpc.String("testing"),
+					".type":       lucirpc.String("switch"),
+					"enable_vlan": lucirpc.Boolean(true),
+					"name":        lucirpc.String("switch0"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
+
+func TestResourceMirrorMonitorPortWithEnableMirrorReceived(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	enable_mirror_rx = true
+	id = "testing"
+	mirror_monitor_port = 3
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "enable_mirror_rx", "true"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "mirror_monitor_port", "3"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
+
+func TestResourceMirrorMonitorPortWithEnableMirrorTransmitted(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	enable_mirror_tx = true
+	id = "testing"
+	mirror_monitor_port = 3
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "enable_mirror_tx", "true"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "mirror_monitor_port", "3"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
+
+func TestResourceMirrorSourcePortWithEnableMirrorReceived(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	enable_mirror_rx = true
+	id = "testing"
+	mirror_source_port = 3
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "enable_mirror_rx", "true"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "mirror_source_port", "3"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
+
+func TestResourceMirrorSourcePortWithEnableMirrorTransmitted(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	step := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch" "testing" {
+	enable_mirror_tx = true
+	id = "testing"
+	mirror_source_port = 3
+	name = "switch0"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "enable_mirror_tx", "true"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "mirror_source_port", "3"),
+			resource.TestCheckResourceAttr("openwrt_network_switch.testing", "name", "switch0"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		step,
+	)
+}
@@ -60,12 +60,13 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		descriptionAttribute:    descriptionSchemaAttribute,
-		deviceAttribute:         deviceSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		portsAttribute:          portsSchemaAttribute,
-		vIdAttribute:            vIdSchemaAttribute,
-		vLanAttribute:           vLanSchemaAttribute,
+		descriptionAttribute:              descriptionSchemaAttribute,
+		deviceAttribute:                   deviceSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		portsAttribute:                    portsSchemaAttribute,
+		vIdAttribute:                      vIdSchemaAttribute,
+		vLanAttribute:                     vLanSchemaAttribute,
 	}
 
 	vIdSchemaAttribute = lucirpcglue.Int64SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -99,6 +100,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -110,24 +120,27 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Description types.String `tfsdk:"description"`
-	Device      types.String `tfsdk:"device"`
-	Id          types.String `tfsdk:"id"`
-	Ports       types.String `tfsdk:"ports"`
-	VId         types.Int64  `tfsdk:"vid"`
-	VLan        types.Int64  `tfsdk:"vlan"`
+	Description  types.String `tfsdk:"description"`
+	Device       types.String `tfsdk:"device"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Ports        types.String `tfsdk:"ports"`
+	VId          types.Int64  `tfsdk:"vid"`
+	VLan         types.Int64  `tfsdk:"vlan"`
 }
 
-func modelGetDescription(m model) types.String { return m.Description }
-func modelGetDevice(m model) types.String      { return m.Device }
-func modelGetId(m model) types.String          { return m.Id }
-func modelGetPorts(m model) types.String       { return m.Ports }
-func modelGetVId(m model) types.Int64          { return m.VId }
-func modelGetVLan(m model) types.Int64         { return m.VLan }
-
-func modelSetDescription(m *model, value types.String) { m.Description = value }
-func modelSetDevice(m *model, value types.String)      { m.Device = value }
-func modelSetId(m *model, value types.String)          { m.Id = value }
-func modelSetPorts(m *model, value types.String)       { m.Ports = value }
-func modelSetVId(m *model, value types.Int64)          { m.VId = value }
-func modelSetVLan(m *model, value types.Int64)         { m.VLan = value }
+func modelGetDescription(m model) types.String  { return m.Description }
+func modelGetDevice(m model) types.String       { return m.Device }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetPorts(m model) types.String        { return m.Ports }
+func modelGetVId(m model) types.Int64           { return m.VId }
+func modelGetVLan(m model) types.Int64          { return m.VLan }
+
+func modelSetDescription(m *model, value types.String)  { m.Description = value }
+func modelSetDevice(m *model, value types.String)       { m.Device = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetPorts(m *model, value types.String)        { m.Ports = value }
+func modelSetVId(m *model, value types.Int64)           { m.VId = value }
+func modelSetVLan(m *model, value types.Int64)          { m.VLan = value }
@@ -0,0 +1,121 @@
+package switchvlan_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("network", "switch_vlan", "testing", map[string]any{
+		"device": "switch0",
+		"ports":  "0t 1t",
+		"vid":    10,
+		"vlan":   2,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_network_switch_vlan" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_network_switch_vlan.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch_vlan.testing", "device", "switch0"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch_vlan.testing", "ports", "0t 1t"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch_vlan.testing", "vid", "10"),
+			resource.TestCheckResourceAttr("data.openwrt_network_switch_vlan.testing", "vlan", "2"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch_vlan" "testing" {
+	device = "switch0"
+	id = "testing"
+	ports = "0t"
+	vlan = 2
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "device", "switch0"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "ports", "0t"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "vlan", "2"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_network_switch_vlan.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_network_switch_vlan" "testing" {
+	device = "switch0"
+	id = "testing"
+	ports = "0t 1t"
+	vid = 10
+	vlan = 2
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "device", "switch0"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "ports", "0t 1t"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "vid", "10"),
+			resource.TestCheckResourceAttr("openwrt_network_switch_vlan.testing", "vlan", "2"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("network", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("switch_vlan"),
+					"device":     lucirpc.String("switch0"),
+					"ports":      lucirpc.String("0t 1t"),
+					"vid":        lucirpc.Integer(10),
+					"vlan":       lucirpc.Integer(2),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
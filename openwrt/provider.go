@@ -2,12 +2,20 @@ package openwrt
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -32,32 +40,174 @@ import (
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/network/networkinterface"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/network/networkswitch"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/network/switchvlan"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/service/reload"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/system/system"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/uci/config"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/uci/section"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/wireless/wifidevice"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/wireless/wifiiface"
 )
 
 const (
+	caCertificateAttribute           = "ca_certificate"
+	caCertificateDefaultValue        = ""
+	caCertificateEnvironmentVariable = "OPENWRT_CA_CERTIFICATE"
+	caCertificateHumanReadableName   = "CA certificate"
+
+	caCertificateFileAttribute           = "ca_certificate_file"
+	caCertificateFileDefaultValue        = ""
+	caCertificateFileEnvironmentVariable = "OPENWRT_CA_CERTIFICATE_FILE"
+	caCertificateFileHumanReadableName   = "CA certificate file"
+
+	certificateFingerprintAttribute           = "certificate_fingerprint"
+	certificateFingerprintDefaultValue        = ""
+	certificateFingerprintEnvironmentVariable = "OPENWRT_CERTIFICATE_FINGERPRINT"
+	certificateFingerprintHumanReadableName   = "certificate fingerprint"
+
+	commitBatchWindowAttribute           = "commit_batch_window"
+	commitBatchWindowDefaultValue        = 0
+	commitBatchWindowEnvironmentVariable = "OPENWRT_COMMIT_BATCH_WINDOW"
+	commitBatchWindowHumanReadableName   = "commit batch window"
+
+	clientCertificateAttribute           = "client_certificate"
+	clientCertificateDefaultValue        = ""
+	clientCertificateEnvironmentVariable = "OPENWRT_CLIENT_CERTIFICATE"
+	clientCertificateHumanReadableName   = "client certificate"
+
+	clientCertificateFileAttribute           = "client_certificate_file"
+	clientCertificateFileDefaultValue        = ""
+	clientCertificateFileEnvironmentVariable = "OPENWRT_CLIENT_CERTIFICATE_FILE"
+	clientCertificateFileHumanReadableName   = "client certificate file"
+
+	clientKeyAttribute           = "client_key"
+	clientKeyDefaultValue        = ""
+	clientKeyEnvironmentVariable = "OPENWRT_CLIENT_KEY"
+	clientKeyHumanReadableName   = "client key"
+
+	clientKeyFileAttribute           = "client_key_file"
+	clientKeyFileDefaultValue        = ""
+	clientKeyFileEnvironmentVariable = "OPENWRT_CLIENT_KEY_FILE"
+	clientKeyFileHumanReadableName   = "client key file"
+
+	devicesAttribute         = "devices"
+	devicesHumanReadableName = "devices"
+
 	hostnameAttribute           = "hostname"
 	hostnameDefaultValue        = "192.168.1.1"
 	hostnameEnvironmentVariable = "OPENWRT_HOSTNAME"
 	hostnameHumanReadableName   = "hostname"
 
+	insecureSkipVerifyAttribute           = "insecure_skip_verify"
+	insecureSkipVerifyDefaultValue        = false
+	insecureSkipVerifyEnvironmentVariable = "OPENWRT_INSECURE_SKIP_VERIFY"
+	insecureSkipVerifyHumanReadableName   = "insecure skip verify"
+
+	maxRetriesAttribute           = "max_retries"
+	maxRetriesDefaultValue        = 0
+	maxRetriesEnvironmentVariable = "OPENWRT_MAX_RETRIES"
+	maxRetriesHumanReadableName   = "max retries"
+
 	passwordAttribute           = "password"
 	passwordDefaultValue        = ""
 	passwordEnvironmentVariable = "OPENWRT_PASSWORD"
 	passwordHumanReadableName   = "password"
 
+	passwordCommandAttribute           = "password_command"
+	passwordCommandEnvironmentVariable = "OPENWRT_PASSWORD_COMMAND"
+	passwordCommandHumanReadableName   = "password command"
+
+	passwordFileAttribute           = "password_file"
+	passwordFileDefaultValue        = ""
+	passwordFileEnvironmentVariable = "OPENWRT_PASSWORD_FILE"
+	passwordFileHumanReadableName   = "password file"
+
+	pendingChangesAttribute           = "pending_changes"
+	pendingChangesDefaultValue        = pendingChangesWarn
+	pendingChangesEnvironmentVariable = "OPENWRT_PENDING_CHANGES"
+	pendingChangesFail                = "fail"
+	pendingChangesHumanReadableName   = "pending changes policy"
+	pendingChangesRevert              = "revert"
+	pendingChangesWarn                = "warn"
+
 	portAttribute           = "port"
 	portDefaultValue        = 80
 	portEnvironmentVariable = "OPENWRT_PORT"
 	portHumanReadableName   = "port"
+	portSSHDefaultValue     = 22
+
+	requestTimeoutAttribute           = "request_timeout"
+	requestTimeoutDefaultValue        = 0
+	requestTimeoutEnvironmentVariable = "OPENWRT_REQUEST_TIMEOUT"
+	requestTimeoutHumanReadableName   = "request timeout"
+
+	retryBackoffAttribute           = "retry_backoff"
+	retryBackoffDefaultValue        = 1000
+	retryBackoffEnvironmentVariable = "OPENWRT_RETRY_BACKOFF"
+	retryBackoffHumanReadableName   = "retry backoff"
+
+	retryMaxBackoffAttribute           = "retry_max_backoff"
+	retryMaxBackoffDefaultValue        = 30000
+	retryMaxBackoffEnvironmentVariable = "OPENWRT_RETRY_MAX_BACKOFF"
+	retryMaxBackoffHumanReadableName   = "retry max backoff"
+
+	rollbackTimeoutAttribute           = "rollback_timeout"
+	rollbackTimeoutDefaultValue        = 0
+	rollbackTimeoutEnvironmentVariable = "OPENWRT_ROLLBACK_TIMEOUT"
+	rollbackTimeoutHumanReadableName   = "rollback timeout"
 
 	schemeAttribute           = "scheme"
 	schemeDefaultValue        = "http"
 	schemeEnvironmentVariable = "OPENWRT_SCHEME"
 	schemeHumanReadableName   = "URI scheme"
 
+	sectionIdAttributeAttribute           = "section_id_attribute"
+	sectionIdAttributeDefaultValue        = "name"
+	sectionIdAttributeEnvironmentVariable = "OPENWRT_SECTION_ID_ATTRIBUTE"
+	sectionIdAttributeHumanReadableName   = "section id attribute"
+
+	sectionIdStrategyAttribute           = "section_id_strategy"
+	sectionIdStrategyDefaultValue        = lucirpcglue.SectionIdStrategyRandom
+	sectionIdStrategyEnvironmentVariable = "OPENWRT_SECTION_ID_STRATEGY"
+	sectionIdStrategyHumanReadableName   = "section id strategy"
+
+	sessionTokenAttribute           = "session_token"
+	sessionTokenDefaultValue        = ""
+	sessionTokenEnvironmentVariable = "OPENWRT_SESSION_TOKEN"
+	sessionTokenHumanReadableName   = "session token"
+
+	sshAgentAttribute           = "ssh_agent"
+	sshAgentDefaultValue        = false
+	sshAgentEnvironmentVariable = "OPENWRT_SSH_AGENT"
+	sshAgentHumanReadableName   = "SSH agent"
+
+	sshInsecureIgnoreHostKeyAttribute           = "ssh_insecure_ignore_host_key"
+	sshInsecureIgnoreHostKeyDefaultValue        = false
+	sshInsecureIgnoreHostKeyEnvironmentVariable = "OPENWRT_SSH_INSECURE_IGNORE_HOST_KEY"
+	sshInsecureIgnoreHostKeyHumanReadableName   = "SSH insecure ignore host key"
+
+	sshKnownHostsFileAttribute           = "ssh_known_hosts_file"
+	sshKnownHostsFileDefaultValue        = ""
+	sshKnownHostsFileEnvironmentVariable = "OPENWRT_SSH_KNOWN_HOSTS_FILE"
+	sshKnownHostsFileHumanReadableName   = "SSH known_hosts file"
+
+	sshPrivateKeyAttribute           = "ssh_private_key"
+	sshPrivateKeyDefaultValue        = ""
+	sshPrivateKeyEnvironmentVariable = "OPENWRT_SSH_PRIVATE_KEY"
+	sshPrivateKeyHumanReadableName   = "SSH private key"
+
+	sshPrivateKeyFileAttribute           = "ssh_private_key_file"
+	sshPrivateKeyFileDefaultValue        = ""
+	sshPrivateKeyFileEnvironmentVariable = "OPENWRT_SSH_PRIVATE_KEY_FILE"
+	sshPrivateKeyFileHumanReadableName   = "SSH private key file"
+
+	transportAttribute           = "transport"
+	transportDefaultValue        = transportLuCIRPC
+	transportEnvironmentVariable = "OPENWRT_TRANSPORT"
+	transportHumanReadableName   = "transport"
+	transportLuCIRPC             = "luci-rpc"
+	transportSSH                 = "ssh"
+	transportUbus                = "ubus"
+
 	usernameAttribute           = "username"
 	usernameDefaultValue        = "root"
 	usernameEnvironmentVariable = "OPENWRT_USERNAME"
@@ -68,6 +218,14 @@ const (
 
 var (
 	_ provider.Provider = &openWrtProvider{}
+
+	deviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	pendingChangesPolicies = map[string]lucirpc.PendingChangesPolicy{
+		pendingChangesFail:   lucirpc.PendingChangesFail,
+		pendingChangesRevert: lucirpc.PendingChangesRevert,
+		pendingChangesWarn:   lucirpc.PendingChangesWarn,
+	}
 )
 
 func New(
@@ -103,23 +261,120 @@ func (p *openWrtProvider) Configure(
 		return
 	}
 
+	caCertificate := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.CACertificate,
+		caCertificateEnvironmentVariable,
+		caCertificateDefaultValue,
+	)
+	caCertificateFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.CACertificateFile,
+		caCertificateFileEnvironmentVariable,
+		caCertificateFileDefaultValue,
+	)
+	certificateFingerprint := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.CertificateFingerprint,
+		certificateFingerprintEnvironmentVariable,
+		certificateFingerprintDefaultValue,
+	)
+	clientCertificate := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.ClientCertificate,
+		clientCertificateEnvironmentVariable,
+		clientCertificateDefaultValue,
+	)
+	clientCertificateFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.ClientCertificateFile,
+		clientCertificateFileEnvironmentVariable,
+		clientCertificateFileDefaultValue,
+	)
+	clientKey := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.ClientKey,
+		clientKeyEnvironmentVariable,
+		clientKeyDefaultValue,
+	)
+	clientKeyFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.ClientKeyFile,
+		clientKeyFileEnvironmentVariable,
+		clientKeyFileDefaultValue,
+	)
+	commitBatchWindow := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.CommitBatchWindow,
+		commitBatchWindowEnvironmentVariable,
+		commitBatchWindowDefaultValue,
+	)
 	hostname := defaultStringAttributeValue(
 		p.lookupEnv,
 		model.Hostname,
 		hostnameEnvironmentVariable,
 		hostnameDefaultValue,
 	)
+	insecureSkipVerify := defaultBoolAttributeValue(
+		p.lookupEnv,
+		model.InsecureSkipVerify,
+		insecureSkipVerifyEnvironmentVariable,
+		insecureSkipVerifyDefaultValue,
+	)
+	maxRetries := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.MaxRetries,
+		maxRetriesEnvironmentVariable,
+		maxRetriesDefaultValue,
+	)
 	password := defaultStringAttributeValue(
 		p.lookupEnv,
 		model.Password,
 		passwordEnvironmentVariable,
 		passwordDefaultValue,
 	)
-	port := defaultInt64AttributeValue(
+	passwordCommand := defaultStringListAttributeValue(
+		ctx,
 		p.lookupEnv,
-		model.Port,
-		portEnvironmentVariable,
-		portDefaultValue,
+		model.PasswordCommand,
+		passwordCommandEnvironmentVariable,
+		res,
+	)
+	passwordFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.PasswordFile,
+		passwordFileEnvironmentVariable,
+		passwordFileDefaultValue,
+	)
+	pendingChanges := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.PendingChanges,
+		pendingChangesEnvironmentVariable,
+		pendingChangesDefaultValue,
+	)
+	requestTimeout := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.RequestTimeout,
+		requestTimeoutEnvironmentVariable,
+		requestTimeoutDefaultValue,
+	)
+	retryBackoff := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.RetryBackoff,
+		retryBackoffEnvironmentVariable,
+		retryBackoffDefaultValue,
+	)
+	retryMaxBackoff := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.RetryMaxBackoff,
+		retryMaxBackoffEnvironmentVariable,
+		retryMaxBackoffDefaultValue,
+	)
+	rollbackTimeout := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.RollbackTimeout,
+		rollbackTimeoutEnvironmentVariable,
+		rollbackTimeoutDefaultValue,
 	)
 	scheme := defaultStringAttributeValue(
 		p.lookupEnv,
@@ -127,6 +382,60 @@ func (p *openWrtProvider) Configure(
 		schemeEnvironmentVariable,
 		schemeDefaultValue,
 	)
+	sectionIdAttribute := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SectionIdAttribute,
+		sectionIdAttributeEnvironmentVariable,
+		sectionIdAttributeDefaultValue,
+	)
+	sectionIdStrategy := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SectionIdStrategy,
+		sectionIdStrategyEnvironmentVariable,
+		sectionIdStrategyDefaultValue,
+	)
+	sessionToken := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SessionToken,
+		sessionTokenEnvironmentVariable,
+		sessionTokenDefaultValue,
+	)
+	sshAgent := defaultBoolAttributeValue(
+		p.lookupEnv,
+		model.SSHAgent,
+		sshAgentEnvironmentVariable,
+		sshAgentDefaultValue,
+	)
+	sshInsecureIgnoreHostKey := defaultBoolAttributeValue(
+		p.lookupEnv,
+		model.SSHInsecureIgnoreHostKey,
+		sshInsecureIgnoreHostKeyEnvironmentVariable,
+		sshInsecureIgnoreHostKeyDefaultValue,
+	)
+	sshKnownHostsFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SSHKnownHostsFile,
+		sshKnownHostsFileEnvironmentVariable,
+		sshKnownHostsFileDefaultValue,
+	)
+	sshPrivateKey := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SSHPrivateKey,
+		sshPrivateKeyEnvironmentVariable,
+		sshPrivateKeyDefaultValue,
+	)
+	sshPrivateKeyFile := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.SSHPrivateKeyFile,
+		sshPrivateKeyFileEnvironmentVariable,
+		sshPrivateKeyFileDefaultValue,
+	)
+	transport := defaultStringAttributeValue(
+		p.lookupEnv,
+		model.Transport,
+		transportEnvironmentVariable,
+		transportDefaultValue,
+	)
 	username := defaultStringAttributeValue(
 		p.lookupEnv,
 		model.Username,
@@ -134,26 +443,210 @@ func (p *openWrtProvider) Configure(
 		usernameDefaultValue,
 	)
 
+	port := defaultInt64AttributeValue(
+		p.lookupEnv,
+		model.Port,
+		portEnvironmentVariable,
+		defaultPort(transport),
+	)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = setField(ctx, caCertificateFileAttribute, caCertificateFile)
+	ctx = setField(ctx, certificateFingerprintAttribute, certificateFingerprint)
+	ctx = setField(ctx, clientCertificateFileAttribute, clientCertificateFile)
+	ctx = setField(ctx, clientKeyFileAttribute, clientKeyFile)
+	ctx = setField(ctx, commitBatchWindowAttribute, commitBatchWindow)
 	ctx = setField(ctx, hostnameAttribute, hostname)
+	ctx = setField(ctx, insecureSkipVerifyAttribute, insecureSkipVerify)
+	ctx = setField(ctx, maxRetriesAttribute, maxRetries)
 	ctx = setField(ctx, passwordAttribute, password)
+	ctx = setField(ctx, passwordCommandAttribute, passwordCommand)
+	ctx = setField(ctx, passwordFileAttribute, passwordFile)
+	ctx = setField(ctx, pendingChangesAttribute, pendingChanges)
 	ctx = setField(ctx, portAttribute, port)
+	ctx = setField(ctx, requestTimeoutAttribute, requestTimeout)
+	ctx = setField(ctx, retryBackoffAttribute, retryBackoff)
+	ctx = setField(ctx, retryMaxBackoffAttribute, retryMaxBackoff)
+	ctx = setField(ctx, rollbackTimeoutAttribute, rollbackTimeout)
 	ctx = setField(ctx, schemeAttribute, scheme)
+	ctx = setField(ctx, sectionIdAttributeAttribute, sectionIdAttribute)
+	ctx = setField(ctx, sectionIdStrategyAttribute, sectionIdStrategy)
+	ctx = setField(ctx, sshAgentAttribute, sshAgent)
+	ctx = setField(ctx, sshInsecureIgnoreHostKeyAttribute, sshInsecureIgnoreHostKey)
+	ctx = setField(ctx, sshKnownHostsFileAttribute, sshKnownHostsFile)
+	ctx = setField(ctx, sshPrivateKeyFileAttribute, sshPrivateKeyFile)
+	ctx = setField(ctx, transportAttribute, transport)
 	ctx = setField(ctx, usernameAttribute, username)
 
-	client := newOpenWrtClient(
+	if rollbackTimeout < 0 {
+		res.Diagnostics.AddAttributeError(
+			path.Root(rollbackTimeoutAttribute),
+			"Invalid rollback timeout",
+			fmt.Sprintf(
+				"The device counts the %s in whole seconds, so it must be at least 1 second. Got %d. Either set the %s to at least 1, or unset it to not roll back changes.",
+				rollbackTimeoutHumanReadableName,
+				rollbackTimeout,
+				rollbackTimeoutHumanReadableName,
+			),
+		)
+		return
+	}
+
+	if rollbackTimeout > 0 && transport == transportSSH {
+		res.Diagnostics.AddAttributeError(
+			path.Root(rollbackTimeoutAttribute),
+			"Rollback requires an HTTP transport",
+			fmt.Sprintf(
+				"The %q transport cannot apply changes with a rollback. Either set the %s to %q or %q, or unset the %s.",
+				transportSSH,
+				transportHumanReadableName,
+				transportLuCIRPC,
+				transportUbus,
+				rollbackTimeoutHumanReadableName,
+			),
+		)
+		return
+	}
+
+	pendingChangesPolicy, ok := pendingChangesPolicies[pendingChanges]
+	if !ok {
+		res.Diagnostics.AddAttributeError(
+			path.Root(pendingChangesAttribute),
+			fmt.Sprintf("Unknown %s", pendingChangesHumanReadableName),
+			fmt.Sprintf(
+				"The %s must be one of %q, %q, or %q, but got %q. Check the %s environment variable.",
+				pendingChangesHumanReadableName,
+				pendingChangesFail,
+				pendingChangesRevert,
+				pendingChangesWarn,
+				pendingChanges,
+				pendingChangesEnvironmentVariable,
+			),
+		)
+		return
+	}
+
+	if !lucirpcglue.IsSectionIdStrategy(sectionIdStrategy) {
+		res.Diagnostics.AddAttributeError(
+			path.Root(sectionIdStrategyAttribute),
+			fmt.Sprintf("Unknown %s", sectionIdStrategyHumanReadableName),
+			fmt.Sprintf(
+				"The %s must be one of %q, %q, or %q, but got %q. Check the %s environment variable.",
+				sectionIdStrategyHumanReadableName,
+				lucirpcglue.SectionIdStrategyAnonymous,
+				lucirpcglue.SectionIdStrategyAttribute,
+				lucirpcglue.SectionIdStrategyRandom,
+				sectionIdStrategy,
+				sectionIdStrategyEnvironmentVariable,
+			),
+		)
+		return
+	}
+
+	tlsConfig := newTLSConfig(
+		ctx,
+		tlsAttributeValues{
+			caCertificate:          caCertificate,
+			caCertificateFile:      caCertificateFile,
+			certificateFingerprint: certificateFingerprint,
+			clientCertificate:      clientCertificate,
+			clientCertificateFile:  clientCertificateFile,
+			clientKey:              clientKey,
+			clientKeyFile:          clientKeyFile,
+			insecureSkipVerify:     insecureSkipVerify,
+		},
+		res,
+	)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	sshPrivateKeyValue := readPEMAttributeValue(
+		sshPrivateKey,
+		sshPrivateKeyFile,
+		sshPrivateKeyFileAttribute,
+		sshPrivateKeyHumanReadableName,
+		res,
+	)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	sshOptions := lucirpc.SSHOptions{
+		Agent:                 sshAgent,
+		InsecureIgnoreHostKey: sshInsecureIgnoreHostKey,
+		KnownHostsFile:        sshKnownHostsFile,
+		Password:              password,
+		PrivateKey:            sshPrivateKeyValue,
+	}
+	clientOptions := []lucirpc.ClientOption{
+		lucirpc.WithCommitBatchWindow(time.Duration(commitBatchWindow) * time.Millisecond),
+		lucirpc.WithPendingChanges(pendingChangesPolicy),
+		lucirpc.WithRequestTimeout(time.Duration(requestTimeout) * time.Second),
+		lucirpc.WithRetries(
+			int(maxRetries),
+			time.Duration(retryBackoff)*time.Millisecond,
+			time.Duration(retryMaxBackoff)*time.Millisecond,
+		),
+		lucirpc.WithRollback(time.Duration(rollbackTimeout) * time.Second),
+		lucirpc.WithTLSConfig(tlsConfig),
+	}
+	defaultConnection := connectionAttributeValues{
+		credentials: credentialAttributeValues{
+			password:        password,
+			passwordCommand: passwordCommand,
+			passwordFile:    passwordFile,
+			sessionToken:    sessionToken,
+		},
+		hostname:  hostname,
+		port:      port,
+		scheme:    scheme,
+		transport: transport,
+		username:  username,
+	}
+	validateCredentials(path.Empty(), defaultConnection, &res.Diagnostics)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	connections := newDeviceConnections(
 		ctx,
-		scheme,
-		hostname,
-		port,
-		username,
-		password,
+		p.lookupEnv,
+		model,
+		defaultConnection,
+		rollbackTimeout,
 		res,
 	)
 	if res.Diagnostics.HasError() {
 		return
 	}
 
-	setProviderData(ctx, client, res)
+	connectors := map[string]lucirpcglue.DeviceConnector{}
+	for name, connection := range connections {
+		connectors[name] = newDeviceConnector(connection, sshOptions, clientOptions)
+	}
+
+	connectors[""] = newDeviceConnector(defaultConnection, sshOptions, clientOptions)
+	devices := lucirpcglue.NewDevices(connectors)
+
+	// Without any other devices,
+	// the provider connects to its device (and probes what it can do) straight away so any problem is reported early.
+	if len(connections) == 0 {
+		_, diagnostics := devices.Client(ctx, "")
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	setProviderData(
+		ctx,
+		devices,
+		lucirpcglue.NewSectionIds(sectionIdStrategy, sectionIdAttribute),
+		res,
+	)
 	if res.Diagnostics.HasError() {
 		return
 	}
@@ -168,23 +661,40 @@ func (p *openWrtProvider) DataSources(
 ) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		bridgevlan.NewDataSource,
+		bridgevlan.NewListDataSource,
 		device.NewDataSource,
+		device.NewListDataSource,
 		dhcp.NewDataSource,
+		dhcp.NewListDataSource,
 		dnsmasq.NewDataSource,
+		dnsmasq.NewListDataSource,
 		domain.NewDataSource,
+		domain.NewListDataSource,
 		forwarding.NewDataSource,
+		forwarding.NewListDataSource,
 		globals.NewDataSource,
 		host.NewDataSource,
+		host.NewListDataSource,
 		networkinterface.NewDataSource,
+		networkinterface.NewListDataSource,
 		networkswitch.NewDataSource,
+		networkswitch.NewListDataSource,
 		odhcpd.NewDataSource,
 		switchvlan.NewDataSource,
+		switchvlan.NewListDataSource,
 		rule.NewDataSource,
+		rule.NewListDataSource,
 		system.NewDataSource,
 		wifidevice.NewDataSource,
+		wifidevice.NewListDataSource,
 		wifiiface.NewDataSource,
+		wifiiface.NewListDataSource,
 		zone.NewDataSource,
+		zone.NewListDataSource,
 		redirect.NewDataSource,
+		redirect.NewListDataSource,
+		section.NewDataSource,
+		config.NewDataSource,
 	}
 }
 
@@ -221,6 +731,8 @@ func (p *openWrtProvider) Resources(
 		wifiiface.NewResource,
 		zone.NewResource,
 		redirect.NewResource,
+		section.NewResource,
+		reload.NewResource,
 	}
 }
 
@@ -230,129 +742,840 @@ func (p *openWrtProvider) Schema(
 	req provider.SchemaRequest,
 	res *provider.SchemaResponse,
 ) {
-	hostname := schema.StringAttribute{
+	caCertificate := schema.StringAttribute{
 		Description: fmt.Sprintf(
-			"The %s to use. Defaults to %q.",
-			hostnameHumanReadableName,
-			hostnameDefaultValue,
+			"The PEM-encoded %s to trust when connecting over HTTPS, in addition to the system certificates. Conflicts with \"ca_certificate_file\".",
+			caCertificateHumanReadableName,
 		),
 		Optional: true,
 		Validators: []validator.String{
 			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(caCertificateFileAttribute),
+			),
 		},
 	}
 
-	password := schema.StringAttribute{
+	caCertificateFile := schema.StringAttribute{
 		Description: fmt.Sprintf(
-			"The %s to use. Defaults to %q.",
-			passwordHumanReadableName,
-			passwordDefaultValue,
+			"The path to a %s containing PEM-encoded certificates to trust when connecting over HTTPS, in addition to the system certificates. Conflicts with \"ca_certificate\".",
+			caCertificateFileHumanReadableName,
 		),
-		Optional:  true,
-		Sensitive: true,
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(caCertificateAttribute),
+			),
+		},
 	}
 
-	port := schema.Int64Attribute{
+	certificateFingerprint := schema.StringAttribute{
 		Description: fmt.Sprintf(
-			"The %s to use. Defaults to %d.",
-			portHumanReadableName,
-			portDefaultValue,
+			"The hex-encoded SHA-256 %s of the device's HTTPS certificate. Colons between bytes are allowed. When set, the device's certificate must have this fingerprint and is not otherwise verified. This allows trusting a self-signed certificate.",
+			certificateFingerprintHumanReadableName,
 		),
 		Optional: true,
-		Validators: []validator.Int64{
-			int64validator.Between(1, 65535),
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
 		},
 	}
 
-	scheme := schema.StringAttribute{
+	clientCertificate := schema.StringAttribute{
 		Description: fmt.Sprintf(
-			"The %s to use. Defaults to %q.",
-			schemeHumanReadableName,
-			schemeDefaultValue,
+			"The PEM-encoded %s to authenticate with over HTTPS. Requires a client key. Conflicts with \"client_certificate_file\".",
+			clientCertificateHumanReadableName,
 		),
 		Optional: true,
 		Validators: []validator.String{
-			stringvalidator.OneOf(
-				"http",
-				"https",
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(clientCertificateFileAttribute),
 			),
 		},
 	}
 
-	username := schema.StringAttribute{
+	clientCertificateFile := schema.StringAttribute{
 		Description: fmt.Sprintf(
-			"The %s to use. Defaults to %q.",
-			usernameHumanReadableName,
-			usernameDefaultValue,
+			"The path to a %s containing a PEM-encoded certificate to authenticate with over HTTPS. Requires a client key. Conflicts with \"client_certificate\".",
+			clientCertificateFileHumanReadableName,
 		),
 		Optional: true,
 		Validators: []validator.String{
 			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(clientCertificateAttribute),
+			),
 		},
 	}
 
-	res.Schema = schema.Schema{
-		Attributes: map[string]schema.Attribute{
-			hostnameAttribute: hostname,
-			passwordAttribute: password,
-			portAttribute:     port,
-			schemeAttribute:   scheme,
-			usernameAttribute: username,
+	clientKey := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The PEM-encoded %s for the client certificate. Conflicts with \"client_key_file\".",
+			clientKeyHumanReadableName,
+		),
+		Optional:  true,
+		Sensitive: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(clientKeyFileAttribute),
+			),
 		},
-		Description: "Interfaces with an OpenWrt device through LuCI RPC. See https://github.com/openwrt/luci/wiki/JsonRpcHowTo#basics for setup instructions.",
 	}
-}
-
-// openWrtProviderModel maps provider schema data to a Go type.
-type openWrtProviderModel struct {
-	Hostname types.String `tfsdk:"hostname"`
-	Password types.String `tfsdk:"password"`
-	Port     types.Int64  `tfsdk:"port"`
-	Scheme   types.String `tfsdk:"scheme"`
-	Username types.String `tfsdk:"username"`
-}
-
-type attributeInt64Default interface {
-	IsNull() bool
-	ValueInt64() int64
-}
-
-type attributeStringDefault interface {
-	IsNull() bool
-	ValueString() string
-}
-
-type attributeKnown interface {
-	IsUnknown() bool
-}
 
-func defaultInt64AttributeValue(
-	lookupEnv func(string) (string, bool),
-	attribute attributeInt64Default,
-	environmentVariable string,
-	defaultValue int64,
-) int64 {
-	value := defaultValue
-	variable, ok := lookupEnv(environmentVariable)
-	if ok {
-		parsed, err := strconv.Atoi(variable)
-		if err == nil {
-			value = int64(parsed)
-		}
+	clientKeyFile := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The path to a %s containing the PEM-encoded private key for the client certificate. Conflicts with \"client_key\".",
+			clientKeyFileHumanReadableName,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(clientKeyAttribute),
+			),
+		},
 	}
 
-	if !attribute.IsNull() {
-		value = attribute.ValueInt64()
+	commitBatchWindow := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use, in milliseconds. Changes to the same UCI config made within this window are committed together, so services on the device only reload once for the whole batch. Any problem committing is reported on every resource in the batch. Defaults to %d, which commits after every change.",
+			commitBatchWindowHumanReadableName,
+			commitBatchWindowDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
 	}
 
-	return value
-}
+	devices := schema.MapNestedAttribute{
+		Description: fmt.Sprintf(
+			"Other devices to manage, keyed by name. Resources and data sources use one of these when their %q is set to its name. The provider only connects to each device (and probes what it can do) the first time it is used, and then reuses the connection. Each device uses the provider's settings (e.g. TLS, SSH, and retries) for anything it does not set. A device that sets any of %q, %q, %q, or %q does not use the provider's credentials. When any %s are set, the provider's own device is also only connected to the first time it is used.",
+			lucirpcglue.TargetDeviceAttribute,
+			passwordAttribute,
+			passwordCommandAttribute,
+			passwordFileAttribute,
+			sessionTokenAttribute,
+			devicesHumanReadableName,
+		),
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				hostnameAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s of the device.",
+						hostnameHumanReadableName,
+					),
+					Required: true,
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+					},
+				},
+				passwordAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s to use for the device. Defaults to the provider's credentials. Conflicts with %q, %q, and %q.",
+						passwordHumanReadableName,
+						passwordCommandAttribute,
+						passwordFileAttribute,
+						sessionTokenAttribute,
+					),
+					Optional:  true,
+					Sensitive: true,
+					Validators: []validator.String{
+						stringvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName(passwordCommandAttribute),
+							path.MatchRelative().AtParent().AtName(passwordFileAttribute),
+							path.MatchRelative().AtParent().AtName(sessionTokenAttribute),
+						),
+					},
+				},
+				passwordCommandAttribute: schema.ListAttribute{
+					Description: fmt.Sprintf(
+						"A %s that prints the password for the device. Conflicts with %q, %q, and %q.",
+						passwordCommandHumanReadableName,
+						passwordAttribute,
+						passwordFileAttribute,
+						sessionTokenAttribute,
+					),
+					ElementType: types.StringType,
+					Optional:    true,
+					Validators: []validator.List{
+						listvalidator.SizeAtLeast(1),
+						listvalidator.ValueStringsAre(
+							stringvalidator.LengthAtLeast(1),
+						),
+						listvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName(passwordAttribute),
+							path.MatchRelative().AtParent().AtName(passwordFileAttribute),
+							path.MatchRelative().AtParent().AtName(sessionTokenAttribute),
+						),
+					},
+				},
+				passwordFileAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The path to a %s containing the password for the device. Conflicts with %q, %q, and %q.",
+						passwordFileHumanReadableName,
+						passwordAttribute,
+						passwordCommandAttribute,
+						sessionTokenAttribute,
+					),
+					Optional: true,
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName(passwordAttribute),
+							path.MatchRelative().AtParent().AtName(passwordCommandAttribute),
+							path.MatchRelative().AtParent().AtName(sessionTokenAttribute),
+						),
+					},
+				},
+				portAttribute: schema.Int64Attribute{
+					Description: fmt.Sprintf(
+						"The %s to use for the device. Defaults to the provider's %s if it is set, or the default %s for the device's transport.",
+						portHumanReadableName,
+						portHumanReadableName,
+						portHumanReadableName,
+					),
+					Optional: true,
+					Validators: []validator.Int64{
+						int64validator.Between(1, 65535),
+					},
+				},
+				schemeAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s to use for the device. Defaults to the provider's %s.",
+						schemeHumanReadableName,
+						schemeHumanReadableName,
+					),
+					Optional: true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(
+							"http",
+							"https",
+						),
+					},
+				},
+				sessionTokenAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s of an existing rpcd session on the device. Conflicts with %q, %q, and %q.",
+						sessionTokenHumanReadableName,
+						passwordAttribute,
+						passwordCommandAttribute,
+						passwordFileAttribute,
+					),
+					Optional:  true,
+					Sensitive: true,
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.ConflictsWith(
+							path.MatchRelative().AtParent().AtName(passwordAttribute),
+							path.MatchRelative().AtParent().AtName(passwordCommandAttribute),
+							path.MatchRelative().AtParent().AtName(passwordFileAttribute),
+						),
+					},
+				},
+				transportAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s to use for the device. Defaults to the provider's %s.",
+						transportHumanReadableName,
+						transportHumanReadableName,
+					),
+					Optional: true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(
+							transportLuCIRPC,
+							transportSSH,
+							transportUbus,
+						),
+					},
+				},
+				usernameAttribute: schema.StringAttribute{
+					Description: fmt.Sprintf(
+						"The %s to use for the device. Defaults to the provider's %s.",
+						usernameHumanReadableName,
+						usernameHumanReadableName,
+					),
+					Optional: true,
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+					},
+				},
+			},
+		},
+		Optional: true,
+		Validators: []validator.Map{
+			mapvalidator.KeysAre(
+				stringvalidator.RegexMatches(
+					deviceNamePattern,
+					"must only contain letters, numbers, periods, hyphens, and underscores",
+				),
+			),
+		},
+	}
 
-func defaultStringAttributeValue(
-	lookupEnv func(string) (string, bool),
-	attribute attributeStringDefault,
-	environmentVariable string,
-	defaultValue string,
+	hostname := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Defaults to %q.",
+			hostnameHumanReadableName,
+			hostnameDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+
+	insecureSkipVerify := schema.BoolAttribute{
+		Description: fmt.Sprintf(
+			"Whether to skip verifying the device's HTTPS certificate. This is insecure, prefer setting a CA certificate or certificate fingerprint instead. Defaults to %t.",
+			insecureSkipVerifyDefaultValue,
+		),
+		Optional: true,
+	}
+
+	maxRetries := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Requests that fail with a temporary problem (like the device being unreachable while services reload) are sent again up to this many times. Reads are retried after any temporary problem. Changes (including commits and service actions) are only retried if the device could not be reached at all, so they are never made twice. Defaults to %d, which does not retry requests.",
+			maxRetriesHumanReadableName,
+			maxRetriesDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
+	}
+
+	password := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Defaults to %q. Conflicts with %q, %q, and %q.",
+			passwordHumanReadableName,
+			passwordDefaultValue,
+			passwordCommandAttribute,
+			passwordFileAttribute,
+			sessionTokenAttribute,
+		),
+		Optional:  true,
+		Sensitive: true,
+		Validators: []validator.String{
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(passwordCommandAttribute),
+				path.MatchRoot(passwordFileAttribute),
+				path.MatchRoot(sessionTokenAttribute),
+			),
+		},
+	}
+
+	passwordCommand := schema.ListAttribute{
+		Description: fmt.Sprintf(
+			"A %s that prints the password (e.g. a credential helper). The first element is the program, and the rest are its arguments. The command is run every time the provider logs in, and what it prints is used as the password, without the trailing line ending. The %s environment variable is split on whitespace. Conflicts with %q, %q, and %q.",
+			passwordCommandHumanReadableName,
+			passwordCommandEnvironmentVariable,
+			passwordAttribute,
+			passwordFileAttribute,
+			sessionTokenAttribute,
+		),
+		ElementType: types.StringType,
+		Optional:    true,
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+			listvalidator.ValueStringsAre(
+				stringvalidator.LengthAtLeast(1),
+			),
+			listvalidator.ConflictsWith(
+				path.MatchRoot(passwordAttribute),
+				path.MatchRoot(passwordFileAttribute),
+				path.MatchRoot(sessionTokenAttribute),
+			),
+		},
+	}
+
+	passwordFile := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The path to a %s containing the password. The file is read every time the provider logs in, and a trailing line ending is ignored. Conflicts with %q, %q, and %q.",
+			passwordFileHumanReadableName,
+			passwordAttribute,
+			passwordCommandAttribute,
+			sessionTokenAttribute,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(passwordAttribute),
+				path.MatchRoot(passwordCommandAttribute),
+				path.MatchRoot(sessionTokenAttribute),
+			),
+		},
+	}
+
+	pendingChanges := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"What to do when a UCI config already has changes staged on the device before the provider changes it (e.g. unsaved edits in LuCI). Committing the config would commit those changes too. %q refuses to change the config. %q commits the changes along with the provider's, and warns about them. %q throws the changes away, and warns about them. Defaults to %q.",
+			pendingChangesFail,
+			pendingChangesWarn,
+			pendingChangesRevert,
+			pendingChangesDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(
+				pendingChangesFail,
+				pendingChangesRevert,
+				pendingChangesWarn,
+			),
+		},
+	}
+
+	port := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Defaults to %d, or %d for the %q transport.",
+			portHumanReadableName,
+			portDefaultValue,
+			portSSHDefaultValue,
+			transportSSH,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.Between(1, 65535),
+		},
+	}
+
+	requestTimeout := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use, in seconds. Each request to the device fails if it takes longer than this. Defaults to %d, which means requests never time out.",
+			requestTimeoutHumanReadableName,
+			requestTimeoutDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
+	}
+
+	retryBackoff := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use, in milliseconds. This is how long to wait before the first retry. Each retry after that waits twice as long as the last. Defaults to %d.",
+			retryBackoffHumanReadableName,
+			retryBackoffDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
+	}
+
+	retryMaxBackoff := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use, in milliseconds. This is the longest to wait between retries. Defaults to %d.",
+			retryMaxBackoffHumanReadableName,
+			retryMaxBackoffDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
+	}
+
+	rollbackTimeout := schema.Int64Attribute{
+		Description: fmt.Sprintf(
+			"The %s to use, in seconds. When set, changes are applied so the device rolls them back unless the provider can still reach it within this many seconds. This guards against changes that would lock the provider out of the device. SSH cannot apply changes with a rollback, so this requires the %q or %q transport. LuCI RPC cannot choose how long the device waits before rolling back: LuCI waits for its own `luci.apply.rollback` setting, and never less than 90 seconds. With the %q transport, this is only how long the provider keeps trying to confirm the changes. Applying changes applies every config on the device at once, so while this is set, changes to different configs are made one at a time instead of in parallel, and each one waits for the previous apply to be confirmed (at least the few seconds the services get to reload). Changes to the same config still join one commit within the %s. Defaults to %d, which does not roll back changes. Otherwise, it must be at least 1 second.",
+			rollbackTimeoutHumanReadableName,
+			transportLuCIRPC,
+			transportUbus,
+			transportLuCIRPC,
+			commitBatchWindowHumanReadableName,
+			rollbackTimeoutDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.Int64{
+			int64validator.AtLeast(0),
+		},
+	}
+
+	scheme := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Defaults to %q.",
+			schemeHumanReadableName,
+			schemeDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(
+				"http",
+				"https",
+			),
+		},
+	}
+
+	sectionIdAttribute := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The attribute to name sections after when the %s is %q. Characters UCI does not allow in section names are replaced with underscores. Resources without this attribute, or where it is not set, fall back to anonymous sections. Defaults to %q.",
+			sectionIdStrategyHumanReadableName,
+			lucirpcglue.SectionIdStrategyAttribute,
+			sectionIdAttributeDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+
+	sectionIdStrategy := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"How to name a section when a resource does not set its `id`. %q names it `tfcfg` followed by a random number. %q creates an anonymous section, and uses the name the device generates for it (e.g. `cfg0392bd`). The device renames anonymous sections when an earlier section is removed or moved, so the section is then found again by its values. %q names it after the value of the %s. Defaults to %q.",
+			lucirpcglue.SectionIdStrategyRandom,
+			lucirpcglue.SectionIdStrategyAnonymous,
+			lucirpcglue.SectionIdStrategyAttribute,
+			sectionIdAttributeHumanReadableName,
+			sectionIdStrategyDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(
+				lucirpcglue.SectionIdStrategyAnonymous,
+				lucirpcglue.SectionIdStrategyAttribute,
+				lucirpcglue.SectionIdStrategyRandom,
+			),
+		},
+	}
+
+	sessionToken := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s of an existing rpcd session to use instead of logging in (e.g. from `ubus call session login`). The username and password are not used. The session is not renewed, so it must not expire while the provider runs. Not supported by the %q transport. Conflicts with %q, %q, and %q.",
+			sessionTokenHumanReadableName,
+			transportSSH,
+			passwordAttribute,
+			passwordCommandAttribute,
+			passwordFileAttribute,
+		),
+		Optional:  true,
+		Sensitive: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(passwordAttribute),
+				path.MatchRoot(passwordCommandAttribute),
+				path.MatchRoot(passwordFileAttribute),
+			),
+		},
+	}
+
+	sshAgent := schema.BoolAttribute{
+		Description: fmt.Sprintf(
+			"Whether to authenticate with the keys held by the %s (found with the SSH_AUTH_SOCK environment variable). Only used by the %q transport. Defaults to %t.",
+			sshAgentHumanReadableName,
+			transportSSH,
+			sshAgentDefaultValue,
+		),
+		Optional: true,
+	}
+
+	sshInsecureIgnoreHostKey := schema.BoolAttribute{
+		Description: fmt.Sprintf(
+			"Whether to skip verifying the device's SSH host key. This is insecure, prefer setting the %s instead. Only used by the %q transport. Defaults to %t.",
+			sshKnownHostsFileHumanReadableName,
+			transportSSH,
+			sshInsecureIgnoreHostKeyDefaultValue,
+		),
+		Optional: true,
+	}
+
+	sshKnownHostsFile := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The path to the %s to verify the device's host key against. Only used by the %q transport. Defaults to \"~/.ssh/known_hosts\".",
+			sshKnownHostsFileHumanReadableName,
+			transportSSH,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+
+	sshPrivateKey := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The PEM-encoded %s to authenticate with. Keys protected by a passphrase are not supported, add them to the SSH agent instead. Only used by the %q transport. Conflicts with \"ssh_private_key_file\".",
+			sshPrivateKeyHumanReadableName,
+			transportSSH,
+		),
+		Optional:  true,
+		Sensitive: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(sshPrivateKeyFileAttribute),
+			),
+		},
+	}
+
+	sshPrivateKeyFile := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The path to an %s containing the PEM-encoded private key to authenticate with. Keys protected by a passphrase are not supported, add them to the SSH agent instead. Only used by the %q transport. Conflicts with \"ssh_private_key\".",
+			sshPrivateKeyFileHumanReadableName,
+			transportSSH,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+			stringvalidator.ConflictsWith(
+				path.MatchRoot(sshPrivateKeyAttribute),
+			),
+		},
+	}
+
+	transport := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s to use. %q requires the luci-mod-rpc package on the device. %q only requires the rpcd and uhttpd-mod-ubus packages. %q runs the uci command line tool over SSH, and only requires an SSH server (e.g. dropbear). It authenticates with the password, an SSH private key, or the SSH agent. Defaults to %q.",
+			transportHumanReadableName,
+			transportLuCIRPC,
+			transportUbus,
+			transportSSH,
+			transportDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(
+				transportLuCIRPC,
+				transportSSH,
+				transportUbus,
+			),
+		},
+	}
+
+	username := schema.StringAttribute{
+		Description: fmt.Sprintf(
+			"The %s to use. Defaults to %q.",
+			usernameHumanReadableName,
+			usernameDefaultValue,
+		),
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+
+	res.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			caCertificateAttribute:            caCertificate,
+			caCertificateFileAttribute:        caCertificateFile,
+			certificateFingerprintAttribute:   certificateFingerprint,
+			clientCertificateAttribute:        clientCertificate,
+			clientCertificateFileAttribute:    clientCertificateFile,
+			clientKeyAttribute:                clientKey,
+			clientKeyFileAttribute:            clientKeyFile,
+			commitBatchWindowAttribute:        commitBatchWindow,
+			devicesAttribute:                  devices,
+			hostnameAttribute:                 hostname,
+			insecureSkipVerifyAttribute:       insecureSkipVerify,
+			maxRetriesAttribute:               maxRetries,
+			passwordAttribute:                 password,
+			passwordCommandAttribute:          passwordCommand,
+			passwordFileAttribute:             passwordFile,
+			pendingChangesAttribute:           pendingChanges,
+			portAttribute:                     port,
+			requestTimeoutAttribute:           requestTimeout,
+			retryBackoffAttribute:             retryBackoff,
+			retryMaxBackoffAttribute:          retryMaxBackoff,
+			rollbackTimeoutAttribute:          rollbackTimeout,
+			schemeAttribute:                   scheme,
+			sectionIdAttributeAttribute:       sectionIdAttribute,
+			sectionIdStrategyAttribute:        sectionIdStrategy,
+			sessionTokenAttribute:             sessionToken,
+			sshAgentAttribute:                 sshAgent,
+			sshInsecureIgnoreHostKeyAttribute: sshInsecureIgnoreHostKey,
+			sshKnownHostsFileAttribute:        sshKnownHostsFile,
+			sshPrivateKeyAttribute:            sshPrivateKey,
+			sshPrivateKeyFileAttribute:        sshPrivateKeyFile,
+			transportAttribute:                transport,
+			usernameAttribute:                 username,
+		},
+		Description: "Interfaces with an OpenWrt device through LuCI RPC, ubus, or SSH. See https://github.com/openwrt/luci/wiki/JsonRpcHowTo#basics for LuCI RPC setup instructions. After connecting, the provider probes the device for its UCI configs, OpenWrt release, and RPC modules. Resources and data sources for a UCI config the device does not have (e.g. \"wireless\" on a device without Wi-Fi) fail when planning, rather than part way through applying. Attributes that name other sections (e.g. the zone in a firewall rule's \"src\") are also checked when planning, with a warning for any section the device does not have.",
+	}
+}
+
+// openWrtProviderModel maps provider schema data to a Go type.
+type openWrtProviderModel struct {
+	CACertificate            types.String `tfsdk:"ca_certificate"`
+	CACertificateFile        types.String `tfsdk:"ca_certificate_file"`
+	CertificateFingerprint   types.String `tfsdk:"certificate_fingerprint"`
+	ClientCertificate        types.String `tfsdk:"client_certificate"`
+	ClientCertificateFile    types.String `tfsdk:"client_certificate_file"`
+	ClientKey                types.String `tfsdk:"client_key"`
+	ClientKeyFile            types.String `tfsdk:"client_key_file"`
+	CommitBatchWindow        types.Int64  `tfsdk:"commit_batch_window"`
+	Devices                  types.Map    `tfsdk:"devices"`
+	Hostname                 types.String `tfsdk:"hostname"`
+	InsecureSkipVerify       types.Bool   `tfsdk:"insecure_skip_verify"`
+	MaxRetries               types.Int64  `tfsdk:"max_retries"`
+	Password                 types.String `tfsdk:"password"`
+	PasswordCommand          types.List   `tfsdk:"password_command"`
+	PasswordFile             types.String `tfsdk:"password_file"`
+	PendingChanges           types.String `tfsdk:"pending_changes"`
+	Port                     types.Int64  `tfsdk:"port"`
+	RequestTimeout           types.Int64  `tfsdk:"request_timeout"`
+	RetryBackoff             types.Int64  `tfsdk:"retry_backoff"`
+	RetryMaxBackoff          types.Int64  `tfsdk:"retry_max_backoff"`
+	RollbackTimeout          types.Int64  `tfsdk:"rollback_timeout"`
+	Scheme                   types.String `tfsdk:"scheme"`
+	SectionIdAttribute       types.String `tfsdk:"section_id_attribute"`
+	SectionIdStrategy        types.String `tfsdk:"section_id_strategy"`
+	SessionToken             types.String `tfsdk:"session_token"`
+	SSHAgent                 types.Bool   `tfsdk:"ssh_agent"`
+	SSHInsecureIgnoreHostKey types.Bool   `tfsdk:"ssh_insecure_ignore_host_key"`
+	SSHKnownHostsFile        types.String `tfsdk:"ssh_known_hosts_file"`
+	SSHPrivateKey            types.String `tfsdk:"ssh_private_key"`
+	SSHPrivateKeyFile        types.String `tfsdk:"ssh_private_key_file"`
+	Transport                types.String `tfsdk:"transport"`
+	Username                 types.String `tfsdk:"username"`
+}
+
+// connectionAttributeValues are how to connect to a single device.
+type connectionAttributeValues struct {
+	credentials credentialAttributeValues
+	hostname    string
+	port        int64
+	scheme      string
+	transport   string
+	username    string
+}
+
+// credentialAttributeValues are the resolved values of every attribute used to authenticate.
+// Only one of them can be set.
+type credentialAttributeValues struct {
+	password        string
+	passwordCommand []string
+	passwordFile    string
+	sessionToken    string
+}
+
+type deviceModel struct {
+	Hostname        types.String `tfsdk:"hostname"`
+	Password        types.String `tfsdk:"password"`
+	PasswordCommand types.List   `tfsdk:"password_command"`
+	PasswordFile    types.String `tfsdk:"password_file"`
+	Port            types.Int64  `tfsdk:"port"`
+	Scheme          types.String `tfsdk:"scheme"`
+	SessionToken    types.String `tfsdk:"session_token"`
+	Transport       types.String `tfsdk:"transport"`
+	Username        types.String `tfsdk:"username"`
+}
+
+// tlsAttributeValues are the resolved values of every TLS-related attribute.
+type tlsAttributeValues struct {
+	caCertificate          string
+	caCertificateFile      string
+	certificateFingerprint string
+	clientCertificate      string
+	clientCertificateFile  string
+	clientKey              string
+	clientKeyFile          string
+	insecureSkipVerify     bool
+}
+
+type attributeBoolDefault interface {
+	IsNull() bool
+	ValueBool() bool
+}
+
+type attributeInt64Default interface {
+	IsNull() bool
+	ValueInt64() int64
+}
+
+type attributeStringDefault interface {
+	IsNull() bool
+	ValueString() string
+}
+
+type attributeKnown interface {
+	IsUnknown() bool
+}
+
+func defaultBoolAttributeValue(
+	lookupEnv func(string) (string, bool),
+	attribute attributeBoolDefault,
+	environmentVariable string,
+	defaultValue bool,
+) bool {
+	value := defaultValue
+	variable, ok := lookupEnv(environmentVariable)
+	if ok {
+		parsed, err := strconv.ParseBool(variable)
+		if err == nil {
+			value = parsed
+		}
+	}
+
+	if !attribute.IsNull() {
+		value = attribute.ValueBool()
+	}
+
+	return value
+}
+
+func defaultInt64AttributeValue(
+	lookupEnv func(string) (string, bool),
+	attribute attributeInt64Default,
+	environmentVariable string,
+	defaultValue int64,
+) int64 {
+	value := defaultValue
+	variable, ok := lookupEnv(environmentVariable)
+	if ok {
+		parsed, err := strconv.Atoi(variable)
+		if err == nil {
+			value = int64(parsed)
+		}
+	}
+
+	if !attribute.IsNull() {
+		value = attribute.ValueInt64()
+	}
+
+	return value
+}
+
+// defaultStringListAttributeValue is like [defaultStringAttributeValue] for a list of strings.
+// The environment variable is split on whitespace.
+func defaultStringListAttributeValue(
+	ctx context.Context,
+	lookupEnv func(string) (string, bool),
+	attribute types.List,
+	environmentVariable string,
+	res *provider.ConfigureResponse,
+) []string {
+	value := []string{}
+	variable, ok := lookupEnv(environmentVariable)
+	if ok {
+		value = strings.Fields(variable)
+	}
+
+	if !attribute.IsNull() {
+		diagnostics := attribute.ElementsAs(ctx, &value, false)
+		res.Diagnostics.Append(diagnostics...)
+	}
+
+	return value
+}
+
+// defaultPort is the port a `transport` uses unless another one is set.
+// SSH listens on a different port than HTTP.
+func defaultPort(
+	transport string,
+) int64 {
+	if transport == transportSSH {
+		return portSSHDefaultValue
+	}
+
+	return portDefaultValue
+}
+
+func defaultStringAttributeValue(
+	lookupEnv func(string) (string, bool),
+	attribute attributeStringDefault,
+	environmentVariable string,
+	defaultValue string,
 ) string {
 	value := defaultValue
 	variable, ok := lookupEnv(environmentVariable)
@@ -367,33 +1590,240 @@ func defaultStringAttributeValue(
 	return value
 }
 
-func newOpenWrtClient(
+// newDeviceConnection works out how to connect to the device with the `name`.
+// The diagnostics only cover this device,
+// so every device is checked even if another one has problems.
+func newDeviceConnection(
 	ctx context.Context,
-	scheme string,
-	hostname string,
-	port int64,
-	username string,
-	password string,
+	lookupEnv func(string) (string, bool),
+	model openWrtProviderModel,
+	defaultConnection connectionAttributeValues,
+	rollbackTimeout int64,
+	name string,
+	values deviceModel,
+) (connectionAttributeValues, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	devicePath := path.Root(devicesAttribute).AtMapKey(name)
+	for attribute, value := range map[string]attributeKnown{
+		hostnameAttribute:        values.Hostname,
+		passwordAttribute:        values.Password,
+		passwordCommandAttribute: values.PasswordCommand,
+		passwordFileAttribute:    values.PasswordFile,
+		portAttribute:            values.Port,
+		schemeAttribute:          values.Scheme,
+		sessionTokenAttribute:    values.SessionToken,
+		transportAttribute:       values.Transport,
+		usernameAttribute:        values.Username,
+	} {
+		if value.IsUnknown() {
+			diagnostics.AddAttributeError(
+				devicePath.AtName(attribute),
+				fmt.Sprintf("Unknown OpenWrt %s", devicesHumanReadableName),
+				"The provider cannot create the OpenWrt API client as there is an unknown configuration value for a device. Either target apply the source of the value first, or set the value statically in the configuration.",
+			)
+		}
+	}
+
+	if diagnostics.HasError() {
+		return defaultConnection, diagnostics
+	}
+
+	connection := defaultConnection
+	connection.hostname = values.Hostname.ValueString()
+	if !values.Password.IsNull() || !values.PasswordCommand.IsNull() || !values.PasswordFile.IsNull() || !values.SessionToken.IsNull() {
+		connection.credentials = credentialAttributeValues{
+			password:     values.Password.ValueString(),
+			passwordFile: values.PasswordFile.ValueString(),
+			sessionToken: values.SessionToken.ValueString(),
+		}
+		diagnostics.Append(values.PasswordCommand.ElementsAs(ctx, &connection.credentials.passwordCommand, false)...)
+		if diagnostics.HasError() {
+			return connection, diagnostics
+		}
+	}
+
+	if !values.Scheme.IsNull() {
+		connection.scheme = values.Scheme.ValueString()
+	}
+
+	if !values.Transport.IsNull() {
+		connection.transport = values.Transport.ValueString()
+	}
+
+	if !values.Username.IsNull() {
+		connection.username = values.Username.ValueString()
+	}
+
+	connection.port = defaultInt64AttributeValue(
+		lookupEnv,
+		model.Port,
+		portEnvironmentVariable,
+		defaultPort(connection.transport),
+	)
+	if !values.Port.IsNull() {
+		connection.port = values.Port.ValueInt64()
+	}
+
+	if rollbackTimeout > 0 && connection.transport == transportSSH {
+		diagnostics.AddAttributeError(
+			devicePath.AtName(transportAttribute),
+			"Rollback requires an HTTP transport",
+			fmt.Sprintf(
+				"The %q transport cannot apply changes with a rollback. Either set the %s of the device %q to %q or %q, or unset the %s.",
+				transportSSH,
+				transportHumanReadableName,
+				name,
+				transportLuCIRPC,
+				transportUbus,
+				rollbackTimeoutHumanReadableName,
+			),
+		)
+		return connection, diagnostics
+	}
+
+	validateCredentials(devicePath, connection, &diagnostics)
+	return connection, diagnostics
+}
+
+// newDeviceConnections works out how to connect to each of the `devices` in the `model`.
+// Anything a device does not set is taken from the `defaultConnection`.
+func newDeviceConnections(
+	ctx context.Context,
+	lookupEnv func(string) (string, bool),
+	model openWrtProviderModel,
+	defaultConnection connectionAttributeValues,
+	rollbackTimeout int64,
 	res *provider.ConfigureResponse,
-) *lucirpc.Client {
+) map[string]connectionAttributeValues {
+	connections := map[string]connectionAttributeValues{}
+	if model.Devices.IsUnknown() {
+		res.Diagnostics.AddAttributeError(
+			path.Root(devicesAttribute),
+			fmt.Sprintf("Unknown OpenWrt %s", devicesHumanReadableName),
+			"The provider cannot create the OpenWrt API client as there is an unknown configuration value for the OpenWrt devices. Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+		return connections
+	}
+
+	if model.Devices.IsNull() {
+		return connections
+	}
+
+	devices := map[string]deviceModel{}
+	diagnostics := model.Devices.ElementsAs(ctx, &devices, false)
+	res.Diagnostics.Append(diagnostics...)
+	if diagnostics.HasError() {
+		return connections
+	}
+
+	for name, values := range devices {
+		connection, diagnostics := newDeviceConnection(
+			ctx,
+			lookupEnv,
+			model,
+			defaultConnection,
+			rollbackTimeout,
+			name,
+			values,
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if diagnostics.HasError() {
+			continue
+		}
+
+		connections[name] = connection
+	}
+
+	return connections
+}
+
+// newCredentialOptions are the options for authenticating with the `credentials` other than the password.
+func newCredentialOptions(
+	credentials credentialAttributeValues,
+) []lucirpc.ClientOption {
+	options := []lucirpc.ClientOption{}
+	if len(credentials.passwordCommand) > 0 {
+		options = append(options, lucirpc.WithPasswordCommand(credentials.passwordCommand))
+	}
+
+	if credentials.passwordFile != "" {
+		options = append(options, lucirpc.WithPasswordFile(credentials.passwordFile))
+	}
+
+	if credentials.sessionToken != "" {
+		options = append(options, lucirpc.WithSessionToken(credentials.sessionToken))
+	}
+
+	return options
+}
+
+// newDeviceConnector connects to a device the first time it is used.
+func newDeviceConnector(
+	connection connectionAttributeValues,
+	sshOptions lucirpc.SSHOptions,
+	options []lucirpc.ClientOption,
+) lucirpcglue.DeviceConnector {
+	return func(ctx context.Context) (*lucirpc.Client, diag.Diagnostics) {
+		ctx = setField(ctx, hostnameAttribute, connection.hostname)
+		return newOpenWrtClient(ctx, connection, sshOptions, options)
+	}
+}
+
+func newOpenWrtClient(
+	ctx context.Context,
+	connection connectionAttributeValues,
+	sshOptions lucirpc.SSHOptions,
+	options []lucirpc.ClientOption,
+) (*lucirpc.Client, diag.Diagnostics) {
 	tflog.Debug(ctx, "Creating OpenWrt API Client")
 
-	client, err := lucirpc.NewClient(
-		ctx,
-		scheme,
-		hostname,
-		uint16(port),
-		username,
-		password,
-	)
-	if err != nil {
-		res.Diagnostics.AddError(
-			"problem creating LuCI RPC client",
-			err.Error(),
+	options = append(newCredentialOptions(connection.credentials), options...)
+	var client *lucirpc.Client
+	var err error
+	switch connection.transport {
+	case transportSSH:
+		sshOptions.Password = connection.credentials.password
+		client, err = lucirpc.NewSSHClient(
+			ctx,
+			connection.hostname,
+			uint16(connection.port),
+			connection.username,
+			sshOptions,
+			options...,
+		)
+
+	case transportUbus:
+		client, err = lucirpc.NewUbusClient(
+			ctx,
+			connection.scheme,
+			connection.hostname,
+			uint16(connection.port),
+			connection.username,
+			connection.credentials.password,
+			options...,
+		)
+
+	default:
+		client, err = lucirpc.NewClient(
+			ctx,
+			connection.scheme,
+			connection.hostname,
+			uint16(connection.port),
+			connection.username,
+			connection.credentials.password,
+			options...,
 		)
 	}
 
-	return client
+	diagnostics := diag.Diagnostics{}
+	if err != nil {
+		diagnostics.Append(lucirpcglue.NewClientErrorDiagnostic(
+			"problem creating OpenWrt API client",
+			err,
+		))
+	}
+
+	return client, diagnostics
 }
 
 func newProviderModel(
@@ -409,6 +1839,79 @@ func newProviderModel(
 	return config
 }
 
+func newTLSConfig(
+	ctx context.Context,
+	values tlsAttributeValues,
+	res *provider.ConfigureResponse,
+) *tls.Config {
+	tflog.Debug(ctx, "Creating TLS configuration")
+
+	caCertificate := readPEMAttributeValue(
+		values.caCertificate,
+		values.caCertificateFile,
+		caCertificateFileAttribute,
+		caCertificateHumanReadableName,
+		res,
+	)
+	clientCertificate := readPEMAttributeValue(
+		values.clientCertificate,
+		values.clientCertificateFile,
+		clientCertificateFileAttribute,
+		clientCertificateHumanReadableName,
+		res,
+	)
+	clientKey := readPEMAttributeValue(
+		values.clientKey,
+		values.clientKeyFile,
+		clientKeyFileAttribute,
+		clientKeyHumanReadableName,
+		res,
+	)
+	if res.Diagnostics.HasError() {
+		return nil
+	}
+
+	tlsConfig, err := lucirpc.NewTLSConfig(lucirpc.TLSOptions{
+		CACertificates:         caCertificate,
+		CertificateFingerprint: values.certificateFingerprint,
+		ClientCertificate:      clientCertificate,
+		ClientKey:              clientKey,
+		InsecureSkipVerify:     values.insecureSkipVerify,
+	})
+	if err != nil {
+		res.Diagnostics.AddError(
+			"problem configuring TLS",
+			err.Error(),
+		)
+	}
+
+	return tlsConfig
+}
+
+// readPEMAttributeValue returns PEM-encoded data given either directly as `value`, or in the file at `file`.
+func readPEMAttributeValue(
+	value string,
+	file string,
+	fileAttribute string,
+	humanReadableName string,
+	res *provider.ConfigureResponse,
+) []byte {
+	if file == "" {
+		return []byte(value)
+	}
+
+	result, err := os.ReadFile(file)
+	if err != nil {
+		res.Diagnostics.AddAttributeError(
+			path.Root(fileAttribute),
+			fmt.Sprintf("problem reading OpenWrt %s file", humanReadableName),
+			err.Error(),
+		)
+	}
+
+	return result
+}
+
 func setField(
 	ctx context.Context,
 	key string,
@@ -421,12 +1924,13 @@ func setField(
 
 func setProviderData(
 	ctx context.Context,
-	client *lucirpc.Client,
+	devices *lucirpcglue.Devices,
+	sectionIds lucirpcglue.SectionIds,
 	res *provider.ConfigureResponse,
 ) {
 	tflog.Debug(ctx, "Making OpenWrt provider data available during DataSource, and Resource type Configure methods")
 
-	providerData := lucirpcglue.NewProviderData(*client, providerTypeName)
+	providerData := lucirpcglue.NewProviderData(devices, providerTypeName, sectionIds)
 	res.DataSourceData = providerData
 	res.ResourceData = providerData
 }
@@ -437,6 +1941,62 @@ func validateConfigurationKnown(
 	res *provider.ConfigureResponse,
 ) {
 	tflog.Debug(ctx, "Validating configuration values are known")
+	validateKnown(
+		model.CACertificate,
+		path.Root(caCertificateAttribute),
+		caCertificateEnvironmentVariable,
+		caCertificateHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.CACertificateFile,
+		path.Root(caCertificateFileAttribute),
+		caCertificateFileEnvironmentVariable,
+		caCertificateFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.CertificateFingerprint,
+		path.Root(certificateFingerprintAttribute),
+		certificateFingerprintEnvironmentVariable,
+		certificateFingerprintHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.ClientCertificate,
+		path.Root(clientCertificateAttribute),
+		clientCertificateEnvironmentVariable,
+		clientCertificateHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.ClientCertificateFile,
+		path.Root(clientCertificateFileAttribute),
+		clientCertificateFileEnvironmentVariable,
+		clientCertificateFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.ClientKey,
+		path.Root(clientKeyAttribute),
+		clientKeyEnvironmentVariable,
+		clientKeyHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.ClientKeyFile,
+		path.Root(clientKeyFileAttribute),
+		clientKeyFileEnvironmentVariable,
+		clientKeyFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.CommitBatchWindow,
+		path.Root(commitBatchWindowAttribute),
+		commitBatchWindowEnvironmentVariable,
+		commitBatchWindowHumanReadableName,
+		res,
+	)
 	validateKnown(
 		model.Hostname,
 		path.Root(hostnameAttribute),
@@ -444,6 +2004,20 @@ func validateConfigurationKnown(
 		hostnameHumanReadableName,
 		res,
 	)
+	validateKnown(
+		model.InsecureSkipVerify,
+		path.Root(insecureSkipVerifyAttribute),
+		insecureSkipVerifyEnvironmentVariable,
+		insecureSkipVerifyHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.MaxRetries,
+		path.Root(maxRetriesAttribute),
+		maxRetriesEnvironmentVariable,
+		maxRetriesHumanReadableName,
+		res,
+	)
 	validateKnown(
 		model.Password,
 		path.Root(passwordAttribute),
@@ -451,6 +2025,27 @@ func validateConfigurationKnown(
 		passwordHumanReadableName,
 		res,
 	)
+	validateKnown(
+		model.PasswordCommand,
+		path.Root(passwordCommandAttribute),
+		passwordCommandEnvironmentVariable,
+		passwordCommandHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.PasswordFile,
+		path.Root(passwordFileAttribute),
+		passwordFileEnvironmentVariable,
+		passwordFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.PendingChanges,
+		path.Root(pendingChangesAttribute),
+		pendingChangesEnvironmentVariable,
+		pendingChangesHumanReadableName,
+		res,
+	)
 	validateKnown(
 		model.Port,
 		path.Root(portAttribute),
@@ -458,6 +2053,34 @@ func validateConfigurationKnown(
 		portHumanReadableName,
 		res,
 	)
+	validateKnown(
+		model.RequestTimeout,
+		path.Root(requestTimeoutAttribute),
+		requestTimeoutEnvironmentVariable,
+		requestTimeoutHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.RetryBackoff,
+		path.Root(retryBackoffAttribute),
+		retryBackoffEnvironmentVariable,
+		retryBackoffHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.RetryMaxBackoff,
+		path.Root(retryMaxBackoffAttribute),
+		retryMaxBackoffEnvironmentVariable,
+		retryMaxBackoffHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.RollbackTimeout,
+		path.Root(rollbackTimeoutAttribute),
+		rollbackTimeoutEnvironmentVariable,
+		rollbackTimeoutHumanReadableName,
+		res,
+	)
 	validateKnown(
 		model.Scheme,
 		path.Root(schemeAttribute),
@@ -465,6 +2088,69 @@ func validateConfigurationKnown(
 		schemeHumanReadableName,
 		res,
 	)
+	validateKnown(
+		model.SectionIdAttribute,
+		path.Root(sectionIdAttributeAttribute),
+		sectionIdAttributeEnvironmentVariable,
+		sectionIdAttributeHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SectionIdStrategy,
+		path.Root(sectionIdStrategyAttribute),
+		sectionIdStrategyEnvironmentVariable,
+		sectionIdStrategyHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SessionToken,
+		path.Root(sessionTokenAttribute),
+		sessionTokenEnvironmentVariable,
+		sessionTokenHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SSHAgent,
+		path.Root(sshAgentAttribute),
+		sshAgentEnvironmentVariable,
+		sshAgentHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SSHInsecureIgnoreHostKey,
+		path.Root(sshInsecureIgnoreHostKeyAttribute),
+		sshInsecureIgnoreHostKeyEnvironmentVariable,
+		sshInsecureIgnoreHostKeyHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SSHKnownHostsFile,
+		path.Root(sshKnownHostsFileAttribute),
+		sshKnownHostsFileEnvironmentVariable,
+		sshKnownHostsFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SSHPrivateKey,
+		path.Root(sshPrivateKeyAttribute),
+		sshPrivateKeyEnvironmentVariable,
+		sshPrivateKeyHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.SSHPrivateKeyFile,
+		path.Root(sshPrivateKeyFileAttribute),
+		sshPrivateKeyFileEnvironmentVariable,
+		sshPrivateKeyFileHumanReadableName,
+		res,
+	)
+	validateKnown(
+		model.Transport,
+		path.Root(transportAttribute),
+		transportEnvironmentVariable,
+		transportHumanReadableName,
+		res,
+	)
 	validateKnown(
 		model.Username,
 		path.Root(usernameAttribute),
@@ -474,6 +2160,61 @@ func validateConfigurationKnown(
 	)
 }
 
+// validateCredentials checks that the `connection` only has one way to authenticate,
+// and that its transport supports it.
+// The `connectionPath` is where the connection is configured, or empty for the provider itself.
+func validateCredentials(
+	connectionPath path.Path,
+	connection connectionAttributeValues,
+	diagnostics *diag.Diagnostics,
+) {
+	set := []string{}
+	for _, attribute := range []struct {
+		name  string
+		isSet bool
+	}{
+		{name: passwordAttribute, isSet: connection.credentials.password != ""},
+		{name: passwordCommandAttribute, isSet: len(connection.credentials.passwordCommand) > 0},
+		{name: passwordFileAttribute, isSet: connection.credentials.passwordFile != ""},
+		{name: sessionTokenAttribute, isSet: connection.credentials.sessionToken != ""},
+	} {
+		if attribute.isSet {
+			set = append(set, fmt.Sprintf("%q", attribute.name))
+		}
+	}
+
+	if len(set) > 1 {
+		diagnostics.AddAttributeError(
+			connectionPath,
+			"Conflicting OpenWrt credentials",
+			fmt.Sprintf(
+				"Only one way to authenticate can be used, but %s are all set. Check the configuration, and the %s, %s, %s, and %s environment variables.",
+				strings.Join(set, " and "),
+				passwordEnvironmentVariable,
+				passwordCommandEnvironmentVariable,
+				passwordFileEnvironmentVariable,
+				sessionTokenEnvironmentVariable,
+			),
+		)
+		return
+	}
+
+	if connection.credentials.sessionToken != "" && connection.transport == transportSSH {
+		diagnostics.AddAttributeError(
+			connectionPath,
+			"Session token requires an HTTP transport",
+			fmt.Sprintf(
+				"The %q transport cannot use a %s. Either set the %s to %q or %q, or authenticate another way.",
+				transportSSH,
+				sessionTokenHumanReadableName,
+				transportHumanReadableName,
+				transportLuCIRPC,
+				transportUbus,
+			),
+		)
+	}
+}
+
 func validateKnown(
 	attribute attributeKnown,
 	attributePath path.Path,
@@ -12,6 +12,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/joneshf/terraform-provider-openwrt/internal/acceptancetest"
@@ -58,27 +59,18 @@ func TestOpenWrtProviderConfigureConnectsWithoutError(t *testing.T) {
 	schemaReq := provider.SchemaRequest{}
 	schemaRes := &provider.SchemaResponse{}
 	openWrtProvider.Schema(ctx, schemaReq, schemaRes)
-	config := tfsdk.Config{
-		Schema: schemaRes.Schema,
-		Raw: tftypes.NewValue(
-			tftypes.Object{
-				AttributeTypes: map[string]tftypes.Type{
-					"hostname": tftypes.String,
-					"password": tftypes.String,
-					"port":     tftypes.Number,
-					"scheme":   tftypes.String,
-					"username": tftypes.String,
-				},
-			},
-			map[string]tftypes.Value{
-				"hostname": tftypes.NewValue(tftypes.String, openWrtServer.Hostname),
-				"password": tftypes.NewValue(tftypes.String, openWrtServer.Password),
-				"port":     tftypes.NewValue(tftypes.Number, openWrtServer.HTTPPort),
-				"scheme":   tftypes.NewValue(tftypes.String, openWrtServer.Scheme),
-				"username": tftypes.NewValue(tftypes.String, openWrtServer.Username),
-			},
-		),
-	}
+	config := providerConfig(
+		ctx,
+		t,
+		schemaRes.Schema,
+		map[string]tftypes.Value{
+			"hostname": tftypes.NewValue(tftypes.String, openWrtServer.Hostname),
+			"password": tftypes.NewValue(tftypes.String, openWrtServer.Password),
+			"port":     tftypes.NewValue(tftypes.Number, openWrtServer.HTTPPort),
+			"scheme":   tftypes.NewValue(tftypes.String, openWrtServer.Scheme),
+			"username": tftypes.NewValue(tftypes.String, openWrtServer.Username),
+		},
+	)
 	req := provider.ConfigureRequest{
 		Config: config,
 	}
@@ -114,27 +106,49 @@ func TestOpenWrtProviderConfigureConnectsWithoutErrorWithEnvironmentVariables(t
 	schemaReq := provider.SchemaRequest{}
 	schemaRes := &provider.SchemaResponse{}
 	openWrtProvider.Schema(ctx, schemaReq, schemaRes)
-	config := tfsdk.Config{
-		Schema: schemaRes.Schema,
-		Raw: tftypes.NewValue(
-			tftypes.Object{
-				AttributeTypes: map[string]tftypes.Type{
-					"hostname": tftypes.String,
-					"password": tftypes.String,
-					"port":     tftypes.Number,
-					"scheme":   tftypes.String,
-					"username": tftypes.String,
-				},
-			},
-			map[string]tftypes.Value{
-				"hostname": tftypes.NewValue(tftypes.String, nil),
-				"password": tftypes.NewValue(tftypes.String, nil),
-				"port":     tftypes.NewValue(tftypes.Number, nil),
-				"scheme":   tftypes.NewValue(tftypes.String, nil),
-				"username": tftypes.NewValue(tftypes.String, nil),
-			},
-		),
+	config := providerConfig(
+		ctx,
+		t,
+		schemaRes.Schema,
+		map[string]tftypes.Value{},
+	)
+	req := provider.ConfigureRequest{
+		Config: config,
 	}
+	res := &provider.ConfigureResponse{}
+
+	// When
+	openWrtProvider.Configure(ctx, req, res)
+
+	// Then
+	assert.DeepEqual(t, res.Diagnostics, diag.Diagnostics{})
+}
+
+func TestOpenWrtProviderConfigureConnectsWithoutErrorWithUbusTransport(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	openWrtServer := acceptancetest.RunOpenWrtServer(
+		ctx,
+		*dockerPool,
+		t,
+	)
+	openWrtProvider := openwrt.New("test", os.LookupEnv)
+	schemaReq := provider.SchemaRequest{}
+	schemaRes := &provider.SchemaResponse{}
+	openWrtProvider.Schema(ctx, schemaReq, schemaRes)
+	config := providerConfig(
+		ctx,
+		t,
+		schemaRes.Schema,
+		map[string]tftypes.Value{
+			"hostname":  tftypes.NewValue(tftypes.String, openWrtServer.Hostname),
+			"password":  tftypes.NewValue(tftypes.String, openWrtServer.Password),
+			"port":      tftypes.NewValue(tftypes.Number, openWrtServer.HTTPPort),
+			"scheme":    tftypes.NewValue(tftypes.String, openWrtServer.Scheme),
+			"transport": tftypes.NewValue(tftypes.String, "ubus"),
+			"username":  tftypes.NewValue(tftypes.String, openWrtServer.Username),
+		},
+	)
 	req := provider.ConfigureRequest{
 		Config: config,
 	}
@@ -146,3 +160,31 @@ func TestOpenWrtProviderConfigureConnectsWithoutErrorWithEnvironmentVariables(t
 	// Then
 	assert.DeepEqual(t, res.Diagnostics, diag.Diagnostics{})
 }
+
+// providerConfig constructs a [tfsdk.Config] for the given `schema`.
+// Any attribute not given in `values` is set to null.
+func providerConfig(
+	ctx context.Context,
+	t *testing.T,
+	schema providerschema.Schema,
+	values map[string]tftypes.Value,
+) tfsdk.Config {
+	t.Helper()
+
+	objectType, ok := schema.Type().TerraformType(ctx).(tftypes.Object)
+	assert.Check(t, ok)
+	allValues := map[string]tftypes.Value{}
+	for attribute, attributeType := range objectType.AttributeTypes {
+		value, ok := values[attribute]
+		if !ok {
+			value = tftypes.NewValue(attributeType, nil)
+		}
+
+		allValues[attribute] = value
+	}
+
+	return tfsdk.Config{
+		Schema: schema,
+		Raw:    tftypes.NewValue(objectType, allValues),
+	}
+}
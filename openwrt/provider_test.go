@@ -39,12 +39,79 @@ func TestOpenWrtProviderMetadataSetsTypeName(t *testing.T) {
 	assert.DeepEqual(t, res.TypeName, "openwrt")
 }
 
+func TestOpenWrtProviderSchemaCACertificateAttribute(t *testing.T) {
+	attribute := "ca_certificate"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaCACertificateFileAttribute(t *testing.T) {
+	attribute := "ca_certificate_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaCertificateFingerprintAttribute(t *testing.T) {
+	attribute := "certificate_fingerprint"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaClientCertificateAttribute(t *testing.T) {
+	attribute := "client_certificate"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaClientCertificateFileAttribute(t *testing.T) {
+	attribute := "client_certificate_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaClientKeyAttribute(t *testing.T) {
+	attribute := "client_key"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+	t.Run("is sensitive", schemaAttributeIsSensitive(attribute))
+}
+
+func TestOpenWrtProviderSchemaClientKeyFileAttribute(t *testing.T) {
+	attribute := "client_key_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaCommitBatchWindowAttribute(t *testing.T) {
+	attribute := "commit_batch_window"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaDevicesAttribute(t *testing.T) {
+	attribute := "devices"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
 func TestOpenWrtProviderSchemaHostnameAttribute(t *testing.T) {
 	attribute := "hostname"
 	t.Run("exists", schemaAttributeExists(attribute))
 	t.Run("is optional", schemaAttributeIsOptional(attribute))
 }
 
+func TestOpenWrtProviderSchemaInsecureSkipVerifyAttribute(t *testing.T) {
+	attribute := "insecure_skip_verify"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaMaxRetriesAttribute(t *testing.T) {
+	attribute := "max_retries"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
 func TestOpenWrtProviderSchemaPasswordAttribute(t *testing.T) {
 	attribute := "password"
 	t.Run("exists", schemaAttributeExists(attribute))
@@ -52,18 +119,116 @@ func TestOpenWrtProviderSchemaPasswordAttribute(t *testing.T) {
 	t.Run("is sensitive", schemaAttributeIsSensitive(attribute))
 }
 
+func TestOpenWrtProviderSchemaPasswordCommandAttribute(t *testing.T) {
+	attribute := "password_command"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaPasswordFileAttribute(t *testing.T) {
+	attribute := "password_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaPendingChangesAttribute(t *testing.T) {
+	attribute := "pending_changes"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
 func TestOpenWrtProviderSchemaPortAttribute(t *testing.T) {
 	attribute := "port"
 	t.Run("exists", schemaAttributeExists(attribute))
 	t.Run("is optional", schemaAttributeIsOptional(attribute))
 }
 
+func TestOpenWrtProviderSchemaRequestTimeoutAttribute(t *testing.T) {
+	attribute := "request_timeout"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaRetryBackoffAttribute(t *testing.T) {
+	attribute := "retry_backoff"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaRetryMaxBackoffAttribute(t *testing.T) {
+	attribute := "retry_max_backoff"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaRollbackTimeoutAttribute(t *testing.T) {
+	attribute := "rollback_timeout"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
 func TestOpenWrtProviderSchemaSchemeAttribute(t *testing.T) {
 	attribute := "scheme"
 	t.Run("exists", schemaAttributeExists(attribute))
 	t.Run("is optional", schemaAttributeIsOptional(attribute))
 }
 
+func TestOpenWrtProviderSchemaSectionIdAttributeAttribute(t *testing.T) {
+	attribute := "section_id_attribute"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaSectionIdStrategyAttribute(t *testing.T) {
+	attribute := "section_id_strategy"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaSessionTokenAttribute(t *testing.T) {
+	attribute := "session_token"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+	t.Run("is sensitive", schemaAttributeIsSensitive(attribute))
+}
+
+func TestOpenWrtProviderSchemaSSHAgentAttribute(t *testing.T) {
+	attribute := "ssh_agent"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaSSHInsecureIgnoreHostKeyAttribute(t *testing.T) {
+	attribute := "ssh_insecure_ignore_host_key"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaSSHKnownHostsFileAttribute(t *testing.T) {
+	attribute := "ssh_known_hosts_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaSSHPrivateKeyAttribute(t *testing.T) {
+	attribute := "ssh_private_key"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+	t.Run("is sensitive", schemaAttributeIsSensitive(attribute))
+}
+
+func TestOpenWrtProviderSchemaSSHPrivateKeyFileAttribute(t *testing.T) {
+	attribute := "ssh_private_key_file"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
+func TestOpenWrtProviderSchemaTransportAttribute(t *testing.T) {
+	attribute := "transport"
+	t.Run("exists", schemaAttributeExists(attribute))
+	t.Run("is optional", schemaAttributeIsOptional(attribute))
+}
+
 func TestOpenWrtProviderSchemaUsernameAttribute(t *testing.T) {
 	attribute := "username"
 	t.Run("exists", schemaAttributeExists(attribute))
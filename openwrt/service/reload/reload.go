@@ -0,0 +1,245 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+)
+
+const (
+	actionAttribute            = "action"
+	actionAttributeDescription = "What to run. `reload` makes the service pick up its configuration again. `restart` stops and starts the service. Defaults to `reload`."
+	actionDefaultValue         = lucirpc.ServiceReload
+
+	idAttribute            = "id"
+	idAttributeDescription = "The name of the service."
+
+	schemaDescription = "Reloads or restarts a service on the device (like `/etc/init.d/<service> reload`). Committing a UCI config does not always make every service pick up the change. This runs when the resource is created, and again whenever anything about it changes. Use `triggers` to run it again when the resources that configure the service change. Requires the `sys` library for the LuCI RPC transport, or access to `rc` `init` for the ubus transport."
+
+	serviceAttribute            = "service"
+	serviceAttributeDescription = "The name of the init script in `/etc/init.d` for the service (e.g. `dnsmasq`)."
+
+	triggersAttribute            = "triggers"
+	triggersAttributeDescription = "Arbitrary values that run the service action again when they change. For example, use the `id`s or encoded values of the resources that configure the service."
+
+	typeName = "service_reload"
+)
+
+var (
+	_ resource.Resource              = &reloadResource{}
+	_ resource.ResourceWithConfigure = &reloadResource{}
+
+	serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+func NewResource() resource.Resource {
+	return &reloadResource{}
+}
+
+type model struct {
+	Action       types.String `tfsdk:"action"`
+	Id           types.String `tfsdk:"id"`
+	Service      types.String `tfsdk:"service"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Triggers     types.Map    `tfsdk:"triggers"`
+}
+
+type reloadResource struct {
+	devices      *lucirpcglue.Devices
+	fullTypeName string
+}
+
+// Configure adds the provider configured client to the resource.
+func (d *reloadResource) Configure(
+	ctx context.Context,
+	req resource.ConfigureRequest,
+	res *resource.ConfigureResponse,
+) {
+	tflog.Info(ctx, "Configuring service reload resource")
+	if req.ProviderData == nil {
+		tflog.Debug(ctx, "No provider data")
+		return
+	}
+
+	providerData, diagnostics := lucirpcglue.ParseProviderData(lucirpcglue.ConfigureRequest(req))
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	d.devices = providerData.Devices
+	d.fullTypeName = fmt.Sprintf("%s_%s", providerData.TypeName, typeName)
+}
+
+// Create runs the service action and sets the initial Terraform state.
+func (d *reloadResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	res *resource.CreateResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Creating %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from plan")
+	var plan model
+	diagnostics := req.Plan.Get(ctx, &plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.Plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	service := plan.Service.ValueString()
+	action := actionDefaultValue
+	if !plan.Action.IsNull() {
+		action = lucirpc.ServiceAction(plan.Action.ValueString())
+	}
+
+	ctx = tflog.SetField(ctx, "service", service)
+	tflog.Debug(ctx, fmt.Sprintf("Running %s of service", action))
+	diagnostics = lucirpcglue.RunServiceAction(
+		ctx,
+		client,
+		service,
+		action,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Id = types.StringValue(service)
+
+	tflog.Debug(ctx, "Updating state with values")
+	diagnostics = res.State.Set(ctx, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete removes the Terraform state.
+// There is nothing to undo on the device.
+func (d *reloadResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	res *resource.DeleteResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Deleting %s resource", d.fullTypeName))
+}
+
+// Metadata sets the resource type name.
+func (d *reloadResource) Metadata(
+	ctx context.Context,
+	req resource.MetadataRequest,
+	res *resource.MetadataResponse,
+) {
+	res.TypeName = fmt.Sprintf("%s_%s", req.ProviderTypeName, typeName)
+}
+
+// Read keeps the Terraform state as it is.
+// Running a service action leaves nothing behind to read.
+func (d *reloadResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	res *resource.ReadResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Reading %s resource", d.fullTypeName))
+}
+
+// Schema defines the schema for the resource.
+func (d *reloadResource) Schema(
+	ctx context.Context,
+	req resource.SchemaRequest,
+	res *resource.SchemaResponse,
+) {
+	res.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			actionAttribute: schema.StringAttribute{
+				Description: actionAttributeDescription,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(lucirpc.ServiceReload),
+						string(lucirpc.ServiceRestart),
+					),
+				},
+			},
+			idAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: idAttributeDescription,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			serviceAttribute: schema.StringAttribute{
+				Description: serviceAttributeDescription,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						serviceNamePattern,
+						"must only contain letters, numbers, periods, hyphens, and underscores",
+					),
+				},
+			},
+			lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceResourceAttribute(),
+			triggersAttribute: schema.MapAttribute{
+				Description: triggersAttributeDescription,
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Description: schemaDescription,
+	}
+}
+
+// Update sets the Terraform state.
+// Every attribute forces a replacement,
+// so there is never anything to run here.
+func (d *reloadResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	res *resource.UpdateResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Updating %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from plan")
+	var plan model
+	diagnostics := req.Plan.Get(ctx, &plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating state with values")
+	diagnostics = res.State.Set(ctx, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
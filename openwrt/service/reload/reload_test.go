@@ -0,0 +1,65 @@
+package reload_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"gotest.tools/v3/assert"
+)
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_service_reload" "testing" {
+	service = "dnsmasq"
+	triggers = {
+		host = "one"
+	}
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_service_reload.testing", "id", "dnsmasq"),
+			func(*terraform.State) error {
+				assert.DeepEqual(t, server.ServiceActions(), []string{"dnsmasq reload"})
+				return nil
+			},
+		),
+	}
+	changeTriggers := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_service_reload" "testing" {
+	action = "restart"
+	service = "dnsmasq"
+	triggers = {
+		host = "two"
+	}
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			func(*terraform.State) error {
+				assert.DeepEqual(t, server.ServiceActions(), []string{"dnsmasq reload", "dnsmasq restart"})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createResource,
+		changeTriggers,
+	)
+}
@@ -86,16 +86,17 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		conLogLevelAttribute:    conLogLevelSchemaAttribute,
-		cronLogLevelAttribute:   cronLogLevelSchemaAttribute,
-		descriptionAttribute:    descriptionSchemaAttribute,
-		hostnameAttribute:       hostnameSchemaAttribute,
-		logSizeAttribute:        logSizeSchemaAttribute,
-		notesAttribute:          notesSchemaAttribute,
-		timezoneAttribute:       timezoneSchemaAttribute,
-		ttyLoginAttribute:       ttyLoginSchemaAttribute,
-		zonenameAttribute:       zonenameSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		conLogLevelAttribute:              conLogLevelSchemaAttribute,
+		cronLogLevelAttribute:             cronLogLevelSchemaAttribute,
+		descriptionAttribute:              descriptionSchemaAttribute,
+		hostnameAttribute:                 hostnameSchemaAttribute,
+		logSizeAttribute:                  logSizeSchemaAttribute,
+		notesAttribute:                    notesSchemaAttribute,
+		timezoneAttribute:                 timezoneSchemaAttribute,
+		ttyLoginAttribute:                 ttyLoginSchemaAttribute,
+		zonenameAttribute:                 zonenameSchemaAttribute,
 	}
 
 	timezoneSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -145,6 +146,7 @@ type model struct {
 	CronLogLevel types.Int64  `tfsdk:"cronloglevel"`
 	Description  types.String `tfsdk:"description"`
 	Hostname     types.String `tfsdk:"hostname"`
+	TargetDevice types.String `tfsdk:"target_device"`
 	Id           types.String `tfsdk:"id"`
 	LogSize      types.Int64  `tfsdk:"log_size"`
 	Notes        types.String `tfsdk:"notes"`
@@ -153,24 +155,26 @@ type model struct {
 	Zonename     types.String `tfsdk:"zonename"`
 }
 
-func modelGetConLogLevel(m model) types.Int64  { return m.ConLogLevel }
-func modelGetCronLogLevel(m model) types.Int64 { return m.CronLogLevel }
-func modelGetDescription(m model) types.String { return m.Description }
-func modelGetHostname(m model) types.String    { return m.Hostname }
-func modelGetId(m model) types.String          { return m.Id }
-func modelGetLogSize(m model) types.Int64      { return m.LogSize }
-func modelGetNotes(m model) types.String       { return m.Notes }
-func modelGetTimezone(m model) types.String    { return m.Timezone }
-func modelGetTTYLogin(m model) types.Bool      { return m.TTYLogin }
-func modelGetZonename(m model) types.String    { return m.Zonename }
-
-func modelSetConLogLevel(m *model, value types.Int64)  { m.ConLogLevel = value }
-func modelSetCronLogLevel(m *model, value types.Int64) { m.CronLogLevel = value }
-func modelSetDescription(m *model, value types.String) { m.Description = value }
-func modelSetHostname(m *model, value types.String)    { m.Hostname = value }
-func modelSetId(m *model, value types.String)          { m.Id = value }
-func modelSetLogSize(m *model, value types.Int64)      { m.LogSize = value }
-func modelSetNotes(m *model, value types.String)       { m.Notes = value }
-func modelSetTimezone(m *model, value types.String)    { m.Timezone = value }
-func modelSetTTYLogin(m *model, value types.Bool)      { m.TTYLogin = value }
-func modelSetZonename(m *model, value types.String)    { m.Zonename = value }
+func modelGetConLogLevel(m model) types.Int64   { return m.ConLogLevel }
+func modelGetCronLogLevel(m model) types.Int64  { return m.CronLogLevel }
+func modelGetDescription(m model) types.String  { return m.Description }
+func modelGetHostname(m model) types.String     { return m.Hostname }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetLogSize(m model) types.Int64       { return m.LogSize }
+func modelGetNotes(m model) types.String        { return m.Notes }
+func modelGetTimezone(m model) types.String     { return m.Timezone }
+func modelGetTTYLogin(m model) types.Bool       { return m.TTYLogin }
+func modelGetZonename(m model) types.String     { return m.Zonename }
+
+func modelSetConLogLevel(m *model, value types.Int64)   { m.ConLogLevel = value }
+func modelSetCronLogLevel(m *model, value types.Int64)  { m.CronLogLevel = value }
+func modelSetDescription(m *model, value types.String)  { m.Description = value }
+func modelSetHostname(m *model, value types.String)     { m.Hostname = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetLogSize(m *model, value types.Int64)       { m.LogSize = value }
+func modelSetNotes(m *model, value types.String)        { m.Notes = value }
+func modelSetTimezone(m *model, value types.String)     { m.Timezone = value }
+func modelSetTTYLogin(m *model, value types.Bool)       { m.TTYLogin = value }
+func modelSetZonename(m *model, value types.String)     { m.Zonename = value }
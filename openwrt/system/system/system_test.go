@@ -0,0 +1,137 @@
+package system_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("system", "system", "cfg01e48a", map[string]any{
+		"hostname": "OpenWrt",
+		"log_size": 64,
+		"timezone": "UTC",
+		"ttylogin": false,
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_system_system" "this" {
+	id = "cfg01e48a"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_system_system.this", "id", "cfg01e48a"),
+			resource.TestCheckResourceAttr("data.openwrt_system_system.this", "hostname", "OpenWrt"),
+			resource.TestCheckResourceAttr("data.openwrt_system_system.this", "log_size", "64"),
+			resource.TestCheckResourceAttr("data.openwrt_system_system.this", "timezone", "UTC"),
+			resource.TestCheckResourceAttr("data.openwrt_system_system.this", "ttylogin", "false"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("system", "system", "cfg01e48a", map[string]any{
+		"hostname": "OpenWrt",
+		"log_size": 64,
+		"timezone": "UTC",
+		"ttylogin": false,
+	})
+	providerBlock := server.ProviderBlock()
+
+	importValidation := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_system_system" "this" {
+	id = "cfg01e48a"
+}
+`,
+			providerBlock,
+		),
+		ImportState:        true,
+		ImportStateId:      "cfg01e48a",
+		ImportStatePersist: true,
+		ResourceName:       "openwrt_system_system.this",
+	}
+
+	readResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_system_system" "this" {
+	id = "cfg01e48a"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "id", "cfg01e48a"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "hostname", "OpenWrt"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "log_size", "64"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "timezone", "UTC"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "ttylogin", "false"),
+		),
+	}
+
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_system_system" "this" {
+	hostname = "OpenWRT"
+	id = "cfg01e48a"
+	log_size = 64
+	timezone = "UTC"
+	ttylogin = false
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "id", "cfg01e48a"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "hostname", "OpenWRT"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "log_size", "64"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "timezone", "UTC"),
+			resource.TestCheckResourceAttr("openwrt_system_system.this", "ttylogin", "false"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("system", "cfg01e48a")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("cfg01e48a"),
+					".type":      lucirpc.String("system"),
+					"hostname":   lucirpc.String("OpenWRT"),
+					"log_size":   lucirpc.Integer(64),
+					"timezone":   lucirpc.String("UTC"),
+					"ttylogin":   lucirpc.Boolean(false),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		importValidation,
+		readResource,
+		updateAndReadResource,
+	)
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+)
+
+const (
+	anonymousAttribute            = "anonymous"
+	anonymousAttributeDescription = "Whether the section is anonymous. Anonymous sections are named by the device (e.g. `cfg0a1b2c`)."
+
+	configAttribute            = "config"
+	configAttributeDescription = "The UCI config to read (e.g. `dhcp`)."
+
+	idAttribute            = "id"
+	idAttributeDescription = "The UCI config that was read."
+
+	listOptionsAttribute            = "list_options"
+	listOptionsAttributeDescription = "Every UCI list option on the section, keyed by option name."
+
+	nameAttribute            = "name"
+	nameAttributeDescription = "The name of the section."
+
+	optionsAttribute            = "options"
+	optionsAttributeDescription = "Every UCI option on the section that is not a list, keyed by option name."
+
+	schemaDescription = "Reads every section of any UCI config."
+
+	sectionsAttribute            = "sections"
+	sectionsAttributeDescription = "The sections of the config, in the order they appear in the config."
+
+	typeAttribute            = "type"
+	typeAttributeDescription = "The type of the section."
+
+	typeName = "uci_config"
+)
+
+var (
+	_ datasource.DataSource              = &configDataSource{}
+	_ datasource.DataSourceWithConfigure = &configDataSource{}
+
+	listOptionsType = types.ListType{ElemType: types.StringType}
+)
+
+func NewDataSource() datasource.DataSource {
+	return &configDataSource{}
+}
+
+type configDataSource struct {
+	devices      *lucirpcglue.Devices
+	fullTypeName string
+}
+
+type model struct {
+	Config       types.String   `tfsdk:"config"`
+	Id           types.String   `tfsdk:"id"`
+	Sections     []sectionModel `tfsdk:"sections"`
+	TargetDevice types.String   `tfsdk:"target_device"`
+}
+
+type sectionModel struct {
+	Anonymous   types.Bool   `tfsdk:"anonymous"`
+	ListOptions types.Map    `tfsdk:"list_options"`
+	Name        types.String `tfsdk:"name"`
+	Options     types.Map    `tfsdk:"options"`
+	Type        types.String `tfsdk:"type"`
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *configDataSource) Configure(
+	ctx context.Context,
+	req datasource.ConfigureRequest,
+	res *datasource.ConfigureResponse,
+) {
+	tflog.Info(ctx, "Configuring uci config data source")
+	if req.ProviderData == nil {
+		tflog.Debug(ctx, "No provider data")
+		return
+	}
+
+	providerData, diagnostics := lucirpcglue.ParseProviderData(lucirpcglue.ConfigureRequest(req))
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	d.devices = providerData.Devices
+	d.fullTypeName = fmt.Sprintf("%s_%s", providerData.TypeName, typeName)
+}
+
+// Metadata sets the data source name.
+func (d *configDataSource) Metadata(
+	ctx context.Context,
+	req datasource.MetadataRequest,
+	res *datasource.MetadataResponse,
+) {
+	res.TypeName = fmt.Sprintf("%s_%s", req.ProviderTypeName, typeName)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *configDataSource) Read(
+	ctx context.Context,
+	req datasource.ReadRequest,
+	res *datasource.ReadResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Reading %s data source", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from config")
+	var m model
+	diagnostics := req.Config.Get(ctx, &m)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.Config)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	config := m.Config.ValueString()
+	ctx = tflog.SetField(ctx, "config", config)
+	// An empty section type lists the sections of every type.
+	sections, diagnostics := lucirpcglue.ListSections(ctx, client, config, "")
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	m.Id = types.StringValue(config)
+	m.Sections = []sectionModel{}
+	for _, section := range sections {
+		s, diagnostics := readSection(ctx, section)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+
+		m.Sections = append(m.Sections, s)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Setting the %s data source state", d.fullTypeName))
+	diagnostics = res.State.Set(ctx, m)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Schema defines the schema for the data source.
+func (d *configDataSource) Schema(
+	ctx context.Context,
+	req datasource.SchemaRequest,
+	res *datasource.SchemaResponse,
+) {
+	res.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			configAttribute: schema.StringAttribute{
+				Description: configAttributeDescription,
+				Required:    true,
+			},
+			idAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: idAttributeDescription,
+			},
+			sectionsAttribute: schema.ListNestedAttribute{
+				Computed:    true,
+				Description: sectionsAttributeDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						anonymousAttribute: schema.BoolAttribute{
+							Computed:    true,
+							Description: anonymousAttributeDescription,
+						},
+						listOptionsAttribute: schema.MapAttribute{
+							Computed:    true,
+							Description: listOptionsAttributeDescription,
+							ElementType: listOptionsType,
+						},
+						nameAttribute: schema.StringAttribute{
+							Computed:    true,
+							Description: nameAttributeDescription,
+						},
+						optionsAttribute: schema.MapAttribute{
+							Computed:    true,
+							Description: optionsAttributeDescription,
+							ElementType: types.StringType,
+						},
+						typeAttribute: schema.StringAttribute{
+							Computed:    true,
+							Description: typeAttributeDescription,
+						},
+					},
+				},
+			},
+			lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceDataSourceAttribute(),
+		},
+		Description: schemaDescription,
+	}
+}
+
+// readSection converts a section to its Terraform representation.
+func readSection(
+	ctx context.Context,
+	section lucirpc.Options,
+) (sectionModel, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	s := sectionModel{}
+	name, err := section.GetString(".name")
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".name"),
+			err.Error(),
+		)
+		return s, diagnostics
+	}
+
+	sectionType, err := section.GetString(".type")
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".type"),
+			err.Error(),
+		)
+		return s, diagnostics
+	}
+
+	anonymous, err := section.GetBoolean(".anonymous")
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".anonymous"),
+			err.Error(),
+		)
+		return s, diagnostics
+	}
+
+	stringOptions, listOptions := lucirpcglue.SplitOptions(section)
+	options, optionsDiagnostics := types.MapValueFrom(ctx, types.StringType, stringOptions)
+	diagnostics.Append(optionsDiagnostics...)
+	lists, listsDiagnostics := types.MapValueFrom(ctx, listOptionsType, listOptions)
+	diagnostics.Append(listsDiagnostics...)
+
+	s.Anonymous = types.BoolValue(anonymous)
+	s.ListOptions = lists
+	s.Name = types.StringValue(name)
+	s.Options = options
+	s.Type = types.StringValue(sectionType)
+	return s, diagnostics
+}
@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("uhttpd", "uhttpd", "main", map[string]any{
+		"home":        "/www",
+		"listen_http": []string{"0.0.0.0:80"},
+	})
+	server.SetSection("uhttpd", "cert", "defaults", map[string]any{
+		"days": "730",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readConfig := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_uci_config" "testing" {
+	config = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "id", "uhttpd"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.#", "2"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.0.anonymous", "false"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.0.list_options.listen_http.0", "0.0.0.0:80"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.0.name", "main"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.0.options.home", "/www"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.0.type", "uhttpd"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.1.name", "defaults"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.1.options.days", "730"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_config.testing", "sections.1.type", "cert"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readConfig,
+	)
+}
@@ -0,0 +1,197 @@
+package section
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+)
+
+const (
+	anonymousAttribute            = "anonymous"
+	anonymousAttributeDescription = "Whether the section is anonymous. Anonymous sections are named by the device (e.g. `cfg0a1b2c`)."
+
+	dataSourceListOptionsAttributeDescription = "Every UCI list option on the section, keyed by option name."
+
+	dataSourceNameAttributeDescription = "The name of the section."
+
+	dataSourceOptionsAttributeDescription = "Every UCI option on the section that is not a list, keyed by option name."
+
+	dataSourceSchemaDescription = "Reads a section of any UCI config."
+
+	dataSourceTypeAttributeDescription = "The type of the section."
+)
+
+var (
+	_ datasource.DataSource              = &sectionDataSource{}
+	_ datasource.DataSourceWithConfigure = &sectionDataSource{}
+)
+
+func NewDataSource() datasource.DataSource {
+	return &sectionDataSource{}
+}
+
+type dataSourceModel struct {
+	Anonymous    types.Bool   `tfsdk:"anonymous"`
+	Config       types.String `tfsdk:"config"`
+	Id           types.String `tfsdk:"id"`
+	ListOptions  types.Map    `tfsdk:"list_options"`
+	Name         types.String `tfsdk:"name"`
+	Options      types.Map    `tfsdk:"options"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Type         types.String `tfsdk:"type"`
+}
+
+type sectionDataSource struct {
+	devices      *lucirpcglue.Devices
+	fullTypeName string
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *sectionDataSource) Configure(
+	ctx context.Context,
+	req datasource.ConfigureRequest,
+	res *datasource.ConfigureResponse,
+) {
+	tflog.Info(ctx, "Configuring uci section data source")
+	if req.ProviderData == nil {
+		tflog.Debug(ctx, "No provider data")
+		return
+	}
+
+	providerData, diagnostics := lucirpcglue.ParseProviderData(lucirpcglue.ConfigureRequest(req))
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	d.devices = providerData.Devices
+	d.fullTypeName = fmt.Sprintf("%s_%s", providerData.TypeName, typeName)
+}
+
+// Metadata sets the data source name.
+func (d *sectionDataSource) Metadata(
+	ctx context.Context,
+	req datasource.MetadataRequest,
+	res *datasource.MetadataResponse,
+) {
+	res.TypeName = fmt.Sprintf("%s_%s", req.ProviderTypeName, typeName)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *sectionDataSource) Read(
+	ctx context.Context,
+	req datasource.ReadRequest,
+	res *datasource.ReadResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Reading %s data source", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from config")
+	var m dataSourceModel
+	diagnostics := req.Config.Get(ctx, &m)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.Config)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	config := m.Config.ValueString()
+	name := m.Name.ValueString()
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", config, name))
+	section, diagnostics := lucirpcglue.GetSection(ctx, client, config, name)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	anonymous, err := section.GetBoolean(".anonymous")
+	if err != nil {
+		res.Diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".anonymous"),
+			err.Error(),
+		)
+		return
+	}
+
+	sectionType, err := section.GetString(".type")
+	if err != nil {
+		res.Diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".type"),
+			err.Error(),
+		)
+		return
+	}
+
+	stringOptions, listOptions := lucirpcglue.SplitOptions(section)
+	m.Options, diagnostics = types.MapValueFrom(ctx, types.StringType, stringOptions)
+	res.Diagnostics.Append(diagnostics...)
+	m.ListOptions, diagnostics = types.MapValueFrom(ctx, listOptionsType, listOptions)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	m.Anonymous = types.BoolValue(anonymous)
+	m.Id = types.StringValue(fmt.Sprintf("%s.%s", config, name))
+	m.Type = types.StringValue(sectionType)
+
+	tflog.Debug(ctx, fmt.Sprintf("Setting the %s data source state", d.fullTypeName))
+	diagnostics = res.State.Set(ctx, m)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Schema defines the schema for the data source.
+func (d *sectionDataSource) Schema(
+	ctx context.Context,
+	req datasource.SchemaRequest,
+	res *datasource.SchemaResponse,
+) {
+	res.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			anonymousAttribute: schema.BoolAttribute{
+				Computed:    true,
+				Description: anonymousAttributeDescription,
+			},
+			configAttribute: schema.StringAttribute{
+				Description: configAttributeDescription,
+				Required:    true,
+			},
+			idAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: idAttributeDescription,
+			},
+			listOptionsAttribute: schema.MapAttribute{
+				Computed:    true,
+				Description: dataSourceListOptionsAttributeDescription,
+				ElementType: listOptionsType,
+			},
+			nameAttribute: schema.StringAttribute{
+				Description: dataSourceNameAttributeDescription,
+				Required:    true,
+			},
+			optionsAttribute: schema.MapAttribute{
+				Computed:    true,
+				Description: dataSourceOptionsAttributeDescription,
+				ElementType: types.StringType,
+			},
+			lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceDataSourceAttribute(),
+			typeAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: dataSourceTypeAttributeDescription,
+			},
+		},
+		Description: dataSourceSchemaDescription,
+	}
+}
@@ -0,0 +1,765 @@
+package section
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+)
+
+const (
+	configAttribute            = "config"
+	configAttributeDescription = "The UCI config the section belongs to (e.g. `dhcp`)."
+
+	idAttribute            = "id"
+	idAttributeDescription = "The config and name of the section, separated by a period (e.g. `dhcp.testing`)."
+
+	listOptionsAttribute            = "list_options"
+	listOptionsAttributeDescription = "UCI list options to manage on the section, keyed by option name. Options on the section that are not in this map are left alone."
+
+	nameAttribute            = "name"
+	nameAttributeDescription = "The name of the section. If not set, an anonymous section is created, and the name the device generates is used. The device names anonymous sections again when an earlier section is removed or moved, so the section is then found again by the values of its options."
+
+	optionsAttribute            = "options"
+	optionsAttributeDescription = "UCI options to manage on the section, keyed by option name. Options on the section that are not in this map are left alone."
+
+	schemaDescription = "A section of any UCI config. Only the options given are managed, so this works for configs the provider has no dedicated resource for."
+
+	typeAttribute            = "type"
+	typeAttributeDescription = "The type of the section (e.g. `host`)."
+
+	typeName = "uci_section"
+)
+
+var (
+	_ resource.Resource                   = &sectionResource{}
+	_ resource.ResourceWithConfigure      = &sectionResource{}
+	_ resource.ResourceWithImportState    = &sectionResource{}
+	_ resource.ResourceWithValidateConfig = &sectionResource{}
+
+	listOptionsType = types.ListType{ElemType: types.StringType}
+
+	// Config and type names can contain hyphens (e.g. `bridge-vlan`),
+	// but section and option names cannot.
+	uciNamePattern     = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	uciTypeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+func NewResource() resource.Resource {
+	return &sectionResource{}
+}
+
+type model struct {
+	Config       types.String `tfsdk:"config"`
+	Id           types.String `tfsdk:"id"`
+	ListOptions  types.Map    `tfsdk:"list_options"`
+	Name         types.String `tfsdk:"name"`
+	Options      types.Map    `tfsdk:"options"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Type         types.String `tfsdk:"type"`
+}
+
+type sectionResource struct {
+	devices      *lucirpcglue.Devices
+	fullTypeName string
+}
+
+// Configure adds the provider configured client to the resource.
+func (d *sectionResource) Configure(
+	ctx context.Context,
+	req resource.ConfigureRequest,
+	res *resource.ConfigureResponse,
+) {
+	tflog.Info(ctx, "Configuring uci section resource")
+	if req.ProviderData == nil {
+		tflog.Debug(ctx, "No provider data")
+		return
+	}
+
+	providerData, diagnostics := lucirpcglue.ParseProviderData(lucirpcglue.ConfigureRequest(req))
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	d.devices = providerData.Devices
+	d.fullTypeName = fmt.Sprintf("%s_%s", providerData.TypeName, typeName)
+}
+
+// Create constructs a new resource and sets the initial Terraform state.
+func (d *sectionResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	res *resource.CreateResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Creating %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from plan")
+	var plan model
+	diagnostics := req.Plan.Get(ctx, &plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.Plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	options, diagnostics := generateOptions(ctx, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	config := plan.Config.ValueString()
+	sectionType := plan.Type.ValueString()
+	name := plan.Name.ValueString()
+	if plan.Name.IsNull() || plan.Name.IsUnknown() {
+		tflog.Debug(ctx, "Adding anonymous section")
+		name, diagnostics = lucirpcglue.AddSection(
+			ctx,
+			client,
+			config,
+			sectionType,
+			options,
+			nil,
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	} else {
+		// Creating a section that already exists would merge into it,
+		// and Terraform would then delete it along with this resource.
+		_, exists, diagnostics := lucirpcglue.GetSectionIfExists(ctx, client, config, name)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+
+		if exists {
+			res.Diagnostics.AddAttributeError(
+				path.Root(nameAttribute),
+				fmt.Sprintf("Section %s.%s already exists", config, name),
+				fmt.Sprintf("Either change the %q attribute, or import the existing section with the id %q.", nameAttribute, fmt.Sprintf("%s.%s", config, name)),
+			)
+			return
+		}
+
+		diagnostics = lucirpcglue.CreateSection(
+			ctx,
+			client,
+			config,
+			sectionType,
+			name,
+			options,
+			nil,
+		)
+		res.Diagnostics.Append(diagnostics...)
+		if res.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", config, name))
+	tflog.Debug(ctx, "Reading created section")
+	section, diagnostics := lucirpcglue.GetSection(ctx, client, config, name)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	state, diagnostics := readManagedOptions(ctx, config, section, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating state with values")
+	diagnostics = res.State.Set(ctx, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete removes the actual resource and remove the Terraform state on success.
+func (d *sectionResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	res *resource.DeleteResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Deleting %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Getting the current state")
+	var state model
+	diagnostics := req.State.Get(ctx, &state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.State)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	config := state.Config.ValueString()
+	name := state.Name.ValueString()
+	section, found, diagnostics := findSection(ctx, client, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if found {
+		name, _ = section.GetString(".name")
+	}
+
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", config, name))
+	tflog.Debug(ctx, "Deleting existing section")
+	diagnostics = lucirpcglue.DeleteSection(
+		ctx,
+		client,
+		config,
+		name,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ImportState brings an existing resource into Terraform state.
+// Every option on the section is managed after an import.
+func (d *sectionResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	res *resource.ImportStateResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Importing %s resource", d.fullTypeName))
+
+	// Sections on other devices are imported with the device before the id (e.g. `office/dhcp.testing`).
+	device, id := lucirpcglue.ParseDeviceImportId(req.ID)
+	config, name, ok := strings.Cut(id, ".")
+	if !ok || config == "" || name == "" {
+		res.Diagnostics.AddError(
+			"Invalid import id",
+			fmt.Sprintf("Expected an id of the form `config.name` or `device/config.name` (e.g. `dhcp.testing`), but got %q.", req.ID),
+		)
+		return
+	}
+
+	client, diagnostics := d.devices.Client(ctx, device)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx = tflog.SetField(ctx, "section", id)
+	section, diagnostics := lucirpcglue.GetSection(ctx, client, config, name)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	state, diagnostics := readAllOptions(ctx, config, section)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if device != "" {
+		state.TargetDevice = types.StringValue(device)
+	}
+
+	tflog.Debug(ctx, "Setting the imported state")
+	diagnostics = res.State.Set(ctx, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Metadata sets the resource type name.
+func (d *sectionResource) Metadata(
+	ctx context.Context,
+	req resource.MetadataRequest,
+	res *resource.MetadataResponse,
+) {
+	res.TypeName = fmt.Sprintf("%s_%s", req.ProviderTypeName, typeName)
+}
+
+// Read refreshes the Terraform state with the latest data.
+// Only the options already in the state are compared against the device.
+func (d *sectionResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	res *resource.ReadResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Reading %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Getting the current state")
+	var state model
+	diagnostics := req.State.Get(ctx, &state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.State)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	config := state.Config.ValueString()
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", config, state.Name.ValueString()))
+	section, found, diagnostics := findSection(ctx, client, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if !found {
+		// The section was removed outside of Terraform.
+		// Removing the resource from state lets Terraform plan to create it again.
+		tflog.Info(ctx, "Section no longer exists, removing the resource from state")
+		res.State.RemoveResource(ctx)
+		return
+	}
+
+	state, diagnostics = readManagedOptions(ctx, config, section, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Setting the %s resource state", d.fullTypeName))
+	diagnostics = res.State.Set(ctx, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Schema defines the schema for the resource.
+func (d *sectionResource) Schema(
+	ctx context.Context,
+	req resource.SchemaRequest,
+	res *resource.SchemaResponse,
+) {
+	nameValidator := stringvalidator.RegexMatches(
+		uciNamePattern,
+		"must only contain letters, numbers, and underscores",
+	)
+	typeNameValidator := stringvalidator.RegexMatches(
+		uciTypeNamePattern,
+		"must only contain letters, numbers, hyphens, and underscores",
+	)
+	res.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			configAttribute: schema.StringAttribute{
+				Description: configAttributeDescription,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+				Validators: []validator.String{
+					typeNameValidator,
+				},
+			},
+			idAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: idAttributeDescription,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			listOptionsAttribute: schema.MapAttribute{
+				Description: listOptionsAttributeDescription,
+				ElementType: listOptionsType,
+				Optional:    true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(nameValidator),
+					// UCI does not keep empty lists.
+					mapvalidator.ValueListsAre(listvalidator.SizeAtLeast(1)),
+				},
+			},
+			nameAttribute: schema.StringAttribute{
+				Computed:    true,
+				Description: nameAttributeDescription,
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					nameValidator,
+				},
+			},
+			optionsAttribute: schema.MapAttribute{
+				Description: optionsAttributeDescription,
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(nameValidator),
+				},
+			},
+			lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceResourceAttribute(),
+			typeAttribute: schema.StringAttribute{
+				Description: typeAttributeDescription,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+				Validators: []validator.String{
+					typeNameValidator,
+				},
+			},
+		},
+		Description: schemaDescription,
+	}
+}
+
+// Update modifies part of the resource and sets the Terraform state on success.
+// Options that are no longer managed are removed from the section.
+func (d *sectionResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	res *resource.UpdateResponse,
+) {
+	tflog.Info(ctx, fmt.Sprintf("Updating %s resource", d.fullTypeName))
+
+	tflog.Debug(ctx, "Retrieving values from plan")
+	var plan model
+	diagnostics := req.Plan.Get(ctx, &plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Retrieving values from state")
+	var state model
+	diagnostics = req.State.Get(ctx, &state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, client, _, diagnostics := lucirpcglue.ReadDeviceClient(ctx, d.devices, req.Plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	options, diagnostics := generateOptions(ctx, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	previousOptions, diagnostics := generateOptions(ctx, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	removedOptions := []string{}
+	for option := range previousOptions {
+		if _, ok := options[option]; !ok {
+			removedOptions = append(removedOptions, option)
+		}
+	}
+	sort.Strings(removedOptions)
+
+	config := state.Config.ValueString()
+	name := state.Name.ValueString()
+	current, found, diagnostics := findSection(ctx, client, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if !found {
+		res.Diagnostics.Append(lucirpcglue.NewClientErrorDiagnostic(
+			fmt.Sprintf("problem getting %s.%s section", config, name),
+			lucirpc.NewNotFoundError(config, name),
+		))
+		return
+	}
+
+	name, _ = current.GetString(".name")
+	ctx = tflog.SetField(ctx, "section", fmt.Sprintf("%s.%s", config, name))
+	diagnostics = lucirpcglue.ChangeSection(
+		ctx,
+		client,
+		config,
+		name,
+		options,
+		removedOptions,
+		nil,
+	)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Reading updated section")
+	section, diagnostics := lucirpcglue.GetSection(ctx, client, config, name)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	state, diagnostics = readManagedOptions(ctx, config, section, plan)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, "Updating state with values")
+	diagnostics = res.State.Set(ctx, state)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ValidateConfig checks that no option is given as both a string and a list.
+func (d *sectionResource) ValidateConfig(
+	ctx context.Context,
+	req resource.ValidateConfigRequest,
+	res *resource.ValidateConfigResponse,
+) {
+	var config model
+	diagnostics := req.Config.Get(ctx, &config)
+	res.Diagnostics.Append(diagnostics...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+
+	if config.Options.IsUnknown() || config.ListOptions.IsUnknown() {
+		return
+	}
+
+	for option := range config.Options.Elements() {
+		if _, ok := config.ListOptions.Elements()[option]; ok {
+			res.Diagnostics.AddAttributeError(
+				path.Root(listOptionsAttribute).AtMapKey(option),
+				"Option set more than once",
+				fmt.Sprintf("The %q option is in both %q and %q. A UCI option is either a string or a list, so it can only be in one of them.", option, optionsAttribute, listOptionsAttribute),
+			)
+		}
+	}
+}
+
+// findSection gets the section in the `state`, if it exists.
+// UCI names anonymous sections again whenever an earlier section is removed or moved,
+// so a renamed anonymous section is found again by the values of the options the `state` manages.
+// Any diagnostic information found in the process (including errors) is returned.
+func findSection(
+	ctx context.Context,
+	client lucirpc.Client,
+	state model,
+) (lucirpc.Options, bool, diag.Diagnostics) {
+	config := state.Config.ValueString()
+	options, diagnostics := generateOptions(ctx, state)
+	if diagnostics.HasError() {
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	want, err := json.Marshal(options)
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("Could not look for %s.%s section", config, state.Name.ValueString()),
+			err.Error(),
+		)
+		return lucirpc.Options{}, false, diagnostics
+	}
+
+	matches := func(section lucirpc.Options) bool {
+		m, diagnostics := readManagedOptions(ctx, config, section, state)
+		if diagnostics.HasError() {
+			return false
+		}
+
+		sectionOptions, diagnostics := generateOptions(ctx, m)
+		if diagnostics.HasError() {
+			return false
+		}
+
+		got, err := json.Marshal(sectionOptions)
+		return err == nil && bytes.Equal(got, want)
+	}
+
+	return lucirpcglue.FindSection(
+		ctx,
+		client,
+		config,
+		state.Type.ValueString(),
+		state.Name.ValueString(),
+		matches,
+	)
+}
+
+// generateOptions converts the options in the `m` to the body of a request.
+func generateOptions(
+	ctx context.Context,
+	m model,
+) (lucirpc.Options, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	options := lucirpc.Options{}
+
+	stringOptions := map[string]string{}
+	diagnostics.Append(m.Options.ElementsAs(ctx, &stringOptions, false)...)
+	if diagnostics.HasError() {
+		return options, diagnostics
+	}
+
+	for option, value := range stringOptions {
+		options[option] = lucirpc.String(value)
+	}
+
+	listOptions := map[string][]string{}
+	diagnostics.Append(m.ListOptions.ElementsAs(ctx, &listOptions, false)...)
+	if diagnostics.HasError() {
+		return options, diagnostics
+	}
+
+	for option, value := range listOptions {
+		options[option] = lucirpc.ListString(value)
+	}
+
+	return options, diagnostics
+}
+
+// readAllOptions constructs a [model] that manages every option of the `section`.
+func readAllOptions(
+	ctx context.Context,
+	config string,
+	section lucirpc.Options,
+) (model, diag.Diagnostics) {
+	stringOptions, listOptions := lucirpcglue.SplitOptions(section)
+	m := model{
+		Config:      types.StringValue(config),
+		ListOptions: types.MapNull(listOptionsType),
+		Options:     types.MapNull(types.StringType),
+	}
+	diagnostics := diag.Diagnostics{}
+	if len(stringOptions) > 0 {
+		options, optionsDiagnostics := types.MapValueFrom(ctx, types.StringType, stringOptions)
+		diagnostics.Append(optionsDiagnostics...)
+		m.Options = options
+	}
+
+	if len(listOptions) > 0 {
+		options, optionsDiagnostics := types.MapValueFrom(ctx, listOptionsType, listOptions)
+		diagnostics.Append(optionsDiagnostics...)
+		m.ListOptions = options
+	}
+
+	return readMetadata(config, section, m, diagnostics)
+}
+
+// readManagedOptions constructs a [model] from the `section`,
+// using only the options already in `managed`.
+// Options that have been removed from the device,
+// or have changed between a string and a list,
+// are left out so Terraform plans to set them again.
+func readManagedOptions(
+	ctx context.Context,
+	config string,
+	section lucirpc.Options,
+	managed model,
+) (model, diag.Diagnostics) {
+	diagnostics := diag.Diagnostics{}
+	m := model{
+		Config:       types.StringValue(config),
+		ListOptions:  types.MapNull(listOptionsType),
+		Options:      types.MapNull(types.StringType),
+		TargetDevice: managed.TargetDevice,
+	}
+
+	if !managed.Options.IsNull() {
+		stringOptions := map[string]string{}
+		for option := range managed.Options.Elements() {
+			if value, err := section.GetString(option); err == nil {
+				stringOptions[option] = value
+			}
+		}
+
+		options, optionsDiagnostics := types.MapValueFrom(ctx, types.StringType, stringOptions)
+		diagnostics.Append(optionsDiagnostics...)
+		m.Options = options
+	}
+
+	if !managed.ListOptions.IsNull() {
+		listOptions := map[string][]string{}
+		for option := range managed.ListOptions.Elements() {
+			if value, err := section.GetListString(option); err == nil {
+				listOptions[option] = value
+			}
+		}
+
+		options, optionsDiagnostics := types.MapValueFrom(ctx, listOptionsType, listOptions)
+		diagnostics.Append(optionsDiagnostics...)
+		m.ListOptions = options
+	}
+
+	return readMetadata(config, section, m, diagnostics)
+}
+
+// readMetadata fills in the attributes of `m` that come from the section's metadata.
+// The type is read from the device so a changed type is planned as a replacement.
+func readMetadata(
+	config string,
+	section lucirpc.Options,
+	m model,
+	diagnostics diag.Diagnostics,
+) (model, diag.Diagnostics) {
+	name, err := section.GetString(".name")
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".name"),
+			err.Error(),
+		)
+		return m, diagnostics
+	}
+
+	sectionType, err := section.GetString(".type")
+	if err != nil {
+		diagnostics.AddError(
+			fmt.Sprintf("unable to parse metadata: %q", ".type"),
+			err.Error(),
+		)
+		return m, diagnostics
+	}
+
+	m.Id = types.StringValue(fmt.Sprintf("%s.%s", config, name))
+	m.Name = types.StringValue(name)
+	m.Type = types.StringValue(sectionType)
+	return m, diagnostics
+}
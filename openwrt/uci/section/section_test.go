@@ -0,0 +1,350 @@
+package section_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("uhttpd", "uhttpd", "main", map[string]any{
+		"home":        "/www",
+		"listen_http": []string{"0.0.0.0:80", "[::]:80"},
+	})
+	providerBlock := server.ProviderBlock()
+
+	readSection := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	name = "main"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "anonymous", "false"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "id", "uhttpd.main"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "list_options.listen_http.#", "2"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "list_options.listen_http.1", "[::]:80"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "options.%", "1"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "options.home", "/www"),
+			resource.TestCheckResourceAttr("data.openwrt_uci_section.testing", "type", "uhttpd"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readSection,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("uhttpd", "uhttpd", "main", map[string]any{
+		"home": "/www",
+	})
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	list_options = {
+		listen_http = ["0.0.0.0:8080", "[::]:8080"]
+	}
+	name = "testing"
+	options = {
+		home = "/www"
+		rfc1918_filter = "1"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "id", "uhttpd.testing"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "list_options.listen_http.#", "2"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "options.home", "/www"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "options.rfc1918_filter", "1"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_uci_section.testing",
+	}
+	removeOptionFromResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	name = "testing"
+	options = {
+		home = "/www"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckNoResourceAttr("openwrt_uci_section.testing", "list_options"),
+			resource.TestCheckNoResourceAttr("openwrt_uci_section.testing", "options.rfc1918_filter"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("uhttpd", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("uhttpd"),
+					"home":       lucirpc.String("/www"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		removeOptionFromResource,
+	)
+}
+
+func TestResourcePendingChanges(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("uhttpd", "uhttpd", "main", map[string]any{
+		"home": "/www",
+	})
+	server.StageOptions("uhttpd", "main", map[string]any{
+		"home": "/srv/www",
+	})
+	providerBlock := server.ProviderBlock(`pending_changes = "fail"`)
+
+	createResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	name = "testing"
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		ExpectError: regexp.MustCompile(`uhttpd.main.home='/srv/www'`),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createResource,
+	)
+}
+
+func TestResourceTargetDevice(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	office := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock(fmt.Sprintf("devices = {%s}", office.DeviceAttribute("office")))
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	name = "testing"
+	options = {
+		home = "/www"
+	}
+	target_device = "office"
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "target_device", "office"),
+			func(*terraform.State) error {
+				_, ok := office.CommittedSection("uhttpd", "testing")
+				assert.Check(t, ok)
+				_, ok = server.CommittedSection("uhttpd", "testing")
+				assert.Check(t, !ok)
+				return nil
+			},
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateId:     "office/uhttpd.testing",
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_uci_section.testing",
+	}
+	unknownDevice := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	name = "testing"
+	target_device = "warehouse"
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		ExpectError: regexp.MustCompile(`Unknown device`),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		unknownDevice,
+	)
+}
+
+func TestResourceAnonymous(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "testing" {
+	config = "uhttpd"
+	options = {
+		home = "/www"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "id", "uhttpd.cfg0122fe"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.testing", "name", "cfg0122fe"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("uhttpd", "cfg0122fe")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got[".anonymous"], lucirpc.Boolean(true))
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+	)
+}
+
+func TestResourceAnonymousRenamed(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResources := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "first" {
+	config = "uhttpd"
+	options = {
+		home = "/www"
+	}
+	type = "uhttpd"
+}
+
+resource "openwrt_uci_section" "second" {
+	depends_on = [openwrt_uci_section.first]
+
+	config = "uhttpd"
+	options = {
+		home = "/srv"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_uci_section.first", "name", "cfg0122fe"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.second", "name", "cfg0222fe"),
+		),
+	}
+	deleteFirstResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "second" {
+	config = "uhttpd"
+	options = {
+		home = "/srv"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: func(*terraform.State) error {
+			got, ok := server.CommittedSection("uhttpd", "cfg0122fe")
+			assert.Check(t, ok)
+			assert.DeepEqual(t, got["home"], lucirpc.String("/srv"))
+			_, ok = server.CommittedSection("uhttpd", "cfg0222fe")
+			assert.Check(t, !ok)
+			return nil
+		},
+	}
+	readRenamedResource := resource.TestStep{
+		RefreshState: true,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_uci_section.second", "id", "uhttpd.cfg0122fe"),
+			resource.TestCheckResourceAttr("openwrt_uci_section.second", "name", "cfg0122fe"),
+		),
+	}
+	updateRenamedResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_uci_section" "second" {
+	config = "uhttpd"
+	options = {
+		home = "/var/www"
+	}
+	type = "uhttpd"
+}
+`,
+			providerBlock,
+		),
+		Check: func(*terraform.State) error {
+			got, ok := server.CommittedSection("uhttpd", "cfg0122fe")
+			assert.Check(t, ok)
+			assert.DeepEqual(t, got["home"], lucirpc.String("/var/www"))
+			return nil
+		},
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResources,
+		deleteFirstResource,
+		readRenamedResource,
+		updateRenamedResource,
+	)
+}
@@ -138,6 +138,12 @@ const (
 )
 
 var (
+	// Reference is how other sections name a wireless device (e.g. a wireless interface's `device`).
+	Reference = lucirpcglue.SectionReference{
+		Config: uciConfig,
+		Type:   uciType,
+	}
+
 	bandSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       bandAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetBand, bandAttribute, bandUCIOption),
@@ -290,14 +296,15 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		bandAttribute:           bandSchemaAttribute,
-		cellDensityAttribute:    cellDensitySchemaAttribute,
-		channelAttribute:        channelSchemaAttribute,
-		countryCodeAttribute:    countryCodeSchemaAttribute,
-		lucirpcglue.IdAttribute: lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		htModeAttribute:         htModeSchemaAttribute,
-		pathAttribute:           pathSchemaAttribute,
-		typeAttribute:           typeSchemaAttribute,
+		bandAttribute:                     bandSchemaAttribute,
+		cellDensityAttribute:              cellDensitySchemaAttribute,
+		channelAttribute:                  channelSchemaAttribute,
+		countryCodeAttribute:              countryCodeSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		htModeAttribute:                   htModeSchemaAttribute,
+		pathAttribute:                     pathSchemaAttribute,
+		typeAttribute:                     typeSchemaAttribute,
 	}
 
 	typeSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -323,6 +330,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -334,30 +350,33 @@ func NewResource() resource.Resource {
 }
 
 type model struct {
-	Band        types.String `tfsdk:"band"`
-	CellDensity types.Int64  `tfsdk:"cell_density"`
-	Channel     types.String `tfsdk:"channel"`
-	CountryCode types.String `tfsdk:"country"`
-	HTMode      types.String `tfsdk:"htmode"`
-	Id          types.String `tfsdk:"id"`
-	Path        types.String `tfsdk:"path"`
-	Type        types.String `tfsdk:"type"`
+	Band         types.String `tfsdk:"band"`
+	CellDensity  types.Int64  `tfsdk:"cell_density"`
+	Channel      types.String `tfsdk:"channel"`
+	CountryCode  types.String `tfsdk:"country"`
+	HTMode       types.String `tfsdk:"htmode"`
+	TargetDevice types.String `tfsdk:"target_device"`
+	Id           types.String `tfsdk:"id"`
+	Path         types.String `tfsdk:"path"`
+	Type         types.String `tfsdk:"type"`
 }
 
-func modelGetBand(m model) types.String        { return m.Band }
-func modelGetCellDensity(m model) types.Int64  { return m.CellDensity }
-func modelGetChannel(m model) types.String     { return m.Channel }
-func modelGetCountryCode(m model) types.String { return m.CountryCode }
-func modelGetHTMode(m model) types.String      { return m.HTMode }
-func modelGetId(m model) types.String          { return m.Id }
-func modelGetPath(m model) types.String        { return m.Path }
-func modelGetType(m model) types.String        { return m.Type }
+func modelGetBand(m model) types.String         { return m.Band }
+func modelGetCellDensity(m model) types.Int64   { return m.CellDensity }
+func modelGetChannel(m model) types.String      { return m.Channel }
+func modelGetCountryCode(m model) types.String  { return m.CountryCode }
+func modelGetHTMode(m model) types.String       { return m.HTMode }
+func modelGetTargetDevice(m model) types.String { return m.TargetDevice }
+func modelGetId(m model) types.String           { return m.Id }
+func modelGetPath(m model) types.String         { return m.Path }
+func modelGetType(m model) types.String         { return m.Type }
 
-func modelSetBand(m *model, value types.String)        { m.Band = value }
-func modelSetCellDensity(m *model, value types.Int64)  { m.CellDensity = value }
-func modelSetChannel(m *model, value types.String)     { m.Channel = value }
-func modelSetCountryCode(m *model, value types.String) { m.CountryCode = value }
-func modelSetHTMode(m *model, value types.String)      { m.HTMode = value }
-func modelSetId(m *model, value types.String)          { m.Id = value }
-func modelSetPath(m *model, value types.String)        { m.Path = value }
-func modelSetType(m *model, value types.String)        { m.Type = value }
+func modelSetBand(m *model, value types.String)         { m.Band = value }
+func modelSetCellDensity(m *model, value types.Int64)   { m.CellDensity = value }
+func modelSetChannel(m *model, value types.String)      { m.Channel = value }
+func modelSetCountryCode(m *model, value types.String)  { m.CountryCode = value }
+func modelSetHTMode(m *model, value types.String)       { m.HTMode = value }
+func modelSetTargetDevice(m *model, value types.String) { m.TargetDevice = value }
+func modelSetId(m *model, value types.String)           { m.Id = value }
+func modelSetPath(m *model, value types.String)         { m.Path = value }
+func modelSetType(m *model, value types.String)         { m.Type = value }
@@ -0,0 +1,112 @@
+package wifidevice_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("wireless", "wifi-device", "testing", map[string]any{
+		"channel": "auto",
+		"type":    "mac80211",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_wireless_wifi_device" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_device.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_device.testing", "channel", "auto"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_device.testing", "type", "mac80211"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_wireless_wifi_device" "testing" {
+	channel = "auto"
+	id = "testing"
+	type = "mac80211"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "channel", "auto"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "type", "mac80211"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_wireless_wifi_device.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_wireless_wifi_device" "testing" {
+	band = "6g"
+	channel = "auto"
+	id = "testing"
+	type = "mac80211"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "band", "6g"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "channel", "auto"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_device.testing", "type", "mac80211"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("wireless", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous": lucirpc.Boolean(false),
+					".name":      lucirpc.String("testing"),
+					".type":      lucirpc.String("wifi-device"),
+					"band":       lucirpc.String("6g"),
+					"channel":    lucirpc.String("auto"),
+					"type":       lucirpc.String("mac80211"),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}
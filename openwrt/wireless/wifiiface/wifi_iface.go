@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
 	"github.com/joneshf/terraform-provider-openwrt/openwrt/internal/lucirpcglue"
+	"github.com/joneshf/terraform-provider-openwrt/openwrt/wireless/wifidevice"
 )
 
 const (
@@ -108,6 +109,7 @@ var (
 	deviceSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
 		Description:       deviceAttributeDescription,
 		ReadResponse:      lucirpcglue.ReadResponseOptionString(modelSetDevice, deviceAttribute, deviceUCIOption),
+		References:        []lucirpcglue.SectionReference{wifidevice.Reference},
 		ResourceExistence: lucirpcglue.Required,
 		UpsertRequest:     lucirpcglue.UpsertRequestOptionString(modelGetDevice, deviceAttribute, deviceUCIOption),
 	}
@@ -222,17 +224,18 @@ var (
 	}
 
 	schemaAttributes = map[string]lucirpcglue.SchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
-		deviceAttribute:           deviceSchemaAttribute,
-		encryptionMethodAttribute: encryptionMethodSchemaAttribute,
-		isolateClientsAttribute:   isolateClientsSchemaAttribute,
-		keyAttribute:              keySchemaAttribute,
-		krackWorkaroundAttribute:  krackWorkaroundSchemaAttribute,
-		lucirpcglue.IdAttribute:   lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
-		modeAttribute:             modeSchemaAttribute,
-		networkAttribute:          networkSchemaAttribute,
-		ssidAttribute:             ssidSchemaAttribute,
-		macFilterAttribute:        macFilterSchemaAttribute,
-		macListAttribute:          macListSchemaAttribute,
+		deviceAttribute:                   deviceSchemaAttribute,
+		encryptionMethodAttribute:         encryptionMethodSchemaAttribute,
+		isolateClientsAttribute:           isolateClientsSchemaAttribute,
+		keyAttribute:                      keySchemaAttribute,
+		krackWorkaroundAttribute:          krackWorkaroundSchemaAttribute,
+		lucirpcglue.TargetDeviceAttribute: lucirpcglue.TargetDeviceSchemaAttribute(modelGetTargetDevice, modelSetTargetDevice),
+		lucirpcglue.IdAttribute:           lucirpcglue.IdSchemaAttribute(modelGetId, modelSetId),
+		modeAttribute:                     modeSchemaAttribute,
+		networkAttribute:                  networkSchemaAttribute,
+		ssidAttribute:                     ssidSchemaAttribute,
+		macFilterAttribute:                macFilterSchemaAttribute,
+		macListAttribute:                  macListSchemaAttribute,
 	}
 
 	ssidSchemaAttribute = lucirpcglue.StringSchemaAttribute[model, lucirpc.Options, lucirpc.Options]{
@@ -277,6 +280,15 @@ func NewDataSource() datasource.DataSource {
 	)
 }
 
+func NewListDataSource() datasource.DataSource {
+	return lucirpcglue.NewListDataSource(
+		schemaAttributes,
+		schemaDescription,
+		uciConfig,
+		uciType,
+	)
+}
+
 func NewResource() resource.Resource {
 	return lucirpcglue.NewResource(
 		modelGetId,
@@ -290,6 +302,7 @@ func NewResource() resource.Resource {
 type model struct {
 	Device           types.String `tfsdk:"device"`
 	EncryptionMethod types.String `tfsdk:"encryption"`
+	TargetDevice     types.String `tfsdk:"target_device"`
 	Id               types.String `tfsdk:"id"`
 	IsolateClients   types.Bool   `tfsdk:"isolate"`
 	Key              types.String `tfsdk:"key"`
@@ -303,6 +316,7 @@ type model struct {
 
 func modelGetDevice(m model) types.String           { return m.Device }
 func modelGetEncryptionMethod(m model) types.String { return m.EncryptionMethod }
+func modelGetTargetDevice(m model) types.String     { return m.TargetDevice }
 func modelGetId(m model) types.String               { return m.Id }
 func modelGetIsolateClients(m model) types.Bool     { return m.IsolateClients }
 func modelGetKey(m model) types.String              { return m.Key }
@@ -315,6 +329,7 @@ func modelGetMacList(m model) types.List            { return m.MacList }
 
 func modelSetDevice(m *model, value types.String)           { m.Device = value }
 func modelSetEncryptionMethod(m *model, value types.String) { m.EncryptionMethod = value }
+func modelSetTargetDevice(m *model, value types.String)     { m.TargetDevice = value }
 func modelSetId(m *model, value types.String)               { m.Id = value }
 func modelSetIsolateClients(m *model, value types.Bool)     { m.IsolateClients = value }
 func modelSetKey(m *model, value types.String)              { m.Key = value }
@@ -0,0 +1,142 @@
+package wifiiface_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/joneshf/terraform-provider-openwrt/internal/lucirpctest"
+	"github.com/joneshf/terraform-provider-openwrt/lucirpc"
+	"gotest.tools/v3/assert"
+)
+
+func TestDataSource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	server.SetSection("wireless", "wifi-iface", "testing", map[string]any{
+		"device":  "device-testing",
+		"mode":    "ap",
+		"network": "network-testing",
+		"ssid":    "ssid-testing",
+	})
+	providerBlock := server.ProviderBlock()
+
+	readDataSource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+data "openwrt_wireless_wifi_iface" "testing" {
+	id = "testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_iface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_iface.testing", "device", "device-testing"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_iface.testing", "mode", "ap"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_iface.testing", "network", "network-testing"),
+			resource.TestCheckResourceAttr("data.openwrt_wireless_wifi_iface.testing", "ssid", "ssid-testing"),
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		readDataSource,
+	)
+}
+
+func TestResource(t *testing.T) {
+	server := lucirpctest.NewServer(t)
+	providerBlock := server.ProviderBlock()
+
+	createAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_wireless_wifi_iface" "testing" {
+	device = "device-testing"
+	id = "testing"
+	mode = "ap"
+	network = "network-testing"
+	ssid = "ssid-testing"
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "device", "device-testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "mode", "ap"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "network", "network-testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "ssid", "ssid-testing"),
+		),
+	}
+	importValidation := resource.TestStep{
+		ImportState:       true,
+		ImportStateVerify: true,
+		ResourceName:      "openwrt_wireless_wifi_iface.testing",
+	}
+	updateAndReadResource := resource.TestStep{
+		Config: fmt.Sprintf(`
+%s
+
+resource "openwrt_wireless_wifi_iface" "testing" {
+	device = "device-testing"
+	encryption = "sae"
+	id = "testing"
+	key = "password"
+	mode = "ap"
+	network = "network-testing"
+	ssid = "ssid-testing"
+	wpa_disable_eapol_key_retries = true
+	macfilter = "allow"
+	maclist = [
+		"00:01:02:03:04:05",
+		"05:04:03:02:01:00",
+	]
+}
+`,
+			providerBlock,
+		),
+		Check: resource.ComposeAggregateTestCheckFunc(
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "id", "testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "device", "device-testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "encryption", "sae"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "key", "password"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "mode", "ap"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "network", "network-testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "ssid", "ssid-testing"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "wpa_disable_eapol_key_retries", "true"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "macfilter", "allow"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "maclist.0", "00:01:02:03:04:05"),
+			resource.TestCheckResourceAttr("openwrt_wireless_wifi_iface.testing", "maclist.1", "05:04:03:02:01:00"),
+			func(*terraform.State) error {
+				got, ok := server.CommittedSection("wireless", "testing")
+				assert.Check(t, ok)
+				assert.DeepEqual(t, got, lucirpc.Options{
+					".anonymous":                    lucirpc.Boolean(false),
+					".name":                         lucirpc.String("testing"),
+					".type":                         lucirpc.String("wifi-iface"),
+					"device":                        lucirpc.String("device-testing"),
+					"encryption":                    lucirpc.String("sae"),
+					"key":                           lucirpc.String("password"),
+					"macfilter":                     lucirpc.String("allow"),
+					"maclist":                       lucirpc.ListString([]string{"00:01:02:03:04:05", "05:04:03:02:01:00"}),
+					"mode":                          lucirpc.String("ap"),
+					"network":                       lucirpc.String("network-testing"),
+					"ssid":                          lucirpc.String("ssid-testing"),
+					"wpa_disable_eapol_key_retries": lucirpc.Boolean(true),
+				})
+				return nil
+			},
+		),
+	}
+
+	lucirpctest.TerraformSteps(
+		t,
+		createAndReadResource,
+		importValidation,
+		updateAndReadResource,
+	)
+}